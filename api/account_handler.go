@@ -1,35 +1,57 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"simplebank/cursor"
 	db "simplebank/db/sqlc"
+	"time"
 )
 
+//errAccountNotOwned is returned when the account doesn't belong to the authenticated user
+var errAccountNotOwned = errors.New("account doesn't belong to the authenticated user")
+
 //accountHandler handles all HTTP requests in Accounts domain.
 type (
 	accountHandler struct {
-		store db.Store
+		store   db.Store
+		cursors cursor.Codec
+	}
+	//accountResponse is an account with its available balance, what is left to spend once active holds are deducted,
+	//overdraft limit included. Like every amount of the API, balances are in minor units of the account currency.
+	accountResponse struct {
+		db.Account
+		AvailableBalance int64 `json:"available_balance"`
 	}
 	createAccountRequest struct {
-		Owner    string `json:"owner" binding:"required"`
-		Currency string `json:"currency" binding:"required,oneof=USD EUR"`
+		Currency string `json:"currency" binding:"required,currency"`
 	}
 	getAccountRequest struct {
 		ID int64 `uri:"id" binding:"required,min=1"`
 	}
+	//listAccountsRequest pages with cursor, page_id is the deprecated OFFSET paging
 	listAccountsRequest struct {
-		PageSize int32 `form:"page_size" binding:"required,min=5,max=20"`
-		PageID   int32 `form:"page_id" binding:"required,min=1"`
+		PageSize int32  `form:"page_size" binding:"required,min=5,max=20"`
+		PageID   int32  `form:"page_id" binding:"omitempty,min=1,excluded_with=Cursor"`
+		Cursor   string `form:"cursor"`
+	}
+	listEntriesRequest struct {
+		PageSize int32     `form:"page_size" binding:"required,min=5,max=20"`
+		PageID   int32     `form:"page_id" binding:"omitempty,min=1,excluded_with=Cursor"`
+		Cursor   string    `form:"cursor"`
+		From     time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+		To       time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00" binding:"omitempty,gtfield=From"`
 	}
 )
 
 //newAccountHandler builds accountHandler struct
-func newAccountHandler(store db.Store) accountHandler {
+func newAccountHandler(store db.Store, cursors cursor.Codec) accountHandler {
 	return accountHandler{
-		store: store,
+		store:   store,
+		cursors: cursors,
 	}
 }
 
@@ -41,17 +63,22 @@ func (h accountHandler) post(ctx *gin.Context) {
 		return
 	}
 
-	account, err := h.store.CreateAccount(ctx, db.CreateAccountParams{
-		Owner:    req.Owner,
+	account, err := h.store.CreateAccountTx(ctx, db.CreateAccountParams{
+		Owner:    authPayload(ctx).Username,
 		Balance:  0,
 		Currency: req.Currency,
 	})
 	if err != nil {
+		if pqErrorCode(err) == foreignKeyViolation {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(errors.New("owner not found")))
+			return
+		}
+
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, account)
+	ctx.JSON(http.StatusCreated, newAccountResponse(account))
 }
 
 func (h accountHandler) get(ctx *gin.Context) {
@@ -62,18 +89,183 @@ func (h accountHandler) get(ctx *gin.Context) {
 		return
 	}
 
-	account, err := h.store.GetAccount(ctx, req.ID)
+	account, ok := ownedAccount(ctx, h.store, req.ID)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountResponse(account))
+}
+
+func (h accountHandler) listEntries(ctx *gin.Context) {
+	var uri getAccountRequest
+	var req listEntriesRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	current, ok := decodeCursor(ctx, h.cursors, req.Cursor)
+	if !ok {
+		return
+	}
+
+	if _, ok := ownedAccount(ctx, h.store, uri.ID); !ok {
+		return
+	}
+
+	if req.PageID != 0 {
+		entries, err := h.store.ListEntriesByAccount(ctx, db.ListEntriesByAccountParams{
+			AccountID: uri.ID,
+			FromTime:  nullTime(req.From),
+			ToTime:    nullTime(req.To),
+			Limit:     req.PageSize,
+			Offset:    (req.PageID - 1) * req.PageSize,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+			return
+		}
+
+		deprecateOffsetPaging(ctx)
+		ctx.JSON(http.StatusOK, entries)
+		return
+	}
+
+	entries, err := h.listEntriesPage(ctx, uri.ID, req, current)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	page, next, prev := cursor.Paginate(h.cursors, entries, req.PageSize, current, entryCursor)
+	ctx.JSON(http.StatusOK, pageResponse{Data: page, NextCursor: next, PrevCursor: prev})
+}
+
+//listEntriesPage reads one entry more than the page size from the cursor position, in the cursor direction
+func (h accountHandler) listEntriesPage(ctx *gin.Context, accountID int64, req listEntriesRequest, current *cursor.Cursor) ([]db.ListEntriesByAccountRow, error) {
+	var entries []db.ListEntriesByAccountRow
+
+	if current != nil && current.Backward {
+		rows, err := h.store.ListEntriesByAccountBefore(ctx, db.ListEntriesByAccountBeforeParams{
+			AccountID:       accountID,
+			FromTime:        nullTime(req.From),
+			ToTime:          nullTime(req.To),
+			CursorCreatedAt: current.CreatedAt,
+			CursorID:        current.ID,
+			Limit:           req.PageSize + 1,
+		})
+		for _, row := range rows {
+			entries = append(entries, db.ListEntriesByAccountRow(row))
+		}
+		return entries, err
+	}
+
+	cursorCreatedAt, cursorID := afterCursor(current)
+	rows, err := h.store.ListEntriesByAccountAfter(ctx, db.ListEntriesByAccountAfterParams{
+		AccountID:       accountID,
+		FromTime:        nullTime(req.From),
+		ToTime:          nullTime(req.To),
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           req.PageSize + 1,
+	})
+	for _, row := range rows {
+		entries = append(entries, db.ListEntriesByAccountRow(row))
+	}
+	return entries, err
+}
+
+//freeze blocks every movement on the account. Freezing and unfreezing are admin routes, so ops decide both
+//and a holder can't lift a freeze placed by ops.
+func (h accountHandler) freeze(ctx *gin.Context) {
+	h.adminChangeStatus(ctx, h.store.FreezeAccount)
+}
+
+//unfreeze moves a frozen account back to active
+func (h accountHandler) unfreeze(ctx *gin.Context) {
+	h.adminChangeStatus(ctx, h.store.UnfreezeAccount)
+}
+
+//close permanently closes the account, its balance must be zero
+func (h accountHandler) close(ctx *gin.Context) {
+	h.changeStatus(ctx, h.store.CloseAccount)
+}
+
+//changeStatus applies a status transition of the store to the authenticated user's account
+func (h accountHandler) changeStatus(ctx *gin.Context, change func(ctx context.Context, id int64) (db.Account, error)) {
+	var req getAccountRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := ownedAccount(ctx, h.store, req.ID); !ok {
+		return
+	}
+
+	h.applyStatus(ctx, req.ID, change)
+}
+
+//adminChangeStatus applies a status transition of the store to any account, the caller is an admin
+func (h accountHandler) adminChangeStatus(ctx *gin.Context, change func(ctx context.Context, id int64) (db.Account, error)) {
+	var req getAccountRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	h.applyStatus(ctx, req.ID, change)
+}
+
+//applyStatus applies a status transition of the store to the account and writes the response
+func (h accountHandler) applyStatus(ctx *gin.Context, id int64, change func(ctx context.Context, id int64) (db.Account, error)) {
+	account, err := change(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("account not found")))
+		case errors.Is(err, db.ErrInvalidAccountTransition):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		case errors.Is(err, db.ErrAccountBalanceNotZero):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountResponse(account))
+}
+
+//ownedAccount loads the account and checks it belongs to the authenticated user.
+//It writes the error response and returns false when the account can't be used.
+func ownedAccount(ctx *gin.Context, store db.Store, id int64) (db.Account, bool) {
+	account, err := store.GetAccount(ctx, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("account not found")))
-			return
+			return account, false
 		}
 
 		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
-		return
+		return account, false
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	if account.Owner != authPayload(ctx).Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(errAccountNotOwned))
+		return account, false
+	}
+
+	return account, true
 }
 
 func (h accountHandler) list(ctx *gin.Context) {
@@ -84,14 +276,78 @@ func (h accountHandler) list(ctx *gin.Context) {
 		return
 	}
 
-	account, err := h.store.ListAccounts(ctx, db.ListAccountsParams{
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
-	})
+	current, ok := decodeCursor(ctx, h.cursors, req.Cursor)
+	if !ok {
+		return
+	}
+
+	owner := authPayload(ctx).Username
+
+	if req.PageID != 0 {
+		accounts, err := h.store.ListAccountsByOwner(ctx, db.ListAccountsByOwnerParams{
+			Owner:  owner,
+			Limit:  req.PageSize,
+			Offset: (req.PageID - 1) * req.PageSize,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+			return
+		}
+
+		deprecateOffsetPaging(ctx)
+		ctx.JSON(http.StatusOK, newAccountsResponse(accounts))
+		return
+	}
+
+	var accounts []db.Account
+	var err error
+	if current != nil && current.Backward {
+		accounts, err = h.store.ListAccountsByOwnerBefore(ctx, db.ListAccountsByOwnerBeforeParams{
+			Owner:           owner,
+			CursorCreatedAt: current.CreatedAt,
+			CursorID:        current.ID,
+			Limit:           req.PageSize + 1,
+		})
+	} else {
+		cursorCreatedAt, cursorID := afterCursor(current)
+		accounts, err = h.store.ListAccountsByOwnerAfter(ctx, db.ListAccountsByOwnerAfterParams{
+			Owner:           owner,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorID,
+			Limit:           req.PageSize + 1,
+		})
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	page, next, prev := cursor.Paginate(h.cursors, accounts, req.PageSize, current, accountCursor)
+	ctx.JSON(http.StatusOK, pageResponse{Data: newAccountsResponse(page), NextCursor: next, PrevCursor: prev})
+}
+
+//newAccountResponse computes the available balance of the account
+func newAccountResponse(account db.Account) accountResponse {
+	return accountResponse{
+		Account:          account,
+		AvailableBalance: account.Balance - account.HeldBalance + account.OverdraftLimit,
+	}
+}
+
+func newAccountsResponse(accounts []db.Account) []accountResponse {
+	rsp := make([]accountResponse, 0, len(accounts))
+	for _, account := range accounts {
+		rsp = append(rsp, newAccountResponse(account))
+	}
+	return rsp
+}
+
+//accountCursor returns the keyset position of an account
+func accountCursor(account db.Account) cursor.Cursor {
+	return cursor.Cursor{CreatedAt: account.CreatedAt, ID: account.ID}
+}
+
+//entryCursor returns the keyset position of an entry
+func entryCursor(entry db.ListEntriesByAccountRow) cursor.Cursor {
+	return cursor.Cursor{CreatedAt: entry.CreatedAt, ID: entry.ID}
 }
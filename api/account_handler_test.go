@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -12,8 +13,11 @@ import (
 	"github.com/stretchr/testify/require"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"simplebank/cursor"
 	db "simplebank/db/sqlc"
 	mockdb "simplebank/db/sqlc/mock"
+	"simplebank/token"
 	"simplebank/util"
 	"testing"
 	"time"
@@ -31,20 +35,25 @@ func Test_accountHandler_get(t *testing.T) {
 	tests := []struct {
 		name          string
 		accountID     int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(ctrl *gomock.Controller) stub
 		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name:      "When it successfully finds the account",
 			accountID: 10,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "Perotto", time.Minute)
+			},
 			buildStubs: func(ctrl *gomock.Controller) stub {
 				store := mockdb.NewMockStore(ctrl)
 				account := db.Account{
-					ID:        10,
-					Owner:     "Perotto",
-					Balance:   100,
-					Currency:  "USD",
-					CreatedAt: defaultCreatedAt,
+					ID:          10,
+					Owner:       "Perotto",
+					Balance:     100,
+					HeldBalance: 30,
+					Currency:    "USD",
+					CreatedAt:   defaultCreatedAt,
 				}
 
 				store.EXPECT().GetAccount(gomock.Any(), int64(10)).
@@ -54,25 +63,67 @@ func Test_accountHandler_get(t *testing.T) {
 				return stub{store: store}
 			},
 			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				var responseBody db.Account
+				var responseBody accountResponse
 				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
 
-				wantResponseBody := db.Account{
-					ID:        10,
-					Owner:     "Perotto",
-					Balance:   100,
-					Currency:  "USD",
-					CreatedAt: defaultCreatedAt,
+				wantResponseBody := accountResponse{
+					Account: db.Account{
+						ID:          10,
+						Owner:       "Perotto",
+						Balance:     100,
+						HeldBalance: 30,
+						Currency:    "USD",
+						CreatedAt:   defaultCreatedAt,
+					},
+					AvailableBalance: 70,
 				}
 
 				assert.Equal(t, http.StatusOK, recorder.Code)
 				assert.Equal(t, wantResponseBody, responseBody)
 			},
 		},
+		{
+			name:      "When account belongs to another user",
+			accountID: 10,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "Emmanuel", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), int64(10)).
+					Times(1).
+					Return(db.Account{ID: 10, Owner: "Perotto", Currency: "USD"}, nil)
 
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody gin.H
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+				assert.Equal(t, "account doesn't belong to the authenticated user", responseBody["error"])
+			},
+		},
+		{
+			name:      "When authorization is not provided",
+			accountID: 10,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 		{
 			name:      "When sending id less than 1",
 			accountID: 0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "Perotto", time.Minute)
+			},
 			buildStubs: func(ctrl *gomock.Controller) stub {
 				store := mockdb.NewMockStore(ctrl)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
@@ -90,6 +141,9 @@ func Test_accountHandler_get(t *testing.T) {
 		{
 			name:      "When account not found",
 			accountID: util.RandomInt(1, 5),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "Perotto", time.Minute)
+			},
 			buildStubs: func(ctrl *gomock.Controller) stub {
 				store := mockdb.NewMockStore(ctrl)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).
@@ -109,6 +163,9 @@ func Test_accountHandler_get(t *testing.T) {
 		{
 			name:      "When there is a generic error fetching account",
 			accountID: 1,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "Perotto", time.Minute)
+			},
 			buildStubs: func(ctrl *gomock.Controller) stub {
 				store := mockdb.NewMockStore(ctrl)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).
@@ -136,11 +193,218 @@ func Test_accountHandler_get(t *testing.T) {
 
 			//Start test server and send request
 			url := fmt.Sprintf("/accounts/%d", tt.accountID)
-			server := NewServer(stubs.store)
+			server := newTestServer(t, stubs.store)
 			recorder := httptest.NewRecorder()
 
 			request, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
+			tt.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
+
+func Test_accountHandler_changeStatus(t *testing.T) {
+	account := db.Account{
+		ID:        10,
+		Owner:     "Perotto",
+		Balance:   100,
+		Currency:  "USD",
+		CreatedAt: defaultCreatedAt,
+		Status:    db.AccountStatusActive,
+	}
+	admin := db.User{Username: "admin", Role: util.AdminRole}
+	holder := db.User{Username: account.Owner, Role: util.DepositorRole}
+
+	tests := []struct {
+		name          string
+		action        string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "When an admin freezes the account",
+			action: "freeze",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				frozen := account
+				frozen.Status = db.AccountStatusFrozen
+
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().FreezeAccount(gomock.Any(), account.ID).Times(1).Return(frozen, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody accountResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, db.AccountStatusFrozen, responseBody.Status)
+			},
+		},
+		{
+			name:   "When the account holder freezes the account",
+			action: "freeze",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), account.Owner).Times(1).Return(holder, nil)
+				store.EXPECT().FreezeAccount(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "When an admin freezes a missing account",
+			action: "freeze",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().FreezeAccount(gomock.Any(), account.ID).Times(1).Return(db.Account{}, sql.ErrNoRows)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "When an admin unfreezes the account",
+			action: "unfreeze",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().UnfreezeAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "When the account holder unfreezes the account",
+			action: "unfreeze",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), account.Owner).Times(1).Return(holder, nil)
+				store.EXPECT().UnfreezeAccount(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "When closing an account with a balance",
+			action: "close",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().CloseAccount(gomock.Any(), account.ID).Times(1).Return(db.Account{}, db.ErrAccountBalanceNotZero)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:   "When reopening a closed account",
+			action: "unfreeze",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().UnfreezeAccount(gomock.Any(), account.ID).
+					Times(1).
+					Return(db.Account{}, fmt.Errorf("%w: from closed to active", db.ErrInvalidAccountTransition))
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "When account belongs to another user",
+			action: "close",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "Emmanuel", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().CloseAccount(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "When authorization is not provided",
+			action:    "freeze",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().FreezeAccount(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			url := fmt.Sprintf("/accounts/%d/%s", account.ID, tt.action)
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+			tt.setupAuth(t, request, server.tokenMaker)
 
 			server.router.ServeHTTP(recorder, request)
 
@@ -154,24 +418,32 @@ func Test_accountHandler_post(t *testing.T) {
 	tests := []struct {
 		name          string
 		requestBody   createAccountRequest
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(ctrl *gomock.Controller) stub
 		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name: "When it succeeds",
 			requestBody: createAccountRequest{
-				Owner:    "Emmanuel Perotto",
 				Currency: "USD",
 			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "perotto", time.Minute)
+			},
 			buildStubs: func(ctrl *gomock.Controller) stub {
 				store := mockdb.NewMockStore(ctrl)
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).
+				store.EXPECT().CreateAccountTx(gomock.Any(), db.CreateAccountParams{
+					Owner:    "perotto",
+					Balance:  0,
+					Currency: "USD",
+				}).
 					Return(db.Account{
 						ID:        1,
-						Owner:     "Emmanuel Perotto",
+						Owner:     "perotto",
 						Balance:   0,
 						Currency:  "USD",
 						CreatedAt: defaultCreatedAt,
+						Status:    db.AccountStatusActive,
 					}, nil)
 
 				return stub{
@@ -185,16 +457,60 @@ func Test_accountHandler_post(t *testing.T) {
 				assert.Equal(t, http.StatusCreated, recorder.Code)
 
 				wantResponseBody := gin.H{
-					"id":         float64(1),
-					"owner":      "Emmanuel Perotto",
-					"currency":   "USD",
-					"balance":    float64(0),
-					"created_at": "2022-04-24T21:18:00Z",
+					"id":                float64(1),
+					"owner":             "perotto",
+					"currency":          "USD",
+					"balance":           float64(0),
+					"held_balance":      float64(0),
+					"available_balance": float64(0),
+					"created_at":        "2022-04-24T21:18:00Z",
+					"status":            "active",
+					"overdraft_limit":   float64(0),
 				}
 
 				assert.Equal(t, wantResponseBody, responseBody)
 			},
 		},
+		{
+			name: "When currency is not enabled",
+			requestBody: createAccountRequest{
+				Currency: "CAD",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "perotto", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{
+					store: store,
+				}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "When currency is unknown",
+			requestBody: createAccountRequest{
+				Currency: "XYZ",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "perotto", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{
+					store: store,
+				}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -205,7 +521,7 @@ func Test_accountHandler_post(t *testing.T) {
 
 			//Start test server and send request
 			url := "/accounts"
-			server := NewServer(stubs.store)
+			server := newTestServer(t, stubs.store)
 			recorder := httptest.NewRecorder()
 
 			bodyBytes, err := json.Marshal(tt.requestBody)
@@ -213,6 +529,484 @@ func Test_accountHandler_post(t *testing.T) {
 
 			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(bodyBytes))
 			require.NoError(t, err)
+			tt.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
+
+func Test_accountHandler_list(t *testing.T) {
+	accounts := []db.Account{
+		{
+			ID:        1,
+			Owner:     "perotto",
+			Balance:   100,
+			Currency:  "USD",
+			CreatedAt: defaultCreatedAt,
+		},
+		{
+			ID:        2,
+			Owner:     "perotto",
+			Balance:   50,
+			Currency:  "EUR",
+			CreatedAt: defaultCreatedAt,
+		},
+	}
+
+	nextCursor := url.QueryEscape(encodeTestCursor(t, cursor.Cursor{CreatedAt: defaultCreatedAt, ID: 10}))
+	prevCursor := url.QueryEscape(encodeTestCursor(t, cursor.Cursor{CreatedAt: defaultCreatedAt, ID: 10, Backward: true}))
+	forgedCursor := url.QueryEscape(nextCursor[:len(nextCursor)-2])
+
+	tests := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "When it lists the accounts of the authenticated user",
+			query: "page_id=2&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "perotto", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().ListAccountsByOwner(gomock.Any(), db.ListAccountsByOwnerParams{
+					Owner:  "perotto",
+					Limit:  5,
+					Offset: 5,
+				}).
+					Times(1).
+					Return(accounts, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody []db.Account
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, "true", recorder.Header().Get("Deprecation"))
+				assert.Equal(t, accounts, responseBody)
+			},
+		},
+		{
+			name:  "When it lists the first page by cursor",
+			query: "page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "perotto", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().ListAccountsByOwnerAfter(gomock.Any(), db.ListAccountsByOwnerAfterParams{
+					Owner: "perotto",
+					Limit: 6,
+				}).
+					Times(1).
+					Return(accounts, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody struct {
+					Data       []db.Account `json:"data"`
+					NextCursor string       `json:"next_cursor"`
+					PrevCursor string       `json:"prev_cursor"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Empty(t, recorder.Header().Get("Deprecation"))
+				assert.Equal(t, accounts, responseBody.Data)
+				assert.Empty(t, responseBody.NextCursor)
+				assert.Empty(t, responseBody.PrevCursor)
+			},
+		},
+		{
+			name:  "When it lists the next page by cursor",
+			query: "page_size=5&cursor=" + nextCursor,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "perotto", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().ListAccountsByOwnerAfter(gomock.Any(), db.ListAccountsByOwnerAfterParams{
+					Owner:           "perotto",
+					CursorCreatedAt: sql.NullTime{Time: defaultCreatedAt, Valid: true},
+					CursorID:        sql.NullInt64{Int64: 10, Valid: true},
+					Limit:           6,
+				}).
+					Times(1).
+					Return(accounts, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody pageResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Empty(t, responseBody.NextCursor)
+				assert.NotEmpty(t, responseBody.PrevCursor)
+			},
+		},
+		{
+			name:  "When it lists the previous page by cursor",
+			query: "page_size=5&cursor=" + prevCursor,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "perotto", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().ListAccountsByOwnerBefore(gomock.Any(), db.ListAccountsByOwnerBeforeParams{
+					Owner:           "perotto",
+					CursorCreatedAt: defaultCreatedAt,
+					CursorID:        10,
+					Limit:           6,
+				}).
+					Times(1).
+					Return([]db.Account{accounts[1], accounts[0]}, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody struct {
+					Data       []db.Account `json:"data"`
+					NextCursor string       `json:"next_cursor"`
+					PrevCursor string       `json:"prev_cursor"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, accounts, responseBody.Data)
+				assert.NotEmpty(t, responseBody.NextCursor)
+				assert.Empty(t, responseBody.PrevCursor)
+			},
+		},
+		{
+			name:  "When cursor is forged",
+			query: "page_size=5&cursor=" + forgedCursor,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "perotto", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().ListAccountsByOwnerAfter(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAccountsByOwnerBefore(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody gin.H
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+				assert.Equal(t, "invalid cursor", responseBody["error"])
+			},
+		},
+		{
+			name:  "When both page_id and cursor are given",
+			query: "page_id=1&page_size=5&cursor=" + nextCursor,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "perotto", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().ListAccountsByOwner(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAccountsByOwnerAfter(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "When page size is out of range",
+			query: "page_id=1&page_size=50",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "perotto", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().ListAccountsByOwner(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "When authorization type is not supported",
+			query: "page_id=1&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, "basic", "perotto", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().ListAccountsByOwner(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody gin.H
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+				assert.Equal(t, "unsupported authorization type", responseBody["error"])
+			},
+		},
+		{
+			name:  "When token has expired",
+			query: "page_id=1&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "perotto", -time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().ListAccountsByOwner(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody gin.H
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+				assert.Equal(t, "token has expired", responseBody["error"])
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/accounts?"+tt.query, nil)
+			require.NoError(t, err)
+			tt.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
+
+func Test_accountHandler_listEntries(t *testing.T) {
+	account := db.Account{
+		ID:        1,
+		Owner:     "perotto",
+		Balance:   80,
+		Currency:  "USD",
+		CreatedAt: defaultCreatedAt,
+	}
+	entries := []db.ListEntriesByAccountRow{
+		{
+			ID:             1,
+			AccountID:      account.ID,
+			Amount:         100,
+			CreatedAt:      defaultCreatedAt,
+			RunningBalance: 100,
+		},
+		{
+			ID:             2,
+			AccountID:      account.ID,
+			Amount:         -20,
+			CreatedAt:      defaultCreatedAt,
+			RunningBalance: 80,
+		},
+	}
+	from := time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	prevCursor := url.QueryEscape(encodeTestCursor(t, cursor.Cursor{CreatedAt: defaultCreatedAt, ID: 3, Backward: true}))
+
+	tests := []struct {
+		name          string
+		accountID     int64
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "When it lists the entries of the account",
+			accountID: account.ID,
+			query:     "page_id=1&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().ListEntriesByAccount(gomock.Any(), db.ListEntriesByAccountParams{
+					AccountID: account.ID,
+					Limit:     5,
+					Offset:    0,
+				}).
+					Times(1).
+					Return(entries, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody []db.ListEntriesByAccountRow
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, entries, responseBody)
+			},
+		},
+		{
+			name:      "When it lists the previous page of entries by cursor",
+			accountID: account.ID,
+			query:     "page_size=5&cursor=" + prevCursor,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().ListEntriesByAccountBefore(gomock.Any(), db.ListEntriesByAccountBeforeParams{
+					AccountID:       account.ID,
+					CursorCreatedAt: defaultCreatedAt,
+					CursorID:        3,
+					Limit:           6,
+				}).
+					Times(1).
+					Return([]db.ListEntriesByAccountBeforeRow{
+						db.ListEntriesByAccountBeforeRow(entries[1]),
+						db.ListEntriesByAccountBeforeRow(entries[0]),
+					}, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody struct {
+					Data       []db.ListEntriesByAccountRow `json:"data"`
+					NextCursor string                       `json:"next_cursor"`
+					PrevCursor string                       `json:"prev_cursor"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, entries, responseBody.Data)
+				assert.NotEmpty(t, responseBody.NextCursor)
+				assert.Empty(t, responseBody.PrevCursor)
+			},
+		},
+		{
+			name:      "When it filters the entries by date",
+			accountID: account.ID,
+			query:     "page_id=1&page_size=5&from=2022-04-01T00:00:00Z&to=2022-05-01T00:00:00Z",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().ListEntriesByAccount(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, params db.ListEntriesByAccountParams) ([]db.ListEntriesByAccountRow, error) {
+						assert.True(t, params.FromTime.Valid)
+						assert.True(t, from.Equal(params.FromTime.Time))
+						assert.True(t, params.ToTime.Valid)
+						assert.True(t, to.Equal(params.ToTime.Time))
+
+						return entries, nil
+					})
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "When the date range is inverted",
+			accountID: account.ID,
+			query:     "page_id=1&page_size=5&from=2022-05-01T00:00:00Z&to=2022-04-01T00:00:00Z",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListEntriesByAccount(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "When account belongs to another user",
+			accountID: account.ID,
+			query:     "page_id=1&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "someoneelse", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().ListEntriesByAccount(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "When account is not found",
+			accountID: account.ID,
+			query:     "page_id=1&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().ListEntriesByAccount(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d/entries?%s", tt.accountID, tt.query), nil)
+			require.NoError(t, err)
+			tt.setupAuth(t, request, server.tokenMaker)
 
 			server.router.ServeHTTP(recorder, request)
 
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	db "simplebank/db/sqlc"
+	"time"
+)
+
+//holdHandler handles all HTTP requests in Holds domain.
+type (
+	holdHandler struct {
+		store   db.Store
+		holdTTL time.Duration
+	}
+	placeHoldRequest struct {
+		AccountID   int64  `json:"account_id" binding:"required,min=1"`
+		ToAccountID int64  `json:"to_account_id" binding:"required,min=1,nefield=AccountID"`
+		Amount      int64  `json:"amount" binding:"required,gt=0"`
+		Currency    string `json:"currency" binding:"required,currency"`
+	}
+	getHoldRequest struct {
+		ID int64 `uri:"id" binding:"required,min=1"`
+	}
+	//captureHoldRequest captures the whole hold when Amount is omitted
+	captureHoldRequest struct {
+		Amount int64 `json:"amount" binding:"omitempty,gt=0"`
+	}
+)
+
+//newHoldHandler builds holdHandler struct
+func newHoldHandler(store db.Store, holdTTL time.Duration) holdHandler {
+	return holdHandler{
+		store:   store,
+		holdTTL: holdTTL,
+	}
+}
+
+func (h holdHandler) post(ctx *gin.Context) {
+	var req placeHoldRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := validAccount(ctx, h.store, req.AccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	if account.Owner != authPayload(ctx).Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(errAccountNotOwned))
+		return
+	}
+
+	if _, valid := validAccount(ctx, h.store, req.ToAccountID, req.Currency); !valid {
+		return
+	}
+
+	result, err := h.store.PlaceHold(ctx, db.PlaceHoldParams{
+		AccountID:   req.AccountID,
+		ToAccountID: req.ToAccountID,
+		Amount:      req.Amount,
+		TTL:         h.holdTTL,
+	})
+	if err != nil {
+		holdErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, result)
+}
+
+func (h holdHandler) get(ctx *gin.Context) {
+	var uri getHoldRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, ok := h.ownedHold(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hold)
+}
+
+func (h holdHandler) capture(ctx *gin.Context) {
+	var uri getHoldRequest
+	var req captureHoldRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := h.ownedHold(ctx, uri.ID); !ok {
+		return
+	}
+
+	result, err := h.store.CaptureHold(ctx, db.CaptureHoldParams{
+		ID:     uri.ID,
+		Amount: req.Amount,
+	})
+	if err != nil {
+		holdErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (h holdHandler) release(ctx *gin.Context) {
+	var uri getHoldRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := h.ownedHold(ctx, uri.ID); !ok {
+		return
+	}
+
+	result, err := h.store.ReleaseHold(ctx, uri.ID)
+	if err != nil {
+		holdErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+//ownedHold loads the hold and checks its source account belongs to the authenticated user.
+//It writes the error response and returns false when the hold can't be used.
+func (h holdHandler) ownedHold(ctx *gin.Context, id int64) (db.Hold, bool) {
+	hold, err := h.store.GetHold(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("hold %d not found", id)))
+			return hold, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return hold, false
+	}
+
+	if _, ok := ownedAccount(ctx, h.store, hold.AccountID); !ok {
+		return hold, false
+	}
+
+	return hold, true
+}
+
+//holdErrorResponse writes the response matching an error returned by a hold transaction
+func holdErrorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, db.ErrHoldNotActive):
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+	case errors.Is(err, db.ErrCaptureExceedsHold), errors.Is(err, db.ErrInsufficientFunds), errors.Is(err, db.ErrInvalidAmount):
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+	case errors.Is(err, db.ErrAccountFrozen), errors.Is(err, db.ErrAccountClosed):
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+	case errors.Is(err, db.ErrTransferLimitExceeded):
+		ctx.JSON(http.StatusUnprocessableEntity, codedErrorResponse(transferLimitExceededCode, err))
+	default:
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+	}
+}
@@ -0,0 +1,499 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	db "simplebank/db/sqlc"
+	mockdb "simplebank/db/sqlc/mock"
+	"simplebank/token"
+	"testing"
+	"time"
+)
+
+func Test_holdHandler_post(t *testing.T) {
+	account := db.Account{
+		ID:        1,
+		Owner:     "Perotto",
+		Balance:   100,
+		Currency:  "USD",
+		CreatedAt: defaultCreatedAt,
+	}
+	merchant := db.Account{
+		ID:        2,
+		Owner:     "Emmanuel",
+		Currency:  "USD",
+		CreatedAt: defaultCreatedAt,
+	}
+
+	tests := []struct {
+		name          string
+		requestBody   gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "When it succeeds",
+			requestBody: gin.H{
+				"account_id":    account.ID,
+				"to_account_id": merchant.ID,
+				"amount":        60,
+				"currency":      "USD",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), merchant.ID).Times(1).Return(merchant, nil)
+				store.EXPECT().PlaceHold(gomock.Any(), db.PlaceHoldParams{
+					AccountID:   account.ID,
+					ToAccountID: merchant.ID,
+					Amount:      60,
+					TTL:         time.Hour,
+				}).
+					Times(1).
+					Return(db.PlaceHoldResult{
+						Hold: db.Hold{ID: 7, AccountID: account.ID, ToAccountID: merchant.ID, Amount: 60, Status: db.HoldStatusActive},
+					}, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody db.PlaceHoldResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+				assert.Equal(t, int64(7), responseBody.Hold.ID)
+				assert.Equal(t, db.HoldStatusActive, responseBody.Hold.Status)
+			},
+		},
+		{
+			name: "When available balance is insufficient",
+			requestBody: gin.H{
+				"account_id":    account.ID,
+				"to_account_id": merchant.ID,
+				"amount":        160,
+				"currency":      "USD",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), merchant.ID).Times(1).Return(merchant, nil)
+				store.EXPECT().PlaceHold(gomock.Any(), gomock.Any()).Times(1).Return(db.PlaceHoldResult{}, db.ErrInsufficientFunds)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "When a transfer limit is exceeded",
+			requestBody: gin.H{
+				"account_id":    account.ID,
+				"to_account_id": merchant.ID,
+				"amount":        60,
+				"currency":      "USD",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), merchant.ID).Times(1).Return(merchant, nil)
+				store.EXPECT().PlaceHold(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PlaceHoldResult{}, fmt.Errorf("%w: account daily amount limit is 100, 50 already sent", db.ErrTransferLimitExceeded))
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody gin.H
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				assert.Equal(t, transferLimitExceededCode, responseBody["code"])
+			},
+		},
+		{
+			name: "When source account belongs to another user",
+			requestBody: gin.H{
+				"account_id":    account.ID,
+				"to_account_id": merchant.ID,
+				"amount":        60,
+				"currency":      "USD",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, merchant.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().PlaceHold(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "When amount is not positive",
+			requestBody: gin.H{
+				"account_id":    account.ID,
+				"to_account_id": merchant.ID,
+				"amount":        0,
+				"currency":      "USD",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().PlaceHold(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "When authorization is not provided",
+			requestBody: gin.H{
+				"account_id":    account.ID,
+				"to_account_id": merchant.ID,
+				"amount":        60,
+				"currency":      "USD",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().PlaceHold(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			bodyBytes, err := json.Marshal(tt.requestBody)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/holds", bytes.NewReader(bodyBytes))
+			require.NoError(t, err)
+			tt.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
+
+func Test_holdHandler_capture(t *testing.T) {
+	account := db.Account{
+		ID:          1,
+		Owner:       "Perotto",
+		Balance:     100,
+		HeldBalance: 60,
+		Currency:    "USD",
+		CreatedAt:   defaultCreatedAt,
+	}
+	hold := db.Hold{
+		ID:          7,
+		AccountID:   account.ID,
+		ToAccountID: 2,
+		Amount:      60,
+		Status:      db.HoldStatusActive,
+	}
+
+	tests := []struct {
+		name          string
+		holdID        int64
+		body          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "When it captures the whole hold",
+			holdID: hold.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetHold(gomock.Any(), hold.ID).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().CaptureHold(gomock.Any(), db.CaptureHoldParams{ID: hold.ID}).
+					Times(1).
+					Return(db.CaptureHoldResult{
+						TransferTxResult: db.TransferTxResult{Transfer: db.Transfer{ID: 3, Amount: 60}},
+						Hold:             db.Hold{ID: hold.ID, Status: db.HoldStatusCaptured, CapturedAmount: 60},
+					}, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody db.CaptureHoldResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, int64(3), responseBody.Transfer.ID)
+				assert.Equal(t, db.HoldStatusCaptured, responseBody.Hold.Status)
+			},
+		},
+		{
+			name:   "When it captures part of the hold",
+			holdID: hold.ID,
+			body:   `{"amount": 25}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetHold(gomock.Any(), hold.ID).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().CaptureHold(gomock.Any(), db.CaptureHoldParams{ID: hold.ID, Amount: 25}).
+					Times(1).
+					Return(db.CaptureHoldResult{}, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "When capture exceeds the hold",
+			holdID: hold.ID,
+			body:   `{"amount": 90}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetHold(gomock.Any(), hold.ID).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().CaptureHold(gomock.Any(), gomock.Any()).Times(1).Return(db.CaptureHoldResult{}, db.ErrCaptureExceedsHold)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:   "When hold is no longer active",
+			holdID: hold.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetHold(gomock.Any(), hold.ID).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().CaptureHold(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CaptureHoldResult{}, fmt.Errorf("%w: hold 7 is expired", db.ErrHoldNotActive))
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "When hold is not found",
+			holdID: hold.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetHold(gomock.Any(), hold.ID).Times(1).Return(db.Hold{}, sql.ErrNoRows)
+				store.EXPECT().CaptureHold(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "When hold belongs to another user",
+			holdID: hold.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "emmanuel", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetHold(gomock.Any(), hold.ID).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().CaptureHold(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "When amount is not positive",
+			holdID: hold.ID,
+			body:   `{"amount": -5}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetHold(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CaptureHold(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			url := fmt.Sprintf("/holds/%d/capture", tt.holdID)
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(tt.body))
+			require.NoError(t, err)
+			tt.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
+
+func Test_holdHandler_release(t *testing.T) {
+	account := db.Account{
+		ID:          1,
+		Owner:       "Perotto",
+		Balance:     100,
+		HeldBalance: 60,
+		Currency:    "USD",
+		CreatedAt:   defaultCreatedAt,
+	}
+	hold := db.Hold{
+		ID:          7,
+		AccountID:   account.ID,
+		ToAccountID: 2,
+		Amount:      60,
+		Status:      db.HoldStatusActive,
+	}
+
+	tests := []struct {
+		name          string
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "When it succeeds",
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetHold(gomock.Any(), hold.ID).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().ReleaseHold(gomock.Any(), hold.ID).
+					Times(1).
+					Return(db.ReleaseHoldResult{
+						Hold:    db.Hold{ID: hold.ID, Status: db.HoldStatusReleased},
+						Account: db.Account{ID: account.ID, Balance: 100},
+					}, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody db.ReleaseHoldResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, db.HoldStatusReleased, responseBody.Hold.Status)
+				assert.Zero(t, responseBody.Account.HeldBalance)
+			},
+		},
+		{
+			name: "When hold is no longer active",
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetHold(gomock.Any(), hold.ID).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().ReleaseHold(gomock.Any(), hold.ID).Times(1).Return(db.ReleaseHoldResult{}, db.ErrHoldNotActive)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			url := fmt.Sprintf("/holds/%d/release", hold.ID)
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
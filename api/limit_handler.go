@@ -0,0 +1,125 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	db "simplebank/db/sqlc"
+)
+
+//limitHandler handles all HTTP requests on outbound transfer limits.
+type (
+	limitHandler struct {
+		store db.Store
+	}
+	//setTransferLimitsRequest replaces every limit, an omitted limit is unlimited
+	setTransferLimitsRequest struct {
+		DailyAmount   int64 `json:"daily_amount" binding:"omitempty,min=1"`
+		MonthlyAmount int64 `json:"monthly_amount" binding:"omitempty,min=1"`
+		DailyCount    int64 `json:"daily_count" binding:"omitempty,min=1"`
+	}
+	setUserTransferLimitsRequest struct {
+		Username string `uri:"username" binding:"required,alphanum"`
+		Currency string `uri:"currency" binding:"required,currency"`
+	}
+)
+
+//newLimitHandler builds limitHandler struct
+func newLimitHandler(store db.Store) limitHandler {
+	return limitHandler{
+		store: store,
+	}
+}
+
+//get returns the limits applying to transfers from the account and how much of them is used
+func (h limitHandler) get(ctx *gin.Context) {
+	var uri getAccountRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := ownedAccount(ctx, h.store, uri.ID)
+	if !ok {
+		return
+	}
+
+	usage, err := h.store.GetTransferLimitsUsage(ctx, account)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, usage)
+}
+
+//putAccount sets the limits of a single account
+func (h limitHandler) putAccount(ctx *gin.Context) {
+	var uri getAccountRequest
+	var req setTransferLimitsRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	limit, err := h.store.UpsertAccountTransferLimit(ctx, db.UpsertAccountTransferLimitParams{
+		AccountID:     sql.NullInt64{Int64: uri.ID, Valid: true},
+		DailyAmount:   nullInt64(req.DailyAmount),
+		MonthlyAmount: nullInt64(req.MonthlyAmount),
+		DailyCount:    nullInt64(req.DailyCount),
+	})
+	if err != nil {
+		if pqErrorCode(err) == foreignKeyViolation {
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("account not found")))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, limit)
+}
+
+//putUser sets the limits shared by all the accounts of a user in a currency
+func (h limitHandler) putUser(ctx *gin.Context) {
+	var uri setUserTransferLimitsRequest
+	var req setTransferLimitsRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	limit, err := h.store.UpsertUserTransferLimit(ctx, db.UpsertUserTransferLimitParams{
+		Owner:         sql.NullString{String: uri.Username, Valid: true},
+		Currency:      sql.NullString{String: uri.Currency, Valid: true},
+		DailyAmount:   nullInt64(req.DailyAmount),
+		MonthlyAmount: nullInt64(req.MonthlyAmount),
+		DailyCount:    nullInt64(req.DailyCount),
+	})
+	if err != nil {
+		if pqErrorCode(err) == foreignKeyViolation {
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("user not found")))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, limit)
+}
@@ -0,0 +1,261 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	db "simplebank/db/sqlc"
+	mockdb "simplebank/db/sqlc/mock"
+	"simplebank/token"
+	"simplebank/util"
+	"testing"
+	"time"
+)
+
+func Test_limitHandler_get(t *testing.T) {
+	account := db.Account{
+		ID:        10,
+		Owner:     "Perotto",
+		Balance:   100,
+		Currency:  "USD",
+		CreatedAt: defaultCreatedAt,
+	}
+	dailyLimit := int64(500)
+
+	tests := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "When it returns the usage",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetTransferLimitsUsage(gomock.Any(), account).
+					Times(1).
+					Return(db.AccountTransferLimitsUsage{
+						Account: db.TransferLimitsUsage{DailyAmount: db.LimitUsage{Limit: &dailyLimit, Used: 120}},
+					}, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody db.AccountTransferLimitsUsage
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, db.LimitUsage{Limit: &dailyLimit, Used: 120}, responseBody.Account.DailyAmount)
+				assert.Nil(t, responseBody.User.DailyAmount.Limit)
+			},
+		},
+		{
+			name: "When account belongs to another user",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "Emmanuel", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetTransferLimitsUsage(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "When authorization is not provided",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetTransferLimitsUsage(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			url := fmt.Sprintf("/accounts/%d/limits", account.ID)
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+			tt.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
+
+func Test_limitHandler_put(t *testing.T) {
+	admin := db.User{Username: "admin", Role: util.AdminRole}
+	depositor := db.User{Username: "perotto", Role: util.DepositorRole}
+
+	tests := []struct {
+		name          string
+		url           string
+		requestBody   gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "When an admin sets the account limits",
+			url:         "/accounts/10/limits",
+			requestBody: gin.H{"daily_amount": 500, "daily_count": 3},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().UpsertAccountTransferLimit(gomock.Any(), db.UpsertAccountTransferLimitParams{
+					AccountID:   sql.NullInt64{Int64: 10, Valid: true},
+					DailyAmount: sql.NullInt64{Int64: 500, Valid: true},
+					DailyCount:  sql.NullInt64{Int64: 3, Valid: true},
+				}).
+					Times(1).
+					Return(db.TransferLimit{ID: 1}, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:        "When an admin sets the user limits",
+			url:         "/users/perotto/limits/USD",
+			requestBody: gin.H{"monthly_amount": 10000},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().UpsertUserTransferLimit(gomock.Any(), db.UpsertUserTransferLimitParams{
+					Owner:         sql.NullString{String: "perotto", Valid: true},
+					Currency:      sql.NullString{String: "USD", Valid: true},
+					MonthlyAmount: sql.NullInt64{Int64: 10000, Valid: true},
+				}).
+					Times(1).
+					Return(db.TransferLimit{ID: 2}, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:        "When user doesn't exist",
+			url:         "/users/ghost/limits/USD",
+			requestBody: gin.H{"monthly_amount": 10000},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().UpsertUserTransferLimit(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferLimit{}, &pq.Error{Code: foreignKeyViolation})
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:        "When a limit is negative",
+			url:         "/accounts/10/limits",
+			requestBody: gin.H{"daily_amount": -5},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().UpsertAccountTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "When caller is not an admin",
+			url:         "/accounts/10/limits",
+			requestBody: gin.H{"daily_amount": 1_000_000},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, depositor.Username, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), depositor.Username).Times(1).Return(depositor, nil)
+				store.EXPECT().UpsertAccountTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			bodyBytes, err := json.Marshal(tt.requestBody)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, tt.url, bytes.NewReader(bodyBytes))
+			require.NoError(t, err)
+			tt.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
@@ -1,13 +1,60 @@
 package api
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net/http"
 	"os"
+	"simplebank/cursor"
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+	"simplebank/util"
 	"testing"
+	"time"
 )
 
+//testCursorSigningKey is shared by all test servers so cursors can be built before the server
+var testCursorSigningKey = util.RandomString(32)
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 
 	os.Exit(m.Run())
 }
+
+func newTestConfig() util.Config {
+	return util.Config{
+		TokenSymmetricKey:    util.RandomString(32),
+		AccessTokenDuration:  time.Minute,
+		RefreshTokenDuration: time.Hour,
+		IdempotencyKeyTTL:    time.Hour,
+		CursorSigningKey:     testCursorSigningKey,
+		FXRatesFile:          "../fx/testdata/rates.json",
+		FXSpreadBps:          50,
+		HoldTTL:              time.Hour,
+	}
+}
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	server, err := NewServer(newTestConfig(), store)
+	require.NoError(t, err)
+
+	return server
+}
+
+func addAuthorization(t *testing.T, request *http.Request, tokenMaker token.Maker, authorizationType string, username string, duration time.Duration) {
+	accessToken, payload, err := tokenMaker.CreateToken(username, token.TokenTypeAccess, duration)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+
+	authorizationHeader := fmt.Sprintf("%s %s", authorizationType, accessToken)
+	request.Header.Set(authorizationHeaderKey, authorizationHeader)
+}
+
+func encodeTestCursor(t *testing.T, c cursor.Cursor) string {
+	codec, err := cursor.NewCodec(testCursorSigningKey)
+	require.NoError(t, err)
+
+	return codec.Encode(c)
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+	"simplebank/util"
+	"strings"
+)
+
+const (
+	authorizationHeaderKey  = "authorization"
+	authorizationTypeBearer = "bearer"
+	authorizationPayloadKey = "authorization_payload"
+)
+
+//authMiddleware verifies the bearer access token of the request and stores its payload in the gin context.
+//It aborts with 401 when the token is missing, invalid or a refresh token.
+func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
+		if authorizationHeader == "" {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(errors.New("authorization header is not provided")))
+			return
+		}
+
+		fields := strings.Fields(authorizationHeader)
+		if len(fields) != 2 {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(errors.New("invalid authorization header format")))
+			return
+		}
+
+		if strings.ToLower(fields[0]) != authorizationTypeBearer {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(errors.New("unsupported authorization type")))
+			return
+		}
+
+		payload, err := tokenMaker.VerifyToken(fields[1], token.TokenTypeAccess)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		ctx.Set(authorizationPayloadKey, payload)
+		ctx.Next()
+	}
+}
+
+//adminMiddleware only lets through authenticated users holding the admin role.
+//It must be registered after authMiddleware and aborts with 403 otherwise.
+func adminMiddleware(store db.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user, err := store.GetUser(ctx, authPayload(ctx).Username)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(errors.New("admin role required")))
+			return
+		}
+
+		if user.Role != util.AdminRole {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(errors.New("admin role required")))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+//authPayload returns the token payload stored by authMiddleware
+func authPayload(ctx *gin.Context) *token.Payload {
+	return ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	db "simplebank/db/sqlc"
+)
+
+//overdraftHandler handles the admin HTTP requests on account overdraft limits.
+type (
+	overdraftHandler struct {
+		store db.Store
+	}
+	//setOverdraftLimitRequest requires a reason, kept with the admin's username in the audit trail
+	setOverdraftLimitRequest struct {
+		Limit  *int64 `json:"limit" binding:"required,min=0"`
+		Reason string `json:"reason" binding:"required,max=500"`
+	}
+)
+
+//newOverdraftHandler builds overdraftHandler struct
+func newOverdraftHandler(store db.Store) overdraftHandler {
+	return overdraftHandler{
+		store: store,
+	}
+}
+
+//put sets how far below zero the account may go
+func (h overdraftHandler) put(ctx *gin.Context) {
+	var uri getAccountRequest
+	var req setOverdraftLimitRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := h.store.SetOverdraftLimit(ctx, db.SetOverdraftLimitParams{
+		AccountID: uri.ID,
+		Limit:     *req.Limit,
+		ChangedBy: authPayload(ctx).Username,
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("account not found")))
+		case errors.Is(err, db.ErrOverdraftLimitTooLow), errors.Is(err, db.ErrAccountClosed):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+//listChanges returns the audit trail of the account overdraft limit, oldest first
+func (h overdraftHandler) listChanges(ctx *gin.Context) {
+	var uri getAccountRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	changes, err := h.store.ListOverdraftLimitChanges(ctx, uri.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, changes)
+}
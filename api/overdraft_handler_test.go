@@ -0,0 +1,185 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	db "simplebank/db/sqlc"
+	mockdb "simplebank/db/sqlc/mock"
+	"simplebank/token"
+	"simplebank/util"
+	"testing"
+	"time"
+)
+
+func Test_overdraftHandler_put(t *testing.T) {
+	admin := db.User{Username: "admin", Role: util.AdminRole}
+	depositor := db.User{Username: "perotto", Role: util.DepositorRole}
+	var accountID int64 = 10
+
+	tests := []struct {
+		name          string
+		requestBody   gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "When an admin sets the limit",
+			requestBody: gin.H{"limit": 50000, "reason": "agreed in the business plan"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().SetOverdraftLimit(gomock.Any(), db.SetOverdraftLimitParams{
+					AccountID: accountID,
+					Limit:     50000,
+					ChangedBy: admin.Username,
+					Reason:    "agreed in the business plan",
+				}).
+					Times(1).
+					Return(db.SetOverdraftLimitResult{
+						Account: db.Account{ID: accountID, OverdraftLimit: 50000},
+						Change:  db.OverdraftLimitChange{ID: 1, AccountID: accountID, NewLimit: 50000, ChangedBy: admin.Username},
+					}, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody db.SetOverdraftLimitResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, int64(50000), responseBody.Account.OverdraftLimit)
+				assert.Equal(t, admin.Username, responseBody.Change.ChangedBy)
+			},
+		},
+		{
+			name:        "When an admin removes the limit",
+			requestBody: gin.H{"limit": 0, "reason": "plan ended"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().SetOverdraftLimit(gomock.Any(), db.SetOverdraftLimitParams{
+					AccountID: accountID,
+					Limit:     0,
+					ChangedBy: admin.Username,
+					Reason:    "plan ended",
+				}).
+					Times(1).
+					Return(db.SetOverdraftLimitResult{}, db.ErrOverdraftLimitTooLow)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:        "When account doesn't exist",
+			requestBody: gin.H{"limit": 100, "reason": "agreed"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().SetOverdraftLimit(gomock.Any(), gomock.Any()).Times(1).Return(db.SetOverdraftLimitResult{}, sql.ErrNoRows)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:        "When reason is missing",
+			requestBody: gin.H{"limit": 100},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().SetOverdraftLimit(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "When limit is negative",
+			requestBody: gin.H{"limit": -1, "reason": "agreed"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().SetOverdraftLimit(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "When caller is not an admin",
+			requestBody: gin.H{"limit": 100, "reason": "agreed"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, depositor.Username, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), depositor.Username).Times(1).Return(depositor, nil)
+				store.EXPECT().SetOverdraftLimit(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			bodyBytes, err := json.Marshal(tt.requestBody)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/overdraft_limit", accountID)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(bodyBytes))
+			require.NoError(t, err)
+			tt.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
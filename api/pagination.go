@@ -0,0 +1,45 @@
+package api
+
+import (
+	"database/sql"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"simplebank/cursor"
+)
+
+//pageResponse is the envelope of cursor paginated lists.
+//The cursors are omitted when there is no page in that direction.
+type pageResponse struct {
+	Data       interface{} `json:"data"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+	PrevCursor string      `json:"prev_cursor,omitempty"`
+}
+
+//decodeCursor returns the position of an opaque cursor, or nil when it is empty to start from the first page.
+//It writes the error response and returns false when the cursor is invalid.
+func decodeCursor(ctx *gin.Context, codec cursor.Codec, s string) (*cursor.Cursor, bool) {
+	if s == "" {
+		return nil, true
+	}
+
+	c, err := codec.Decode(s)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return nil, false
+	}
+
+	return &c, true
+}
+
+//afterCursor returns the keyset position to read forward from, NULL on the first page
+func afterCursor(c *cursor.Cursor) (sql.NullTime, sql.NullInt64) {
+	if c == nil {
+		return sql.NullTime{}, sql.NullInt64{}
+	}
+	return sql.NullTime{Time: c.CreatedAt, Valid: true}, sql.NullInt64{Int64: c.ID, Valid: true}
+}
+
+//deprecateOffsetPaging flags responses paged with page_id, which is kept for one release in favor of cursors
+func deprecateOffsetPaging(ctx *gin.Context) {
+	ctx.Header("Deprecation", "true")
+}
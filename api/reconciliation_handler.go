@@ -0,0 +1,92 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	db "simplebank/db/sqlc"
+)
+
+//defaultReconciliationRuns is the number of runs listed when the request doesn't set a limit
+const defaultReconciliationRuns = 20
+
+//reconciliationHandler handles the admin HTTP requests on ledger reconciliation reports.
+type (
+	reconciliationHandler struct {
+		store db.Store
+	}
+	//listReconciliationRunsRequest lists the latest runs first
+	listReconciliationRunsRequest struct {
+		Limit int32 `form:"limit" binding:"omitempty,min=1,max=100"`
+	}
+	//getReconciliationRunRequest is the uri of a single run
+	getReconciliationRunRequest struct {
+		ID int64 `uri:"id" binding:"required,min=1"`
+	}
+	//reconciliationRunResponse is a run with the issues it found
+	reconciliationRunResponse struct {
+		db.ReconciliationRun
+		Issues []db.ReconciliationIssue `json:"issues"`
+	}
+)
+
+//newReconciliationHandler builds reconciliationHandler struct
+func newReconciliationHandler(store db.Store) reconciliationHandler {
+	return reconciliationHandler{
+		store: store,
+	}
+}
+
+//list returns the latest reconciliation runs without their issues
+func (h reconciliationHandler) list(ctx *gin.Context) {
+	var req listReconciliationRunsRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.Limit == 0 {
+		req.Limit = defaultReconciliationRuns
+	}
+
+	runs, err := h.store.ListReconciliationRuns(ctx, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, runs)
+}
+
+//get returns a reconciliation run with the drift and transfer issues it found
+func (h reconciliationHandler) get(ctx *gin.Context) {
+	var uri getReconciliationRunRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	run, err := h.store.GetReconciliationRun(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("reconciliation run not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	issues, err := h.store.ListReconciliationIssues(ctx, run.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reconciliationRunResponse{
+		ReconciliationRun: run,
+		Issues:            issues,
+	})
+}
@@ -0,0 +1,259 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	db "simplebank/db/sqlc"
+	mockdb "simplebank/db/sqlc/mock"
+	"simplebank/token"
+	"simplebank/util"
+	"testing"
+	"time"
+)
+
+func Test_reconciliationHandler_list(t *testing.T) {
+	admin := db.User{Username: "admin", Role: util.AdminRole}
+	depositor := db.User{Username: "perotto", Role: util.DepositorRole}
+	runs := []db.ReconciliationRun{
+		{ID: 2, AccountsChecked: 10, TransfersChecked: 30, IssuesCount: 1},
+		{ID: 1, AccountsChecked: 10, TransfersChecked: 25},
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "When an admin lists the runs",
+			query: "",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().ListReconciliationRuns(gomock.Any(), int32(defaultReconciliationRuns)).Times(1).Return(runs, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody []db.ReconciliationRun
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, runs, responseBody)
+			},
+		},
+		{
+			name:  "When an admin sets the limit",
+			query: "?limit=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().ListReconciliationRuns(gomock.Any(), int32(5)).Times(1).Return(runs, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "When limit is too big",
+			query: "?limit=1000",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().ListReconciliationRuns(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "When caller is not an admin",
+			query: "",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, depositor.Username, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), depositor.Username).Times(1).Return(depositor, nil)
+				store.EXPECT().ListReconciliationRuns(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "When DB fails",
+			query: "",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().ListReconciliationRuns(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/reconciliation_runs"+tt.query, nil)
+			require.NoError(t, err)
+			tt.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
+
+func Test_reconciliationHandler_get(t *testing.T) {
+	admin := db.User{Username: "admin", Role: util.AdminRole}
+	run := db.ReconciliationRun{ID: 7, AccountsChecked: 10, TransfersChecked: 30, IssuesCount: 1}
+	issues := []db.ReconciliationIssue{
+		{
+			ID:        1,
+			RunID:     run.ID,
+			Kind:      db.ReconciliationIssueAccountDrift,
+			AccountID: sql.NullInt64{Int64: 3, Valid: true},
+			Expected:  100,
+			Actual:    150,
+			Detail:    "balance is off by 50",
+		},
+	}
+
+	tests := []struct {
+		name          string
+		runID         int64
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "When the run exists",
+			runID: run.ID,
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().GetReconciliationRun(gomock.Any(), run.ID).Times(1).Return(run, nil)
+				store.EXPECT().ListReconciliationIssues(gomock.Any(), run.ID).Times(1).Return(issues, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody reconciliationRunResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, run, responseBody.ReconciliationRun)
+				assert.Equal(t, issues, responseBody.Issues)
+			},
+		},
+		{
+			name:  "When the run doesn't exist",
+			runID: 99,
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().GetReconciliationRun(gomock.Any(), int64(99)).Times(1).Return(db.ReconciliationRun{}, sql.ErrNoRows)
+				store.EXPECT().ListReconciliationIssues(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:  "When the id is invalid",
+			runID: 0,
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().GetReconciliationRun(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "When listing the issues fails",
+			runID: run.ID,
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().GetReconciliationRun(gomock.Any(), run.ID).Times(1).Return(run, nil)
+				store.EXPECT().ListReconciliationIssues(gomock.Any(), run.ID).Times(1).Return(nil, errors.New("connection reset"))
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/reconciliation_runs/%d", tt.runID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	db "simplebank/db/sqlc"
+	"simplebank/schedule"
+	"time"
+)
+
+var (
+	//errScheduledTransferNotOwned is returned when the scheduled transfer doesn't belong to the authenticated user
+	errScheduledTransferNotOwned = errors.New("scheduled transfer doesn't belong to the authenticated user")
+	//errScheduledTransferFinished is returned when changing a completed, failed or cancelled scheduled transfer
+	errScheduledTransferFinished = errors.New("scheduled transfer is no longer active")
+)
+
+//scheduledTransferHandler handles all HTTP requests in Scheduled Transfers domain.
+type (
+	scheduledTransferHandler struct {
+		store db.Store
+	}
+	//createScheduledTransferRequest runs once at StartAt, or from StartAt on every occurrence of Recurrence until EndAt
+	createScheduledTransferRequest struct {
+		FromAccountID int64     `json:"from_account_id" binding:"required,min=1"`
+		ToAccountID   int64     `json:"to_account_id" binding:"required,min=1,nefield=FromAccountID"`
+		Amount        int64     `json:"amount" binding:"required,gt=0"`
+		Currency      string    `json:"currency" binding:"required,currency"`
+		StartAt       time.Time `json:"start_at" binding:"required"`
+		Recurrence    string    `json:"recurrence"`
+		EndAt         time.Time `json:"end_at" binding:"omitempty,gtfield=StartAt"`
+	}
+	getScheduledTransferRequest struct {
+		ID int64 `uri:"id" binding:"required,min=1"`
+	}
+	//updateScheduledTransferRequest only changes the fields that are sent
+	updateScheduledTransferRequest struct {
+		Amount int64     `json:"amount" binding:"omitempty,gt=0"`
+		EndAt  time.Time `json:"end_at"`
+		Status string    `json:"status" binding:"omitempty,oneof=active paused"`
+	}
+	//scheduledTransferResponse is the public representation of db.ScheduledTransfer, it hides the worker lease
+	scheduledTransferResponse struct {
+		ID            int64      `json:"id"`
+		Owner         string     `json:"owner"`
+		FromAccountID int64      `json:"from_account_id"`
+		ToAccountID   int64      `json:"to_account_id"`
+		Amount        int64      `json:"amount"`
+		Recurrence    string     `json:"recurrence"`
+		ScheduledFor  time.Time  `json:"scheduled_for"`
+		NextRunAt     time.Time  `json:"next_run_at"`
+		EndAt         *time.Time `json:"end_at"`
+		Status        string     `json:"status"`
+		Attempts      int32      `json:"attempts"`
+		LastError     string     `json:"last_error"`
+		CreatedAt     time.Time  `json:"created_at"`
+	}
+)
+
+//newScheduledTransferHandler builds scheduledTransferHandler struct
+func newScheduledTransferHandler(store db.Store) scheduledTransferHandler {
+	return scheduledTransferHandler{
+		store: store,
+	}
+}
+
+func newScheduledTransferResponse(st db.ScheduledTransfer) scheduledTransferResponse {
+	rsp := scheduledTransferResponse{
+		ID:            st.ID,
+		Owner:         st.Owner,
+		FromAccountID: st.FromAccountID,
+		ToAccountID:   st.ToAccountID,
+		Amount:        st.Amount,
+		Recurrence:    st.Recurrence,
+		ScheduledFor:  st.ScheduledFor,
+		NextRunAt:     st.NextRunAt,
+		Status:        st.Status,
+		Attempts:      st.Attempts,
+		LastError:     st.LastError,
+		CreatedAt:     st.CreatedAt,
+	}
+	if st.EndAt.Valid {
+		rsp.EndAt = &st.EndAt.Time
+	}
+	return rsp
+}
+
+func (h scheduledTransferHandler) post(ctx *gin.Context) {
+	var req createScheduledTransferRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !req.StartAt.After(time.Now()) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("start_at must be in the future")))
+		return
+	}
+
+	recurrence := ""
+	if req.Recurrence != "" {
+		rule, err := schedule.ParseRule(req.Recurrence)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		recurrence = rule.Anchor(req.StartAt).String()
+	}
+
+	fromAccount, valid := validAccount(ctx, h.store, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	if fromAccount.Owner != authPayload(ctx).Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(errAccountNotOwned))
+		return
+	}
+
+	if _, valid := validAccount(ctx, h.store, req.ToAccountID, req.Currency); !valid {
+		return
+	}
+
+	st, err := h.store.CreateScheduledTransfer(ctx, db.CreateScheduledTransferParams{
+		Owner:         fromAccount.Owner,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Recurrence:    recurrence,
+		ScheduledFor:  req.StartAt,
+		EndAt:         nullTime(req.EndAt),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, newScheduledTransferResponse(st))
+}
+
+func (h scheduledTransferHandler) list(ctx *gin.Context) {
+	scheduledTransfers, err := h.store.ListScheduledTransfersByOwner(ctx, authPayload(ctx).Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	rsp := make([]scheduledTransferResponse, 0, len(scheduledTransfers))
+	for _, st := range scheduledTransfers {
+		rsp = append(rsp, newScheduledTransferResponse(st))
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+func (h scheduledTransferHandler) get(ctx *gin.Context) {
+	var uri getScheduledTransferRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	st, ok := h.ownedScheduledTransfer(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newScheduledTransferResponse(st))
+}
+
+//listRuns returns every attempt of the scheduled transfer, oldest first
+func (h scheduledTransferHandler) listRuns(ctx *gin.Context) {
+	var uri getScheduledTransferRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := h.ownedScheduledTransfer(ctx, uri.ID); !ok {
+		return
+	}
+
+	runs, err := h.store.ListScheduledTransferRuns(ctx, uri.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, runs)
+}
+
+func (h scheduledTransferHandler) patch(ctx *gin.Context) {
+	var uri getScheduledTransferRequest
+	var req updateScheduledTransferRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	st, ok := h.ownedScheduledTransfer(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	if !req.EndAt.IsZero() && !req.EndAt.After(st.ScheduledFor) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("end_at must be after the next occurrence")))
+		return
+	}
+
+	h.update(ctx, st, db.UpdateScheduledTransferParams{
+		Amount: nullInt64(req.Amount),
+		EndAt:  nullTime(req.EndAt),
+		Status: sql.NullString{String: req.Status, Valid: req.Status != ""},
+		ID:     st.ID,
+	})
+}
+
+//delete cancels the scheduled transfer, its past runs are kept
+func (h scheduledTransferHandler) delete(ctx *gin.Context) {
+	var uri getScheduledTransferRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	st, ok := h.ownedScheduledTransfer(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	h.update(ctx, st, db.UpdateScheduledTransferParams{
+		Status: sql.NullString{String: db.ScheduledTransferStatusCancelled, Valid: true},
+		ID:     st.ID,
+	})
+}
+
+//update changes an active or paused scheduled transfer and writes it in the response
+func (h scheduledTransferHandler) update(ctx *gin.Context, st db.ScheduledTransfer, params db.UpdateScheduledTransferParams) {
+	if st.Status != db.ScheduledTransferStatusActive && st.Status != db.ScheduledTransferStatusPaused {
+		ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("%w: it is %s", errScheduledTransferFinished, st.Status)))
+		return
+	}
+
+	st, err := h.store.UpdateScheduledTransfer(ctx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newScheduledTransferResponse(st))
+}
+
+//ownedScheduledTransfer loads the scheduled transfer and checks it belongs to the authenticated user.
+//It writes the error response and returns false when the scheduled transfer can't be used.
+func (h scheduledTransferHandler) ownedScheduledTransfer(ctx *gin.Context, id int64) (db.ScheduledTransfer, bool) {
+	st, err := h.store.GetScheduledTransfer(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("scheduled transfer %d not found", id)))
+			return st, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return st, false
+	}
+
+	if st.Owner != authPayload(ctx).Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(errScheduledTransferNotOwned))
+		return st, false
+	}
+
+	return st, true
+}
@@ -0,0 +1,411 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	db "simplebank/db/sqlc"
+	mockdb "simplebank/db/sqlc/mock"
+	"simplebank/token"
+	"testing"
+	"time"
+)
+
+func Test_scheduledTransferHandler_post(t *testing.T) {
+	account := db.Account{
+		ID:        1,
+		Owner:     "Perotto",
+		Balance:   100,
+		Currency:  "USD",
+		CreatedAt: defaultCreatedAt,
+	}
+	landlord := db.Account{
+		ID:        2,
+		Owner:     "Emmanuel",
+		Currency:  "USD",
+		CreatedAt: defaultCreatedAt,
+	}
+	startAt := time.Date(2100, time.January, 31, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		requestBody   gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "When it schedules a monthly transfer",
+			requestBody: gin.H{
+				"from_account_id": account.ID,
+				"to_account_id":   landlord.ID,
+				"amount":          60,
+				"currency":        "USD",
+				"start_at":        startAt,
+				"recurrence":      "FREQ=MONTHLY",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), landlord.ID).Times(1).Return(landlord, nil)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), db.CreateScheduledTransferParams{
+					Owner:         account.Owner,
+					FromAccountID: account.ID,
+					ToAccountID:   landlord.ID,
+					Amount:        60,
+					Recurrence:    "FREQ=MONTHLY;BYMONTHDAY=31",
+					ScheduledFor:  startAt,
+				}).
+					Times(1).
+					Return(db.ScheduledTransfer{
+						ID:            5,
+						Owner:         account.Owner,
+						FromAccountID: account.ID,
+						ToAccountID:   landlord.ID,
+						Amount:        60,
+						Recurrence:    "FREQ=MONTHLY;BYMONTHDAY=31",
+						ScheduledFor:  startAt,
+						NextRunAt:     startAt,
+						Status:        db.ScheduledTransferStatusActive,
+					}, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody scheduledTransferResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+				assert.Equal(t, int64(5), responseBody.ID)
+				assert.Equal(t, db.ScheduledTransferStatusActive, responseBody.Status)
+				assert.Nil(t, responseBody.EndAt)
+				assert.NotContains(t, recorder.Body.String(), "claimed_until")
+			},
+		},
+		{
+			name: "When recurrence is invalid",
+			requestBody: gin.H{
+				"from_account_id": account.ID,
+				"to_account_id":   landlord.ID,
+				"amount":          60,
+				"currency":        "USD",
+				"start_at":        startAt,
+				"recurrence":      "FREQ=HOURLY",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "When start_at is in the past",
+			requestBody: gin.H{
+				"from_account_id": account.ID,
+				"to_account_id":   landlord.ID,
+				"amount":          60,
+				"currency":        "USD",
+				"start_at":        defaultCreatedAt,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "When end_at is before start_at",
+			requestBody: gin.H{
+				"from_account_id": account.ID,
+				"to_account_id":   landlord.ID,
+				"amount":          60,
+				"currency":        "USD",
+				"start_at":        startAt,
+				"recurrence":      "FREQ=WEEKLY",
+				"end_at":          startAt.Add(-time.Hour),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "When source account belongs to another user",
+			requestBody: gin.H{
+				"from_account_id": account.ID,
+				"to_account_id":   landlord.ID,
+				"amount":          60,
+				"currency":        "USD",
+				"start_at":        startAt,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, landlord.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "When authorization is not provided",
+			requestBody: gin.H{
+				"from_account_id": account.ID,
+				"to_account_id":   landlord.ID,
+				"amount":          60,
+				"currency":        "USD",
+				"start_at":        startAt,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			bodyBytes, err := json.Marshal(tt.requestBody)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/scheduled_transfers", bytes.NewReader(bodyBytes))
+			require.NoError(t, err)
+			tt.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
+
+func Test_scheduledTransferHandler_patch(t *testing.T) {
+	st := db.ScheduledTransfer{
+		ID:            5,
+		Owner:         "Perotto",
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        60,
+		Recurrence:    "FREQ=WEEKLY",
+		ScheduledFor:  time.Date(2100, time.January, 31, 9, 0, 0, 0, time.UTC),
+		Status:        db.ScheduledTransferStatusActive,
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		requestBody   gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "When it pauses the scheduled transfer",
+			method:      http.MethodPatch,
+			requestBody: gin.H{"status": db.ScheduledTransferStatusPaused},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, st.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				paused := st
+				paused.Status = db.ScheduledTransferStatusPaused
+
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), st.ID).Times(1).Return(st, nil)
+				store.EXPECT().UpdateScheduledTransfer(gomock.Any(), db.UpdateScheduledTransferParams{
+					Status: sql.NullString{String: db.ScheduledTransferStatusPaused, Valid: true},
+					ID:     st.ID,
+				}).
+					Times(1).
+					Return(paused, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody scheduledTransferResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, db.ScheduledTransferStatusPaused, responseBody.Status)
+			},
+		},
+		{
+			name:        "When status can't be set by the user",
+			method:      http.MethodPatch,
+			requestBody: gin.H{"status": db.ScheduledTransferStatusCompleted},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, st.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().UpdateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "When it cancels the scheduled transfer",
+			method: http.MethodDelete,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, st.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				cancelled := st
+				cancelled.Status = db.ScheduledTransferStatusCancelled
+
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), st.ID).Times(1).Return(st, nil)
+				store.EXPECT().UpdateScheduledTransfer(gomock.Any(), db.UpdateScheduledTransferParams{
+					Status: sql.NullString{String: db.ScheduledTransferStatusCancelled, Valid: true},
+					ID:     st.ID,
+				}).
+					Times(1).
+					Return(cancelled, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody scheduledTransferResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, db.ScheduledTransferStatusCancelled, responseBody.Status)
+			},
+		},
+		{
+			name:   "When scheduled transfer is already cancelled",
+			method: http.MethodDelete,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, st.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				cancelled := st
+				cancelled.Status = db.ScheduledTransferStatusCancelled
+
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), st.ID).Times(1).Return(cancelled, nil)
+				store.EXPECT().UpdateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "When scheduled transfer belongs to another user",
+			method: http.MethodDelete,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "Emmanuel", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), st.ID).Times(1).Return(st, nil)
+				store.EXPECT().UpdateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "When scheduled transfer doesn't exist",
+			method: http.MethodDelete,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, st.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), st.ID).Times(1).Return(db.ScheduledTransfer{}, sql.ErrNoRows)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			bodyBytes, err := json.Marshal(tt.requestBody)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/scheduled_transfers/%d", st.ID)
+			request, err := http.NewRequest(tt.method, url, bytes.NewReader(bodyBytes))
+			require.NoError(t, err)
+			tt.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
@@ -1,29 +1,159 @@
 package api
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+	"simplebank/cursor"
 	db "simplebank/db/sqlc"
+	"simplebank/fx"
+	"simplebank/token"
+	"simplebank/util"
+	"sync"
+	"time"
 )
 
+const (
+	foreignKeyViolation pq.ErrorCode = "23503"
+	uniqueViolation     pq.ErrorCode = "23505"
+)
+
+//transferLimitExceededCode tells clients a limit refused the request, retrying it won't help until the period resets
+const transferLimitExceededCode = "transfer_limit_exceeded"
+
+//registerValidations guards the validator engine shared by all servers of the process
+var registerValidations sync.Once
+
 //Server serves HTTP requests for our banking service.
 type Server struct {
-	store  db.Store
-	router *gin.Engine
+	config     util.Config
+	store      db.Store
+	tokenMaker token.Maker
+	cursors    cursor.Codec
+	rates      fx.RateProvider
+	router     *gin.Engine
 }
 
 //NewServer builds a Server struct
-func NewServer(store db.Store) *Server {
+func NewServer(config util.Config, store db.Store) (*Server, error) {
+	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	cursors, err := cursor.NewCodec(config.CursorSigningKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create cursor codec: %w", err)
+	}
+
+	rates, err := newRateProvider(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create rate provider: %w", err)
+	}
+
+	registerValidations.Do(func() {
+		if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+			err = v.RegisterValidation("currency", validCurrency)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot register validations: %w", err)
+	}
+
 	router := gin.Default()
-	accHandler := newAccountHandler(store)
+	accHandler := newAccountHandler(store, cursors)
+	transfHandler := newTransferHandler(store, cursors, rates, config)
+	usrHandler := newUserHandler(store, tokenMaker, config)
+	tokHandler := newTokenHandler(store, tokenMaker, config.AccessTokenDuration)
+	sessHandler := newSessionHandler(store)
+	stmtHandler := newStatementHandler(store)
+	hldHandler := newHoldHandler(store, config.HoldTTL)
+	schedHandler := newScheduledTransferHandler(store)
+	odHandler := newOverdraftHandler(store)
+	lmtHandler := newLimitHandler(store)
+	recHandler := newReconciliationHandler(store)
+	batchHandler := newTransferBatchHandler(store)
+	whHandler := newWebhookHandler(store)
+
+	router.POST("/users", usrHandler.post)
+	router.POST("/users/login", usrHandler.login)
+	router.POST("/tokens/renew_access", tokHandler.renewAccess)
+
+	authRoutes := router.Group("/").Use(authMiddleware(tokenMaker))
+
+	authRoutes.POST("/accounts", accHandler.post)
+	authRoutes.GET("/accounts/:id", accHandler.get)
+	authRoutes.GET("/accounts", accHandler.list)
+	authRoutes.GET("/accounts/:id/entries", accHandler.listEntries)
+	authRoutes.GET("/accounts/:id/statement", stmtHandler.get)
+	authRoutes.POST("/accounts/:id/close", accHandler.close)
+	authRoutes.GET("/accounts/:id/limits", lmtHandler.get)
+
+	authRoutes.POST("/transfers", transfHandler.post)
+	authRoutes.GET("/transfers", transfHandler.list)
+	authRoutes.GET("/transfers/:id", transfHandler.get)
+
+	authRoutes.POST("/holds", hldHandler.post)
+	authRoutes.GET("/holds/:id", hldHandler.get)
+	authRoutes.POST("/holds/:id/capture", hldHandler.capture)
+	authRoutes.POST("/holds/:id/release", hldHandler.release)
+
+	authRoutes.POST("/scheduled_transfers", schedHandler.post)
+	authRoutes.GET("/scheduled_transfers", schedHandler.list)
+	authRoutes.GET("/scheduled_transfers/:id", schedHandler.get)
+	authRoutes.GET("/scheduled_transfers/:id/runs", schedHandler.listRuns)
+	authRoutes.PATCH("/scheduled_transfers/:id", schedHandler.patch)
+	authRoutes.DELETE("/scheduled_transfers/:id", schedHandler.delete)
+
+	authRoutes.POST("/transfer-batches", batchHandler.post)
+	authRoutes.GET("/transfer-batches/:id", batchHandler.get)
 
-	router.POST("/accounts", accHandler.post)
-	router.GET("/accounts/:id", accHandler.get)
-	router.GET("/accounts", accHandler.list)
+	authRoutes.POST("/webhooks", whHandler.post)
+	authRoutes.GET("/webhooks", whHandler.list)
+	authRoutes.GET("/webhooks/:id", whHandler.get)
+	authRoutes.PATCH("/webhooks/:id", whHandler.patch)
+	authRoutes.DELETE("/webhooks/:id", whHandler.delete)
+	authRoutes.GET("/webhooks/:id/deliveries", whHandler.listDeliveries)
+	authRoutes.GET("/webhooks/:id/deliveries/:delivery_id", whHandler.getDelivery)
+
+	adminRoutes := router.Group("/").Use(authMiddleware(tokenMaker), adminMiddleware(store))
+
+	adminRoutes.POST("/sessions/:id/block", sessHandler.block)
+	adminRoutes.POST("/accounts/:id/freeze", accHandler.freeze)
+	adminRoutes.POST("/accounts/:id/unfreeze", accHandler.unfreeze)
+	adminRoutes.PUT("/accounts/:id/overdraft_limit", odHandler.put)
+	adminRoutes.GET("/accounts/:id/overdraft_limit_changes", odHandler.listChanges)
+	adminRoutes.PUT("/accounts/:id/limits", lmtHandler.putAccount)
+	adminRoutes.PUT("/users/:username/limits/:currency", lmtHandler.putUser)
+	adminRoutes.POST("/transfers/:id/reverse", transfHandler.reverse)
+	adminRoutes.GET("/reconciliation_runs", recHandler.list)
+	adminRoutes.GET("/reconciliation_runs/:id", recHandler.get)
 
 	return &Server{
-		store:  store,
-		router: router,
+		config:     config,
+		store:      store,
+		tokenMaker: tokenMaker,
+		cursors:    cursors,
+		rates:      rates,
+		router:     router,
+	}, nil
+}
+
+//newRateProvider loads the exchange rates file, without one only same currency transfers are possible
+func newRateProvider(config util.Config) (fx.RateProvider, error) {
+	if err := fx.ValidateSpread(config.FXSpreadBps); err != nil {
+		return nil, err
+	}
+
+	if config.FXRatesFile == "" {
+		return fx.NewStaticRateProvider(nil)
 	}
+
+	return fx.LoadStaticRateProvider(config.FXRatesFile)
 }
 
 //Start runs the HTTP server on specific address.
@@ -36,3 +166,30 @@ func errorResponse(err error) gin.H {
 		"error": err.Error(),
 	}
 }
+
+//codedErrorResponse is an errorResponse with a machine-readable code
+func codedErrorResponse(code string, err error) gin.H {
+	return gin.H{
+		"error": err.Error(),
+		"code":  code,
+	}
+}
+
+//pqErrorCode returns the Postgres error code of err, or an empty code when it is not a Postgres error
+func pqErrorCode(err error) pq.ErrorCode {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code
+	}
+	return ""
+}
+
+//nullInt64 converts an optional filter where zero means not provided
+func nullInt64(v int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: v, Valid: v != 0}
+}
+
+//nullTime converts an optional filter where the zero time means not provided
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	db "simplebank/db/sqlc"
+	"time"
+)
+
+//sessionHandler handles all HTTP requests in Sessions domain.
+type (
+	sessionHandler struct {
+		store db.Store
+	}
+	blockSessionRequest struct {
+		ID string `uri:"id" binding:"required,uuid"`
+	}
+	//sessionResponse is the public representation of db.Session, it never exposes the refresh token
+	sessionResponse struct {
+		ID        uuid.UUID `json:"id"`
+		Username  string    `json:"username"`
+		UserAgent string    `json:"user_agent"`
+		ClientIp  string    `json:"client_ip"`
+		IsBlocked bool      `json:"is_blocked"`
+		ExpiresAt time.Time `json:"expires_at"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+)
+
+//newSessionHandler builds sessionHandler struct
+func newSessionHandler(store db.Store) sessionHandler {
+	return sessionHandler{
+		store: store,
+	}
+}
+
+func newSessionResponse(session db.Session) sessionResponse {
+	return sessionResponse{
+		ID:        session.ID,
+		Username:  session.Username,
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		IsBlocked: session.IsBlocked,
+		ExpiresAt: session.ExpiresAt,
+		CreatedAt: session.CreatedAt,
+	}
+}
+
+//block marks the session as blocked so its refresh token can no longer renew access tokens
+func (h sessionHandler) block(ctx *gin.Context) {
+	var req blockSessionRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	session, err := h.store.BlockSession(ctx, uuid.MustParse(req.ID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("session not found")))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newSessionResponse(session))
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	db "simplebank/db/sqlc"
+	"simplebank/statement"
+	"time"
+)
+
+//statementHandler handles all HTTP requests in Statements domain.
+type (
+	statementHandler struct {
+		store db.Store
+	}
+	getStatementRequest struct {
+		From   time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00" binding:"required"`
+		To     time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00" binding:"required,gtfield=From"`
+		Format string    `form:"format" binding:"required,oneof=csv ofx camt053"`
+	}
+)
+
+//newStatementHandler builds statementHandler struct
+func newStatementHandler(store db.Store) statementHandler {
+	return statementHandler{
+		store: store,
+	}
+}
+
+func (h statementHandler) get(ctx *gin.Context) {
+	var uri getAccountRequest
+	var req getStatementRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	formatter, err := statement.NewFormatter(req.Format)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := ownedAccount(ctx, h.store, uri.ID)
+	if !ok {
+		return
+	}
+
+	//The opening balance is derived from the entries booked since From,
+	//so it doesn't move when transfers are committed while the statement is built
+	openingBalance, err := h.store.GetAccountBalanceAt(ctx, db.GetAccountBalanceAtParams{
+		At:        req.From,
+		AccountID: account.ID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	entries, err := h.store.ListStatementEntries(ctx, db.ListStatementEntriesParams{
+		AccountID: account.ID,
+		FromTime:  req.From,
+		ToTime:    req.To,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	stmt := statement.Statement{
+		AccountID:      account.ID,
+		Owner:          account.Owner,
+		Currency:       account.Currency,
+		From:           req.From,
+		To:             req.To,
+		GeneratedAt:    time.Now(),
+		OpeningBalance: openingBalance,
+		Lines:          make([]statement.Line, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		stmt.Lines = append(stmt.Lines, statement.Line{
+			EntryID:               entry.ID,
+			TransferID:            entry.TransferID.Int64,
+			CounterpartyAccountID: counterpartyAccountID(entry, account.ID),
+			Amount:                entry.Amount,
+			BookedAt:              entry.CreatedAt,
+		})
+	}
+
+	var body bytes.Buffer
+	if err := formatter.Write(&body, stmt); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	filename := fmt.Sprintf("statement-%d-%s-%s.%s", account.ID, req.From.UTC().Format("20060102"), req.To.UTC().Format("20060102"), formatter.FileExtension())
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Data(http.StatusOK, formatter.ContentType(), body.Bytes())
+}
+
+//counterpartyAccountID is the other account of the transfer that booked the entry, zero for entries without transfer
+func counterpartyAccountID(entry db.ListStatementEntriesRow, accountID int64) int64 {
+	if entry.TransferFromAccountID.Int64 == accountID {
+		return entry.TransferToAccountID.Int64
+	}
+	return entry.TransferFromAccountID.Int64
+}
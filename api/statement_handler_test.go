@@ -0,0 +1,195 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	db "simplebank/db/sqlc"
+	mockdb "simplebank/db/sqlc/mock"
+	"simplebank/token"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_statementHandler_get(t *testing.T) {
+	account := db.Account{
+		ID:        1,
+		Owner:     "perotto",
+		Balance:   120,
+		Currency:  "USD",
+		CreatedAt: defaultCreatedAt,
+	}
+	entries := []db.ListStatementEntriesRow{
+		{
+			ID:                    10,
+			Amount:                -30,
+			CreatedAt:             time.Date(2022, time.April, 3, 12, 0, 0, 0, time.UTC),
+			TransferID:            sql.NullInt64{Int64: 5, Valid: true},
+			TransferFromAccountID: sql.NullInt64{Int64: 1, Valid: true},
+			TransferToAccountID:   sql.NullInt64{Int64: 2, Valid: true},
+		},
+		{
+			ID:                    13,
+			Amount:                50,
+			CreatedAt:             time.Date(2022, time.April, 15, 8, 45, 0, 0, time.UTC),
+			TransferID:            sql.NullInt64{Int64: 7, Valid: true},
+			TransferFromAccountID: sql.NullInt64{Int64: 3, Valid: true},
+			TransferToAccountID:   sql.NullInt64{Int64: 1, Valid: true},
+		},
+	}
+	period := "from=2022-04-01T00:00:00Z&to=2022-05-01T00:00:00Z"
+
+	tests := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "When it exports the statement as csv",
+			query: period + "&format=csv",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountBalanceAt(gomock.Any(), gomock.Any()).Times(1).Return(int64(100), nil)
+				store.EXPECT().ListStatementEntries(gomock.Any(), gomock.Any()).Times(1).Return(entries, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, "text/csv", recorder.Header().Get("Content-Type"))
+				assert.Equal(t, `attachment; filename="statement-1-20220401-20220501.csv"`, recorder.Header().Get("Content-Disposition"))
+
+				lines := strings.Split(strings.TrimSpace(recorder.Body.String()), "\n")
+				assert.Len(t, lines, 5)
+				assert.Equal(t, "opening_balance,2022-04-01T00:00:00Z,,,,,100,USD", lines[1])
+				assert.Equal(t, "entry,2022-04-03T12:00:00Z,10,5,2,-30,70,USD", lines[2])
+				assert.Equal(t, "entry,2022-04-15T08:45:00Z,13,7,3,50,120,USD", lines[3])
+				assert.Equal(t, "closing_balance,2022-05-01T00:00:00Z,,,,,120,USD", lines[4])
+			},
+		},
+		{
+			name:  "When it exports the statement as camt053",
+			query: period + "&format=camt053",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountBalanceAt(gomock.Any(), gomock.Any()).Times(1).Return(int64(100), nil)
+				store.EXPECT().ListStatementEntries(gomock.Any(), gomock.Any()).Times(1).Return(entries, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, "application/xml", recorder.Header().Get("Content-Type"))
+				assert.Contains(t, recorder.Body.String(), "<Cd>CLBD</Cd>")
+			},
+		},
+		{
+			name:  "When format is not supported",
+			query: period + "&format=pdf",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "When the period is inverted",
+			query: "from=2022-05-01T00:00:00Z&to=2022-04-01T00:00:00Z&format=ofx",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "When account belongs to another user",
+			query: period + "&format=ofx",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "someoneelse", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().ListStatementEntries(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "When there is a generic error listing the entries",
+			query: period + "&format=ofx",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountBalanceAt(gomock.Any(), gomock.Any()).Times(1).Return(int64(100), nil)
+				store.EXPECT().ListStatementEntries(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, errors.New("run, it's all broken"))
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			url := fmt.Sprintf("/accounts/%d/statement?%s", account.ID, tt.query)
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+			tt.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+	"time"
+)
+
+//tokenHandler handles all HTTP requests in Tokens domain.
+type (
+	tokenHandler struct {
+		store               db.Store
+		tokenMaker          token.Maker
+		accessTokenDuration time.Duration
+	}
+	renewAccessTokenRequest struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+	renewAccessTokenResponse struct {
+		AccessToken          string    `json:"access_token"`
+		AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+	}
+)
+
+//newTokenHandler builds tokenHandler struct
+func newTokenHandler(store db.Store, tokenMaker token.Maker, accessTokenDuration time.Duration) tokenHandler {
+	return tokenHandler{
+		store:               store,
+		tokenMaker:          tokenMaker,
+		accessTokenDuration: accessTokenDuration,
+	}
+}
+
+func (h tokenHandler) renewAccess(ctx *gin.Context) {
+	var req renewAccessTokenRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	refreshPayload, err := h.tokenMaker.VerifyToken(req.RefreshToken, token.TokenTypeRefresh)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	session, err := h.store.GetSession(ctx, refreshPayload.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("session not found")))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	if err := validSession(session, refreshPayload, req.RefreshToken); err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	accessToken, accessPayload, err := h.tokenMaker.CreateToken(refreshPayload.Username, token.TokenTypeAccess, h.accessTokenDuration)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, renewAccessTokenResponse{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: accessPayload.ExpiredAt,
+	})
+}
+
+//validSession checks that the session may still be used to renew access tokens for the refresh token
+func validSession(session db.Session, refreshPayload *token.Payload, refreshToken string) error {
+	if session.IsBlocked {
+		return errors.New("blocked session")
+	}
+
+	if session.Username != refreshPayload.Username {
+		return errors.New("incorrect session user")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(session.RefreshTokenHash), []byte(token.Hash(refreshToken))) != 1 {
+		return errors.New("mismatched session token")
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		return errors.New("expired session")
+	}
+
+	return nil
+}
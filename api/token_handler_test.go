@@ -0,0 +1,204 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	db "simplebank/db/sqlc"
+	mockdb "simplebank/db/sqlc/mock"
+	"simplebank/token"
+	"testing"
+	"time"
+)
+
+func Test_tokenHandler_renewAccess(t *testing.T) {
+	config := newTestConfig()
+	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	require.NoError(t, err)
+
+	refreshToken, refreshPayload, err := tokenMaker.CreateToken("perotto", token.TokenTypeRefresh, time.Hour)
+	require.NoError(t, err)
+
+	expiredToken, _, err := tokenMaker.CreateToken("perotto", token.TokenTypeRefresh, -time.Minute)
+	require.NoError(t, err)
+
+	accessToken, _, err := tokenMaker.CreateToken("perotto", token.TokenTypeAccess, time.Hour)
+	require.NoError(t, err)
+
+	session := db.Session{
+		ID:               refreshPayload.ID,
+		Username:         refreshPayload.Username,
+		RefreshTokenHash: token.Hash(refreshToken),
+		ExpiresAt:        refreshPayload.ExpiredAt,
+	}
+
+	tests := []struct {
+		name          string
+		refreshToken  string
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:         "When it succeeds",
+			refreshToken: refreshToken,
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetSession(gomock.Any(), refreshPayload.ID).
+					Times(1).
+					Return(session, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody renewAccessTokenResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				payload, err := tokenMaker.VerifyToken(responseBody.AccessToken, token.TokenTypeAccess)
+				require.NoError(t, err)
+				assert.Equal(t, "perotto", payload.Username)
+				assert.WithinDuration(t, time.Now().Add(config.AccessTokenDuration), responseBody.AccessTokenExpiresAt, time.Second)
+			},
+		},
+		{
+			name:         "When refresh token has expired",
+			refreshToken: expiredToken,
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:         "When an access token is sent",
+			refreshToken: accessToken,
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:         "When session is not found",
+			refreshToken: refreshToken,
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetSession(gomock.Any(), refreshPayload.ID).
+					Times(1).
+					Return(db.Session{}, sql.ErrNoRows)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:         "When session is blocked",
+			refreshToken: refreshToken,
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				blocked := session
+				blocked.IsBlocked = true
+
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetSession(gomock.Any(), refreshPayload.ID).
+					Times(1).
+					Return(blocked, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody gin.H
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+				assert.Equal(t, "blocked session", responseBody["error"])
+			},
+		},
+		{
+			name:         "When session belongs to another user",
+			refreshToken: refreshToken,
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				other := session
+				other.Username = "emmanuel"
+
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetSession(gomock.Any(), refreshPayload.ID).
+					Times(1).
+					Return(other, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody gin.H
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+				assert.Equal(t, "incorrect session user", responseBody["error"])
+			},
+		},
+		{
+			name:         "When session token mismatches",
+			refreshToken: refreshToken,
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				other := session
+				other.RefreshTokenHash = token.Hash("another-token")
+
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetSession(gomock.Any(), refreshPayload.ID).
+					Times(1).
+					Return(other, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody gin.H
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+				assert.Equal(t, "mismatched session token", responseBody["error"])
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			url := "/tokens/renew_access"
+			server, err := NewServer(config, stubs.store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			bodyBytes, err := json.Marshal(gin.H{"refresh_token": tt.refreshToken})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(bodyBytes))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
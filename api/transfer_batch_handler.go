@@ -0,0 +1,187 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	db "simplebank/db/sqlc"
+	"time"
+)
+
+//errTransferBatchNotOwned is returned when the transfer batch doesn't belong to the authenticated user
+var errTransferBatchNotOwned = errors.New("transfer batch doesn't belong to the authenticated user")
+
+//transferBatchHandler handles all HTTP requests in Transfer Batches domain.
+type (
+	transferBatchHandler struct {
+		store db.Store
+	}
+	//createTransferBatchRequest pays every destination from one account, all_or_nothing books every transfer or
+	//none of them while best_effort books those that can be
+	createTransferBatchRequest struct {
+		FromAccountID int64                      `json:"from_account_id" binding:"required,min=1"`
+		Currency      string                     `json:"currency" binding:"required,currency"`
+		Mode          string                     `json:"mode" binding:"required,oneof=all_or_nothing best_effort"`
+		Transfers     []transferBatchItemRequest `json:"transfers" binding:"required,min=1,max=1000,dive"`
+	}
+	transferBatchItemRequest struct {
+		ToAccountID int64 `json:"to_account_id" binding:"required,min=1"`
+		Amount      int64 `json:"amount" binding:"required,gt=0"`
+	}
+	getTransferBatchRequest struct {
+		ID int64 `uri:"id" binding:"required,min=1"`
+	}
+	//transferBatchResponse is the public representation of db.TransferBatch with its transfers, it hides the worker lease
+	transferBatchResponse struct {
+		ID             int64                       `json:"id"`
+		Owner          string                      `json:"owner"`
+		FromAccountID  int64                       `json:"from_account_id"`
+		Mode           string                      `json:"mode"`
+		Status         string                      `json:"status"`
+		TotalAmount    int64                       `json:"total_amount"`
+		ItemsCount     int32                       `json:"items_count"`
+		SucceededCount int32                       `json:"succeeded_count"`
+		FailedCount    int32                       `json:"failed_count"`
+		CreatedAt      time.Time                   `json:"created_at"`
+		FinishedAt     *time.Time                  `json:"finished_at"`
+		Transfers      []transferBatchItemResponse `json:"transfers"`
+	}
+	//transferBatchItemResponse is the outcome of one transfer of the batch, TransferID is set once it is booked
+	transferBatchItemResponse struct {
+		Position    int32  `json:"position"`
+		ToAccountID int64  `json:"to_account_id"`
+		Amount      int64  `json:"amount"`
+		Status      string `json:"status"`
+		TransferID  *int64 `json:"transfer_id"`
+		Error       string `json:"error,omitempty"`
+	}
+)
+
+//newTransferBatchHandler builds transferBatchHandler struct
+func newTransferBatchHandler(store db.Store) transferBatchHandler {
+	return transferBatchHandler{
+		store: store,
+	}
+}
+
+func newTransferBatchResponse(batch db.TransferBatch, items []db.TransferBatchItem) transferBatchResponse {
+	rsp := transferBatchResponse{
+		ID:             batch.ID,
+		Owner:          batch.Owner,
+		FromAccountID:  batch.FromAccountID,
+		Mode:           batch.Mode,
+		Status:         batch.Status,
+		TotalAmount:    batch.TotalAmount,
+		ItemsCount:     batch.ItemsCount,
+		SucceededCount: batch.SucceededCount,
+		FailedCount:    batch.FailedCount,
+		CreatedAt:      batch.CreatedAt,
+		Transfers:      make([]transferBatchItemResponse, 0, len(items)),
+	}
+	if batch.FinishedAt.Valid {
+		rsp.FinishedAt = &batch.FinishedAt.Time
+	}
+
+	for _, item := range items {
+		itemRsp := transferBatchItemResponse{
+			Position:    item.Position,
+			ToAccountID: item.ToAccountID,
+			Amount:      item.Amount,
+			Status:      item.Status,
+			Error:       item.Error,
+		}
+		if item.TransferID.Valid {
+			transferID := item.TransferID.Int64
+			itemRsp.TransferID = &transferID
+		}
+		rsp.Transfers = append(rsp.Transfers, itemRsp)
+	}
+	return rsp
+}
+
+//post submits the batch to be processed in the background, its status is polled with get.
+//Only the source account and the batch total are checked up front, a destination that can't be paid
+//fails its transfer when the batch is processed.
+func (h transferBatchHandler) post(ctx *gin.Context) {
+	var req createTransferBatchRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	items := make([]db.TransferBatchItemParams, 0, len(req.Transfers))
+	for i, transfer := range req.Transfers {
+		if transfer.ToAccountID == req.FromAccountID {
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("transfer %d: %w", i, db.ErrTransferBatchSameAccount)))
+			return
+		}
+		items = append(items, db.TransferBatchItemParams{
+			ToAccountID: transfer.ToAccountID,
+			Amount:      transfer.Amount,
+		})
+	}
+
+	fromAccount, valid := validAccount(ctx, h.store, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	if fromAccount.Owner != authPayload(ctx).Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(errAccountNotOwned))
+		return
+	}
+
+	result, err := h.store.SubmitTransferBatch(ctx, db.SubmitTransferBatchParams{
+		Owner:         fromAccount.Owner,
+		FromAccountID: fromAccount.ID,
+		Mode:          req.Mode,
+		Items:         items,
+	})
+	if err != nil {
+		if pqErrorCode(err) == foreignKeyViolation {
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("a destination account of the batch doesn't exist")))
+			return
+		}
+
+		transferErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, newTransferBatchResponse(result.Batch, result.Items))
+}
+
+func (h transferBatchHandler) get(ctx *gin.Context) {
+	var uri getTransferBatchRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	batch, err := h.store.GetTransferBatch(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("transfer batch %d not found", uri.ID)))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	if batch.Owner != authPayload(ctx).Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(errTransferBatchNotOwned))
+		return
+	}
+
+	items, err := h.store.ListTransferBatchItems(ctx, batch.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newTransferBatchResponse(batch, items))
+}
@@ -0,0 +1,469 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	db "simplebank/db/sqlc"
+	mockdb "simplebank/db/sqlc/mock"
+	"simplebank/token"
+	"testing"
+	"time"
+)
+
+func Test_transferBatchHandler_post(t *testing.T) {
+	payroll := db.Account{
+		ID:        1,
+		Owner:     "Perotto",
+		Balance:   1000,
+		Currency:  "USD",
+		CreatedAt: defaultCreatedAt,
+	}
+	transfers := []gin.H{
+		{"to_account_id": 2, "amount": 300},
+		{"to_account_id": 3, "amount": 200},
+	}
+
+	tests := []struct {
+		name          string
+		requestBody   gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "When it submits a batch",
+			requestBody: gin.H{
+				"from_account_id": payroll.ID,
+				"currency":        "USD",
+				"mode":            db.TransferBatchModeAllOrNothing,
+				"transfers":       transfers,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, payroll.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), payroll.ID).Times(1).Return(payroll, nil)
+				store.EXPECT().SubmitTransferBatch(gomock.Any(), db.SubmitTransferBatchParams{
+					Owner:         payroll.Owner,
+					FromAccountID: payroll.ID,
+					Mode:          db.TransferBatchModeAllOrNothing,
+					Items: []db.TransferBatchItemParams{
+						{ToAccountID: 2, Amount: 300},
+						{ToAccountID: 3, Amount: 200},
+					},
+				}).
+					Times(1).
+					Return(db.TransferBatchResult{
+						Batch: db.TransferBatch{
+							ID:            7,
+							Owner:         payroll.Owner,
+							FromAccountID: payroll.ID,
+							Mode:          db.TransferBatchModeAllOrNothing,
+							Status:        db.TransferBatchStatusPending,
+							TotalAmount:   500,
+							ItemsCount:    2,
+							ClaimedUntil:  sql.NullTime{Time: time.Now(), Valid: true},
+						},
+						Items: []db.TransferBatchItem{
+							{ID: 1, BatchID: 7, Position: 0, ToAccountID: 2, Amount: 300, Status: db.TransferBatchItemPending},
+							{ID: 2, BatchID: 7, Position: 1, ToAccountID: 3, Amount: 200, Status: db.TransferBatchItemPending},
+						},
+					}, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody transferBatchResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusAccepted, recorder.Code)
+				assert.Equal(t, int64(7), responseBody.ID)
+				assert.Equal(t, db.TransferBatchStatusPending, responseBody.Status)
+				assert.Equal(t, int64(500), responseBody.TotalAmount)
+				require.Len(t, responseBody.Transfers, 2)
+				assert.Equal(t, int64(3), responseBody.Transfers[1].ToAccountID)
+				assert.Nil(t, responseBody.Transfers[1].TransferID)
+				assert.Nil(t, responseBody.FinishedAt)
+				assert.NotContains(t, recorder.Body.String(), "claimed_until")
+			},
+		},
+		{
+			name: "When a transfer pays the source account",
+			requestBody: gin.H{
+				"from_account_id": payroll.ID,
+				"currency":        "USD",
+				"mode":            db.TransferBatchModeBestEffort,
+				"transfers":       []gin.H{{"to_account_id": payroll.ID, "amount": 300}},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, payroll.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().SubmitTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "When mode is unknown",
+			requestBody: gin.H{
+				"from_account_id": payroll.ID,
+				"currency":        "USD",
+				"mode":            "as_many_as_possible",
+				"transfers":       transfers,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, payroll.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().SubmitTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "When a transfer amount is not positive",
+			requestBody: gin.H{
+				"from_account_id": payroll.ID,
+				"currency":        "USD",
+				"mode":            db.TransferBatchModeBestEffort,
+				"transfers":       []gin.H{{"to_account_id": 2, "amount": -5}},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, payroll.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().SubmitTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "When the batch has no transfers",
+			requestBody: gin.H{
+				"from_account_id": payroll.ID,
+				"currency":        "USD",
+				"mode":            db.TransferBatchModeBestEffort,
+				"transfers":       []gin.H{},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, payroll.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().SubmitTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "When the source account belongs to someone else",
+			requestBody: gin.H{
+				"from_account_id": payroll.ID,
+				"currency":        "USD",
+				"mode":            db.TransferBatchModeAllOrNothing,
+				"transfers":       transfers,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "Emmanuel", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), payroll.ID).Times(1).Return(payroll, nil)
+				store.EXPECT().SubmitTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "When the source account holds another currency",
+			requestBody: gin.H{
+				"from_account_id": payroll.ID,
+				"currency":        "EUR",
+				"mode":            db.TransferBatchModeAllOrNothing,
+				"transfers":       transfers,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, payroll.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), payroll.ID).Times(1).Return(payroll, nil)
+				store.EXPECT().SubmitTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "When the total exceeds the available balance",
+			requestBody: gin.H{
+				"from_account_id": payroll.ID,
+				"currency":        "USD",
+				"mode":            db.TransferBatchModeBestEffort,
+				"transfers":       transfers,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, payroll.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), payroll.ID).Times(1).Return(payroll, nil)
+				store.EXPECT().SubmitTransferBatch(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferBatchResult{}, db.ErrInsufficientFunds)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "When a destination account doesn't exist",
+			requestBody: gin.H{
+				"from_account_id": payroll.ID,
+				"currency":        "USD",
+				"mode":            db.TransferBatchModeBestEffort,
+				"transfers":       transfers,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, payroll.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), payroll.ID).Times(1).Return(payroll, nil)
+				store.EXPECT().SubmitTransferBatch(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferBatchResult{}, &pq.Error{Code: foreignKeyViolation})
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "When there is no authorization",
+			requestBody: gin.H{
+				"from_account_id": payroll.ID,
+				"currency":        "USD",
+				"mode":            db.TransferBatchModeAllOrNothing,
+				"transfers":       transfers,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().SubmitTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			bodyBytes, err := json.Marshal(tt.requestBody)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfer-batches", bytes.NewReader(bodyBytes))
+			require.NoError(t, err)
+			tt.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
+
+func Test_transferBatchHandler_get(t *testing.T) {
+	batch := db.TransferBatch{
+		ID:             7,
+		Owner:          "Perotto",
+		FromAccountID:  1,
+		Mode:           db.TransferBatchModeBestEffort,
+		Status:         db.TransferBatchStatusPartiallyCompleted,
+		TotalAmount:    500,
+		ItemsCount:     2,
+		SucceededCount: 1,
+		FailedCount:    1,
+		FinishedAt:     sql.NullTime{Time: defaultCreatedAt, Valid: true},
+	}
+	items := []db.TransferBatchItem{
+		{
+			ID:          1,
+			BatchID:     batch.ID,
+			Position:    0,
+			ToAccountID: 2,
+			Amount:      300,
+			Status:      db.TransferBatchItemSucceeded,
+			TransferID:  sql.NullInt64{Int64: 40, Valid: true},
+		},
+		{
+			ID:          2,
+			BatchID:     batch.ID,
+			Position:    1,
+			ToAccountID: 3,
+			Amount:      200,
+			Status:      db.TransferBatchItemFailed,
+			Error:       db.ErrAccountFrozen.Error(),
+		},
+	}
+
+	tests := []struct {
+		name          string
+		batchID       int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "When it returns the batch with the outcome of each transfer",
+			batchID: batch.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, batch.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetTransferBatch(gomock.Any(), batch.ID).Times(1).Return(batch, nil)
+				store.EXPECT().ListTransferBatchItems(gomock.Any(), batch.ID).Times(1).Return(items, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody transferBatchResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, db.TransferBatchStatusPartiallyCompleted, responseBody.Status)
+				require.NotNil(t, responseBody.FinishedAt)
+				require.Len(t, responseBody.Transfers, 2)
+
+				require.NotNil(t, responseBody.Transfers[0].TransferID)
+				assert.Equal(t, int64(40), *responseBody.Transfers[0].TransferID)
+				assert.Empty(t, responseBody.Transfers[0].Error)
+
+				assert.Equal(t, db.TransferBatchItemFailed, responseBody.Transfers[1].Status)
+				assert.Nil(t, responseBody.Transfers[1].TransferID)
+				assert.Equal(t, db.ErrAccountFrozen.Error(), responseBody.Transfers[1].Error)
+			},
+		},
+		{
+			name:    "When the batch belongs to someone else",
+			batchID: batch.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "Emmanuel", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetTransferBatch(gomock.Any(), batch.ID).Times(1).Return(batch, nil)
+				store.EXPECT().ListTransferBatchItems(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:    "When the batch doesn't exist",
+			batchID: 99,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, batch.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetTransferBatch(gomock.Any(), int64(99)).Times(1).Return(db.TransferBatch{}, sql.ErrNoRows)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:    "When the store fails",
+			batchID: batch.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, batch.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetTransferBatch(gomock.Any(), batch.ID).Times(1).Return(db.TransferBatch{}, sql.ErrConnDone)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/transfer-batches/%d", tt.batchID), nil)
+			require.NoError(t, err)
+			tt.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
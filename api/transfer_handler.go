@@ -0,0 +1,492 @@
+package api
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"simplebank/currency"
+	"simplebank/cursor"
+	db "simplebank/db/sqlc"
+	"simplebank/fx"
+	"simplebank/util"
+	"time"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	idempotencyKeyMaxLength  = 255
+)
+
+//transferHandler handles all HTTP requests in Transfers domain.
+type (
+	transferHandler struct {
+		store             db.Store
+		cursors           cursor.Codec
+		rates             fx.RateProvider
+		spreadBps         int64
+		idempotencyKeyTTL time.Duration
+	}
+	//createTransferRequest debits Amount in Currency, ToCurrency asks for a cross-currency transfer
+	createTransferRequest struct {
+		FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+		ToAccountID   int64  `json:"to_account_id" binding:"required,min=1,nefield=FromAccountID"`
+		Amount        int64  `json:"amount" binding:"required,gt=0"`
+		Currency      string `json:"currency" binding:"required,currency"`
+		ToCurrency    string `json:"to_currency,omitempty" binding:"omitempty,currency"`
+	}
+	listTransfersRequest struct {
+		AccountID      int64     `form:"account_id" binding:"required_with=Direction CounterpartyID,omitempty,min=1"`
+		Direction      string    `form:"direction" binding:"omitempty,oneof=in out all"`
+		CounterpartyID int64     `form:"counterparty_id" binding:"omitempty,min=1,nefield=AccountID"`
+		FromAccountID  int64     `form:"from_account_id" binding:"omitempty,min=1"`
+		ToAccountID    int64     `form:"to_account_id" binding:"omitempty,min=1"`
+		MinAmount      int64     `form:"min_amount" binding:"omitempty,min=1"`
+		MaxAmount      int64     `form:"max_amount" binding:"omitempty,min=1,gtefield=MinAmount"`
+		From           time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+		To             time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00" binding:"omitempty,gtfield=From"`
+		PageSize       int32     `form:"page_size" binding:"required,min=5,max=20"`
+		PageID         int32     `form:"page_id" binding:"omitempty,min=1,excluded_with=Cursor"`
+		Cursor         string    `form:"cursor"`
+	}
+	getTransferRequest struct {
+		ID int64 `uri:"id" binding:"required,min=1"`
+	}
+	//reverseTransferRequest returns Amount to the source account, omitted it reverses what is left
+	reverseTransferRequest struct {
+		Amount int64  `json:"amount" binding:"omitempty,gt=0"`
+		Reason string `json:"reason" binding:"required,max=500"`
+	}
+	//transferResponse is a transfer with how much of it was reversed, ReversalOf is set on compensating transfers
+	transferResponse struct {
+		db.Transfer
+		ReversalStatus string                `json:"reversal_status"`
+		ReversedAmount int64                 `json:"reversed_amount"`
+		ReversalOf     int64                 `json:"reversal_of,omitempty"`
+		Reversals      []db.TransferReversal `json:"reversals"`
+	}
+)
+
+//newTransferHandler builds transferHandler struct
+func newTransferHandler(store db.Store, cursors cursor.Codec, rates fx.RateProvider, config util.Config) transferHandler {
+	return transferHandler{
+		store:             store,
+		cursors:           cursors,
+		rates:             rates,
+		spreadBps:         config.FXSpreadBps,
+		idempotencyKeyTTL: config.IdempotencyKeyTTL,
+	}
+}
+
+func (h transferHandler) post(ctx *gin.Context) {
+	var req createTransferRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	idempotencyKey := ctx.GetHeader(idempotencyKeyHeader)
+	if len(idempotencyKey) > idempotencyKeyMaxLength {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("%s must have at most %d characters", idempotencyKeyHeader, idempotencyKeyMaxLength)))
+		return
+	}
+
+	fromAccount, valid := validAccount(ctx, h.store, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	if fromAccount.Owner != authPayload(ctx).Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(errAccountNotOwned))
+		return
+	}
+
+	toCurrency := req.Currency
+	if req.ToCurrency != "" {
+		toCurrency = req.ToCurrency
+	}
+
+	if _, valid := validAccount(ctx, h.store, req.ToAccountID, toCurrency); !valid {
+		return
+	}
+
+	params := db.TransferTxParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+	}
+
+	if toCurrency != req.Currency {
+		quote, err := h.quote(ctx, req.Currency, toCurrency, req.Amount)
+		if err != nil {
+			transferErrorResponse(ctx, err)
+			return
+		}
+
+		params.ToAmount = quote.ConvertedAmount
+		params.ExchangeRate = quote.Rate.Value
+		params.SpreadBps = int32(quote.SpreadBps)
+	}
+
+	if idempotencyKey == "" {
+		result, err := h.store.TransferTx(ctx, params)
+		if err != nil {
+			transferErrorResponse(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, result)
+		return
+	}
+
+	result, err := h.store.IdempotentTransferTx(ctx, db.IdempotentTransferTxParams{
+		TransferTxParams: params,
+		Username:         authPayload(ctx).Username,
+		Key:              idempotencyKey,
+		RequestHash:      requestHash(req),
+		TTL:              h.idempotencyKeyTTL,
+	})
+	if err != nil {
+		transferErrorResponse(ctx, err)
+		return
+	}
+
+	if result.Replayed {
+		ctx.Header(idempotentReplayedHeader, "true")
+	}
+	ctx.JSON(http.StatusCreated, result.TransferTxResult)
+}
+
+//list searches the transfers touching any account owned by the authenticated user.
+//Direction is relative to account_id: "out" when it is the source, "in" when it is the destination.
+func (h transferHandler) list(ctx *gin.Context) {
+	var req listTransfersRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	current, ok := decodeCursor(ctx, h.cursors, req.Cursor)
+	if !ok {
+		return
+	}
+
+	owner := authPayload(ctx).Username
+
+	if req.PageID != 0 {
+		transfers, err := h.searchPage(ctx, owner, req)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+			return
+		}
+
+		deprecateOffsetPaging(ctx)
+		ctx.JSON(http.StatusOK, transfers)
+		return
+	}
+
+	var transfers []db.Transfer
+	var err error
+	if current != nil && current.Backward {
+		transfers, err = h.searchBefore(ctx, owner, req, current)
+	} else {
+		transfers, err = h.searchAfter(ctx, owner, req, current)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	page, next, prev := cursor.Paginate(h.cursors, transfers, req.PageSize, current, transferCursor)
+	ctx.JSON(http.StatusOK, pageResponse{Data: page, NextCursor: next, PrevCursor: prev})
+}
+
+//searchAnchor returns the account a search is anchored on and whether its outgoing and incoming transfers match.
+//Searching by account equality lets the queries use the transfers account indexes, a zero account means the
+//search spans all the accounts of the owner.
+func searchAnchor(req listTransfersRequest) (accountID int64, outgoing bool, incoming bool) {
+	switch {
+	case req.AccountID != 0:
+		return req.AccountID, req.Direction != "in", req.Direction != "out"
+	case req.FromAccountID != 0:
+		return req.FromAccountID, true, false
+	case req.ToAccountID != 0:
+		return req.ToAccountID, false, true
+	default:
+		return 0, false, false
+	}
+}
+
+//searchPage searches a page of transfers by offset
+func (h transferHandler) searchPage(ctx *gin.Context, owner string, req listTransfersRequest) ([]db.Transfer, error) {
+	accountID, outgoing, incoming := searchAnchor(req)
+	if accountID == 0 {
+		return h.store.SearchTransfers(ctx, db.SearchTransfersParams{
+			Owner:     owner,
+			MinAmount: nullInt64(req.MinAmount),
+			MaxAmount: nullInt64(req.MaxAmount),
+			FromTime:  nullTime(req.From),
+			ToTime:    nullTime(req.To),
+			Limit:     req.PageSize,
+			Offset:    (req.PageID - 1) * req.PageSize,
+		})
+	}
+
+	return h.store.SearchAccountTransfers(ctx, db.SearchAccountTransfersParams{
+		Owner:          owner,
+		Outgoing:       outgoing,
+		AccountID:      accountID,
+		CounterpartyID: nullInt64(req.CounterpartyID),
+		Incoming:       incoming,
+		FromAccountID:  nullInt64(req.FromAccountID),
+		ToAccountID:    nullInt64(req.ToAccountID),
+		MinAmount:      nullInt64(req.MinAmount),
+		MaxAmount:      nullInt64(req.MaxAmount),
+		FromTime:       nullTime(req.From),
+		ToTime:         nullTime(req.To),
+		Limit:          req.PageSize,
+		Offset:         (req.PageID - 1) * req.PageSize,
+	})
+}
+
+//searchAfter searches the transfers following the cursor, one more than the page size to tell whether a next page exists
+func (h transferHandler) searchAfter(ctx *gin.Context, owner string, req listTransfersRequest, current *cursor.Cursor) ([]db.Transfer, error) {
+	cursorCreatedAt, cursorID := afterCursor(current)
+	accountID, outgoing, incoming := searchAnchor(req)
+	if accountID == 0 {
+		return h.store.SearchTransfersAfter(ctx, db.SearchTransfersAfterParams{
+			Owner:           owner,
+			MinAmount:       nullInt64(req.MinAmount),
+			MaxAmount:       nullInt64(req.MaxAmount),
+			FromTime:        nullTime(req.From),
+			ToTime:          nullTime(req.To),
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorID,
+			Limit:           req.PageSize + 1,
+		})
+	}
+
+	return h.store.SearchAccountTransfersAfter(ctx, db.SearchAccountTransfersAfterParams{
+		Owner:           owner,
+		Outgoing:        outgoing,
+		AccountID:       accountID,
+		CounterpartyID:  nullInt64(req.CounterpartyID),
+		Incoming:        incoming,
+		FromAccountID:   nullInt64(req.FromAccountID),
+		ToAccountID:     nullInt64(req.ToAccountID),
+		MinAmount:       nullInt64(req.MinAmount),
+		MaxAmount:       nullInt64(req.MaxAmount),
+		FromTime:        nullTime(req.From),
+		ToTime:          nullTime(req.To),
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           req.PageSize + 1,
+	})
+}
+
+//searchBefore searches the transfers preceding the cursor, one more than the page size to tell whether a previous page exists
+func (h transferHandler) searchBefore(ctx *gin.Context, owner string, req listTransfersRequest, current *cursor.Cursor) ([]db.Transfer, error) {
+	accountID, outgoing, incoming := searchAnchor(req)
+	if accountID == 0 {
+		return h.store.SearchTransfersBefore(ctx, db.SearchTransfersBeforeParams{
+			Owner:           owner,
+			MinAmount:       nullInt64(req.MinAmount),
+			MaxAmount:       nullInt64(req.MaxAmount),
+			FromTime:        nullTime(req.From),
+			ToTime:          nullTime(req.To),
+			CursorCreatedAt: current.CreatedAt,
+			CursorID:        current.ID,
+			Limit:           req.PageSize + 1,
+		})
+	}
+
+	return h.store.SearchAccountTransfersBefore(ctx, db.SearchAccountTransfersBeforeParams{
+		Owner:           owner,
+		Outgoing:        outgoing,
+		AccountID:       accountID,
+		CounterpartyID:  nullInt64(req.CounterpartyID),
+		Incoming:        incoming,
+		FromAccountID:   nullInt64(req.FromAccountID),
+		ToAccountID:     nullInt64(req.ToAccountID),
+		MinAmount:       nullInt64(req.MinAmount),
+		MaxAmount:       nullInt64(req.MaxAmount),
+		FromTime:        nullTime(req.From),
+		ToTime:          nullTime(req.To),
+		CursorCreatedAt: current.CreatedAt,
+		CursorID:        current.ID,
+		Limit:           req.PageSize + 1,
+	})
+}
+
+//get returns a transfer touching an account owned by the authenticated user, with its reversal status
+func (h transferHandler) get(ctx *gin.Context) {
+	var uri getTransferRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, err := h.store.GetTransfer(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("transfer not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	if !h.touchesOwnedAccount(ctx, transfer) {
+		return
+	}
+
+	status, err := h.store.GetTransferReversalStatus(ctx, transfer.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	reversals, err := h.store.ListTransferReversals(ctx, transfer.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transferResponse{
+		Transfer:       transfer,
+		ReversalStatus: db.TransferReversalStatus(transfer, status.ReversedAmount),
+		ReversedAmount: status.ReversedAmount,
+		ReversalOf:     status.ReversalOf,
+		Reversals:      reversals,
+	})
+}
+
+//reverse books a compensating transfer undoing all or part of a transfer, recording the admin and the reason
+func (h transferHandler) reverse(ctx *gin.Context) {
+	var uri getTransferRequest
+	var req reverseTransferRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := h.store.ReverseTransfer(ctx, db.ReverseTransferParams{
+		TransferID: uri.ID,
+		Amount:     req.Amount,
+		ReversedBy: authPayload(ctx).Username,
+		Reason:     req.Reason,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("transfer not found")))
+		case errors.Is(err, db.ErrTransferAlreadyReversed), errors.Is(err, db.ErrTransferIsReversal):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		case errors.Is(err, db.ErrReversalExceedsTransfer):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		default:
+			transferErrorResponse(ctx, err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, result)
+}
+
+//touchesOwnedAccount checks that the authenticated user owns the source or the destination of the transfer.
+//It writes the error response and returns false otherwise.
+func (h transferHandler) touchesOwnedAccount(ctx *gin.Context, transfer db.Transfer) bool {
+	username := authPayload(ctx).Username
+
+	for _, id := range []int64{transfer.FromAccountID, transfer.ToAccountID} {
+		account, err := h.store.GetAccount(ctx, id)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+			return false
+		}
+		if account.Owner == username {
+			return true
+		}
+	}
+
+	ctx.JSON(http.StatusForbidden, errorResponse(errors.New("transfer doesn't touch an account of the authenticated user")))
+	return false
+}
+
+//quote converts the amount debited in the source currency to the amount credited in the destination currency
+func (h transferHandler) quote(ctx *gin.Context, from string, to string, amount int64) (fx.Quote, error) {
+	rate, err := h.rates.Rate(ctx, from, to)
+	if err != nil {
+		return fx.Quote{}, err
+	}
+
+	return fx.NewQuote(currency.Default, rate, h.spreadBps, amount)
+}
+
+//transferCursor returns the keyset position of a transfer
+func transferCursor(transfer db.Transfer) cursor.Cursor {
+	return cursor.Cursor{CreatedAt: transfer.CreatedAt, ID: transfer.ID}
+}
+
+//transferErrorResponse writes the response matching an error returned by a transfer transaction
+func transferErrorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, db.ErrInsufficientFunds), errors.Is(err, db.ErrInvalidAmount):
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+	case errors.Is(err, db.ErrAccountFrozen), errors.Is(err, db.ErrAccountClosed):
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+	case errors.Is(err, db.ErrTransferLimitExceeded):
+		ctx.JSON(http.StatusUnprocessableEntity, codedErrorResponse(transferLimitExceededCode, err))
+	case errors.Is(err, fx.ErrRateNotFound), errors.Is(err, fx.ErrAmountTooSmall):
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+	case errors.Is(err, db.ErrIdempotencyKeyReused):
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+	default:
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+	}
+}
+
+//requestHash returns the hex encoded SHA-256 of the canonical JSON encoding of the request
+func requestHash(req createTransferRequest) string {
+	body, _ := json.Marshal(req)
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+//validAccount checks that the account exists and holds the given currency.
+//It writes the error response and returns false otherwise.
+func validAccount(ctx *gin.Context, store db.Store, accountID int64, currency string) (db.Account, bool) {
+	account, err := store.GetAccount(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("account %d not found", accountID)))
+			return account, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return account, false
+	}
+
+	if account.Currency != currency {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(
+			fmt.Errorf("account %d currency mismatch: %s vs %s", accountID, account.Currency, currency),
+		))
+		return account, false
+	}
+
+	return account, true
+}
@@ -0,0 +1,1130 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	db "simplebank/db/sqlc"
+	mockdb "simplebank/db/sqlc/mock"
+	"simplebank/token"
+	"simplebank/util"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_transferHandler_post(t *testing.T) {
+	fromAccount := db.Account{
+		ID:        1,
+		Owner:     "Perotto",
+		Balance:   100,
+		Currency:  "USD",
+		CreatedAt: defaultCreatedAt,
+	}
+	toAccount := db.Account{
+		ID:        2,
+		Owner:     "Emmanuel",
+		Balance:   50,
+		Currency:  "USD",
+		CreatedAt: defaultCreatedAt,
+	}
+	eurAccount := db.Account{
+		ID:        3,
+		Owner:     "Emmanuel",
+		Balance:   50,
+		Currency:  "EUR",
+		CreatedAt: defaultCreatedAt,
+	}
+
+	tests := []struct {
+		name           string
+		requestBody    gin.H
+		idempotencyKey string
+		setupAuth      func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs     func(ctrl *gomock.Controller) stub
+		runAssertions  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "When it succeeds",
+			requestBody: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          10,
+				"currency":        "USD",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccount.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAccount.ID).Times(1).Return(toAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), db.TransferTxParams{
+					FromAccountID: fromAccount.ID,
+					ToAccountID:   toAccount.ID,
+					Amount:        10,
+				}).
+					Times(1).
+					Return(db.TransferTxResult{
+						Transfer: db.Transfer{
+							ID:            1,
+							FromAccountID: fromAccount.ID,
+							ToAccountID:   toAccount.ID,
+							Amount:        10,
+							CreatedAt:     defaultCreatedAt,
+						},
+					}, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody db.TransferTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+				assert.Equal(t, int64(1), responseBody.Transfer.ID)
+				assert.Equal(t, int64(10), responseBody.Transfer.Amount)
+			},
+		},
+		{
+			name: "When it succeeds across currencies",
+			requestBody: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   eurAccount.ID,
+				"amount":          1000,
+				"currency":        "USD",
+				"to_currency":     "EUR",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccount.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), eurAccount.ID).Times(1).Return(eurAccount, nil)
+				//1000 * 0.92 minus the 50 basis points spread, rounded down
+				store.EXPECT().TransferTx(gomock.Any(), db.TransferTxParams{
+					FromAccountID: fromAccount.ID,
+					ToAccountID:   eurAccount.ID,
+					Amount:        1000,
+					ToAmount:      915,
+					ExchangeRate:  920_000,
+					SpreadBps:     50,
+				}).
+					Times(1).
+					Return(db.TransferTxResult{
+						Transfer: db.Transfer{
+							ID:            1,
+							FromAccountID: fromAccount.ID,
+							ToAccountID:   eurAccount.ID,
+							Amount:        1000,
+							ToAmount:      915,
+							ExchangeRate:  920_000,
+							SpreadBps:     50,
+							CreatedAt:     defaultCreatedAt,
+						},
+					}, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody db.TransferTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+				assert.Equal(t, int64(1000), responseBody.Transfer.Amount)
+				assert.Equal(t, int64(915), responseBody.Transfer.ToAmount)
+				assert.Equal(t, int64(920_000), responseBody.Transfer.ExchangeRate)
+			},
+		},
+		{
+			name: "When converted amount rounds down to zero",
+			requestBody: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   eurAccount.ID,
+				"amount":          1,
+				"currency":        "USD",
+				"to_currency":     "EUR",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccount.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), eurAccount.ID).Times(1).Return(eurAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "When destination account doesn't hold the destination currency",
+			requestBody: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          10,
+				"currency":        "USD",
+				"to_currency":     "EUR",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccount.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAccount.ID).Times(1).Return(toAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "When source and destination are the same account",
+			requestBody: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   fromAccount.ID,
+				"amount":          10,
+				"currency":        "USD",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccount.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "When amount is not positive",
+			requestBody: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          -10,
+				"currency":        "USD",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccount.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "When source account is not found",
+			requestBody: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          10,
+				"currency":        "USD",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccount.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().GetAccount(gomock.Any(), toAccount.ID).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody gin.H
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+				assert.Equal(t, "account 1 not found", responseBody["error"])
+			},
+		},
+		{
+			name: "When source account belongs to another user",
+			requestBody: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          10,
+				"currency":        "USD",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, toAccount.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAccount.ID).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "When authorization is not provided",
+			requestBody: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          10,
+				"currency":        "USD",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "When destination account currency mismatches",
+			requestBody: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          10,
+				"currency":        "USD",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccount.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				eurAccount := toAccount
+				eurAccount.Currency = "EUR"
+
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAccount.ID).Times(1).Return(eurAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody gin.H
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				assert.Equal(t, "account 2 currency mismatch: EUR vs USD", responseBody["error"])
+			},
+		},
+		{
+			name: "When source account has insufficient funds",
+			requestBody: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          1000,
+				"currency":        "USD",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccount.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAccount.ID).Times(1).Return(toAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody gin.H
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				assert.Equal(t, "insufficient funds", responseBody["error"])
+			},
+		},
+		{
+			name: "When a transfer limit is exceeded",
+			requestBody: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          10,
+				"currency":        "USD",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccount.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAccount.ID).Times(1).Return(toAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, fmt.Errorf("%w: account daily count limit is 3 transfers", db.ErrTransferLimitExceeded))
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody gin.H
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				assert.Equal(t, "transfer limit exceeded: account daily count limit is 3 transfers", responseBody["error"])
+				assert.Equal(t, transferLimitExceededCode, responseBody["code"])
+			},
+		},
+		{
+			name: "When there is a generic error performing the transfer",
+			requestBody: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          10,
+				"currency":        "USD",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccount.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAccount.ID).Times(1).Return(toAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, errors.New("run, it's all broken"))
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody gin.H
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+				assert.Equal(t, "unknown error", responseBody["error"])
+			},
+		},
+		{
+			name: "When an idempotency key is replayed",
+			requestBody: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          10,
+				"currency":        "USD",
+			},
+			idempotencyKey: "transfer-1",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccount.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAccount.ID).Times(1).Return(toAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().IdempotentTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, params db.IdempotentTransferTxParams) (db.IdempotentTransferTxResult, error) {
+						assert.Equal(t, fromAccount.Owner, params.Username)
+						assert.Equal(t, "transfer-1", params.Key)
+						assert.Len(t, params.RequestHash, 64)
+						assert.Equal(t, time.Hour, params.TTL)
+
+						return db.IdempotentTransferTxResult{
+							TransferTxResult: db.TransferTxResult{
+								Transfer: db.Transfer{
+									ID:            1,
+									FromAccountID: fromAccount.ID,
+									ToAccountID:   toAccount.ID,
+									Amount:        10,
+									CreatedAt:     defaultCreatedAt,
+								},
+							},
+							Replayed: true,
+						}, nil
+					})
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody db.TransferTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+				assert.Equal(t, "true", recorder.Header().Get(idempotentReplayedHeader))
+				assert.Equal(t, int64(1), responseBody.Transfer.ID)
+			},
+		},
+		{
+			name: "When an idempotency key is reused with a different request",
+			requestBody: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          10,
+				"currency":        "USD",
+			},
+			idempotencyKey: "transfer-1",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccount.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAccount.ID).Times(1).Return(toAccount, nil)
+				store.EXPECT().IdempotentTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotentTransferTxResult{}, db.ErrIdempotencyKeyReused)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody gin.H
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+				assert.Equal(t, db.ErrIdempotencyKeyReused.Error(), responseBody["error"])
+			},
+		},
+		{
+			name: "When the idempotency key is too long",
+			requestBody: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          10,
+				"currency":        "USD",
+			},
+			idempotencyKey: strings.Repeat("k", idempotencyKeyMaxLength+1),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccount.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().IdempotentTransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			url := "/transfers"
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			bodyBytes, err := json.Marshal(tt.requestBody)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(bodyBytes))
+			require.NoError(t, err)
+			tt.setupAuth(t, request, server.tokenMaker)
+			if tt.idempotencyKey != "" {
+				request.Header.Set(idempotencyKeyHeader, tt.idempotencyKey)
+			}
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
+
+func Test_transferHandler_list(t *testing.T) {
+	transfers := []db.Transfer{
+		{
+			ID:            1,
+			FromAccountID: 1,
+			ToAccountID:   2,
+			Amount:        10,
+			CreatedAt:     defaultCreatedAt,
+		},
+		{
+			ID:            2,
+			FromAccountID: 2,
+			ToAccountID:   1,
+			Amount:        30,
+			CreatedAt:     defaultCreatedAt,
+		},
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "When it lists the transfers of the authenticated user",
+			query: "page_id=2&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "perotto", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().SearchTransfers(gomock.Any(), db.SearchTransfersParams{
+					Owner:  "perotto",
+					Limit:  5,
+					Offset: 5,
+				}).
+					Times(1).
+					Return(transfers, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody []db.Transfer
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, transfers, responseBody)
+			},
+		},
+		{
+			name:  "When it lists the first page of transfers by cursor",
+			query: "page_size=5&account_id=1",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "perotto", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().SearchAccountTransfersAfter(gomock.Any(), db.SearchAccountTransfersAfterParams{
+					Owner:     "perotto",
+					Outgoing:  true,
+					AccountID: 1,
+					Incoming:  true,
+					Limit:     6,
+				}).
+					Times(1).
+					Return(transfers, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody struct {
+					Data       []db.Transfer `json:"data"`
+					NextCursor string        `json:"next_cursor"`
+					PrevCursor string        `json:"prev_cursor"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, transfers, responseBody.Data)
+				assert.Empty(t, responseBody.NextCursor)
+				assert.Empty(t, responseBody.PrevCursor)
+			},
+		},
+		{
+			name:  "When it filters by account, direction, counterparty and amount",
+			query: "page_id=1&page_size=5&account_id=1&direction=in&counterparty_id=2&min_amount=20&max_amount=50",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "perotto", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().SearchAccountTransfers(gomock.Any(), db.SearchAccountTransfersParams{
+					Owner:          "perotto",
+					Outgoing:       false,
+					AccountID:      1,
+					CounterpartyID: sql.NullInt64{Int64: 2, Valid: true},
+					Incoming:       true,
+					MinAmount:      sql.NullInt64{Int64: 20, Valid: true},
+					MaxAmount:      sql.NullInt64{Int64: 50, Valid: true},
+					Limit:          5,
+					Offset:         0,
+				}).
+					Times(1).
+					Return(transfers[1:], nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody []db.Transfer
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, transfers[1:], responseBody)
+			},
+		},
+		{
+			name:  "When it filters by source, destination and date",
+			query: "page_id=1&page_size=5&from_account_id=1&to_account_id=2&from=2022-04-01T00:00:00Z&to=2022-05-01T00:00:00Z",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "perotto", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().SearchAccountTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, params db.SearchAccountTransfersParams) ([]db.Transfer, error) {
+						assert.Equal(t, int64(1), params.AccountID)
+						assert.True(t, params.Outgoing)
+						assert.False(t, params.Incoming)
+						assert.Equal(t, sql.NullInt64{Int64: 1, Valid: true}, params.FromAccountID)
+						assert.Equal(t, sql.NullInt64{Int64: 2, Valid: true}, params.ToAccountID)
+						assert.True(t, params.FromTime.Valid)
+						assert.True(t, time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC).Equal(params.FromTime.Time))
+						assert.True(t, params.ToTime.Valid)
+						assert.True(t, time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC).Equal(params.ToTime.Time))
+
+						return transfers[:1], nil
+					})
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "When direction is given without an account",
+			query: "page_id=1&page_size=5&direction=in",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "perotto", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "When direction is not supported",
+			query: "page_id=1&page_size=5&account_id=1&direction=sideways",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "perotto", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "When amount range is inverted",
+			query: "page_id=1&page_size=5&min_amount=50&max_amount=20",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "perotto", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "When authorization is not provided",
+			query:     "page_id=1&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:  "When there is a generic error searching the transfers",
+			query: "page_id=1&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "perotto", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			url := "/transfers?" + tt.query
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+			tt.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
+
+func Test_transferHandler_get(t *testing.T) {
+	transfer := db.Transfer{
+		ID:            5,
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        100,
+		ToAmount:      100,
+		CreatedAt:     defaultCreatedAt,
+	}
+	from := db.Account{ID: 1, Owner: "perotto", Currency: "USD"}
+	to := db.Account{ID: 2, Owner: "gianni", Currency: "USD"}
+	reversals := []db.TransferReversal{
+		{ID: 1, TransferID: transfer.ID, ReversalTransferID: 9, Amount: 40, ReversedBy: "admin", Reason: "duplicate", CreatedAt: defaultCreatedAt},
+	}
+
+	tests := []struct {
+		name          string
+		username      string
+		transferID    int64
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "When the source owner gets a partially reversed transfer",
+			username:   from.Owner,
+			transferID: transfer.ID,
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetTransfer(gomock.Any(), transfer.ID).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), from.ID).Times(1).Return(from, nil)
+				store.EXPECT().GetTransferReversalStatus(gomock.Any(), transfer.ID).
+					Times(1).
+					Return(db.GetTransferReversalStatusRow{ReversedAmount: 40}, nil)
+				store.EXPECT().ListTransferReversals(gomock.Any(), transfer.ID).Times(1).Return(reversals, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody transferResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, transfer, responseBody.Transfer)
+				assert.Equal(t, db.TransferPartiallyReversed, responseBody.ReversalStatus)
+				assert.Equal(t, int64(40), responseBody.ReversedAmount)
+				assert.Equal(t, reversals, responseBody.Reversals)
+			},
+		},
+		{
+			name:       "When the destination owner gets a compensating transfer",
+			username:   to.Owner,
+			transferID: transfer.ID,
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetTransfer(gomock.Any(), transfer.ID).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), from.ID).Times(1).Return(from, nil)
+				store.EXPECT().GetAccount(gomock.Any(), to.ID).Times(1).Return(to, nil)
+				store.EXPECT().GetTransferReversalStatus(gomock.Any(), transfer.ID).
+					Times(1).
+					Return(db.GetTransferReversalStatusRow{ReversalOf: 3}, nil)
+				store.EXPECT().ListTransferReversals(gomock.Any(), transfer.ID).Times(1).Return([]db.TransferReversal{}, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody transferResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, db.TransferNotReversed, responseBody.ReversalStatus)
+				assert.Equal(t, int64(3), responseBody.ReversalOf)
+			},
+		},
+		{
+			name:       "When the user owns neither account",
+			username:   "stranger",
+			transferID: transfer.ID,
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetTransfer(gomock.Any(), transfer.ID).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), from.ID).Times(1).Return(from, nil)
+				store.EXPECT().GetAccount(gomock.Any(), to.ID).Times(1).Return(to, nil)
+				store.EXPECT().GetTransferReversalStatus(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:       "When the transfer doesn't exist",
+			username:   from.Owner,
+			transferID: 99,
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetTransfer(gomock.Any(), int64(99)).Times(1).Return(db.Transfer{}, sql.ErrNoRows)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/transfers/%d", tt.transferID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tt.username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
+
+func Test_transferHandler_reverse(t *testing.T) {
+	admin := db.User{Username: "admin", Role: util.AdminRole}
+	depositor := db.User{Username: "perotto", Role: util.DepositorRole}
+	var transferID int64 = 5
+
+	tests := []struct {
+		name          string
+		username      string
+		requestBody   gin.H
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "When an admin reverses part of a transfer",
+			username:    admin.Username,
+			requestBody: gin.H{"amount": 40, "reason": "charged twice"},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().ReverseTransfer(gomock.Any(), db.ReverseTransferParams{
+					TransferID: transferID,
+					Amount:     40,
+					ReversedBy: admin.Username,
+					Reason:     "charged twice",
+				}).
+					Times(1).
+					Return(db.ReverseTransferResult{
+						TransferTxResult: db.TransferTxResult{Transfer: db.Transfer{ID: 9, FromAccountID: 2, ToAccountID: 1, Amount: 40}},
+						Reversal:         db.TransferReversal{ID: 1, TransferID: transferID, ReversalTransferID: 9, Amount: 40, ReversedBy: admin.Username},
+						ReversedAmount:   40,
+						ReversalStatus:   db.TransferPartiallyReversed,
+					}, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody db.ReverseTransferResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+				assert.Equal(t, int64(9), responseBody.Transfer.ID)
+				assert.Equal(t, admin.Username, responseBody.Reversal.ReversedBy)
+				assert.Equal(t, db.TransferPartiallyReversed, responseBody.ReversalStatus)
+			},
+		},
+		{
+			name:        "When an admin reverses what is left",
+			username:    admin.Username,
+			requestBody: gin.H{"reason": "cancelled order"},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().ReverseTransfer(gomock.Any(), db.ReverseTransferParams{
+					TransferID: transferID,
+					ReversedBy: admin.Username,
+					Reason:     "cancelled order",
+				}).
+					Times(1).
+					Return(db.ReverseTransferResult{ReversalStatus: db.TransferReversed}, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:        "When the reversal exceeds the transfer",
+			username:    admin.Username,
+			requestBody: gin.H{"amount": 1000, "reason": "too much"},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().ReverseTransfer(gomock.Any(), gomock.Any()).Times(1).Return(db.ReverseTransferResult{}, db.ErrReversalExceedsTransfer)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:        "When the transfer is already reversed",
+			username:    admin.Username,
+			requestBody: gin.H{"reason": "again"},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().ReverseTransfer(gomock.Any(), gomock.Any()).Times(1).Return(db.ReverseTransferResult{}, db.ErrTransferAlreadyReversed)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:        "When the destination can't pay it back",
+			username:    admin.Username,
+			requestBody: gin.H{"reason": "fraud"},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().ReverseTransfer(gomock.Any(), gomock.Any()).Times(1).Return(db.ReverseTransferResult{}, db.ErrInsufficientFunds)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:        "When the transfer doesn't exist",
+			username:    admin.Username,
+			requestBody: gin.H{"reason": "fraud"},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().ReverseTransfer(gomock.Any(), gomock.Any()).Times(1).Return(db.ReverseTransferResult{}, sql.ErrNoRows)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:        "When reason is missing",
+			username:    admin.Username,
+			requestBody: gin.H{"amount": 10},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().ReverseTransfer(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "When caller is not an admin",
+			username:    depositor.Username,
+			requestBody: gin.H{"reason": "mine"},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), depositor.Username).Times(1).Return(depositor, nil)
+				store.EXPECT().ReverseTransfer(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			bodyBytes, err := json.Marshal(tt.requestBody)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/transfers/%d/reverse", transferID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(bodyBytes))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tt.username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
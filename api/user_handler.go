@@ -0,0 +1,163 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+	"simplebank/util"
+	"time"
+)
+
+//userHandler handles all HTTP requests in Users domain.
+type (
+	userHandler struct {
+		store                db.Store
+		tokenMaker           token.Maker
+		accessTokenDuration  time.Duration
+		refreshTokenDuration time.Duration
+	}
+	createUserRequest struct {
+		Username string `json:"username" binding:"required,alphanum"`
+		Password string `json:"password" binding:"required,min=6"`
+		FullName string `json:"full_name" binding:"required"`
+		Email    string `json:"email" binding:"required,email"`
+	}
+	loginUserRequest struct {
+		Username string `json:"username" binding:"required,alphanum"`
+		Password string `json:"password" binding:"required,min=6"`
+	}
+	//userResponse is the public representation of db.User, it never exposes the password
+	userResponse struct {
+		Username          string    `json:"username"`
+		FullName          string    `json:"full_name"`
+		Email             string    `json:"email"`
+		PasswordChangedAt time.Time `json:"password_changed_at"`
+		CreatedAt         time.Time `json:"created_at"`
+	}
+	loginUserResponse struct {
+		SessionID             uuid.UUID    `json:"session_id"`
+		AccessToken           string       `json:"access_token"`
+		AccessTokenExpiresAt  time.Time    `json:"access_token_expires_at"`
+		RefreshToken          string       `json:"refresh_token"`
+		RefreshTokenExpiresAt time.Time    `json:"refresh_token_expires_at"`
+		User                  userResponse `json:"user"`
+	}
+)
+
+//newUserHandler builds userHandler struct
+func newUserHandler(store db.Store, tokenMaker token.Maker, config util.Config) userHandler {
+	return userHandler{
+		store:                store,
+		tokenMaker:           tokenMaker,
+		accessTokenDuration:  config.AccessTokenDuration,
+		refreshTokenDuration: config.RefreshTokenDuration,
+	}
+}
+
+func newUserResponse(user db.User) userResponse {
+	return userResponse{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		PasswordChangedAt: user.PasswordChangedAt,
+		CreatedAt:         user.CreatedAt,
+	}
+}
+
+func (h userHandler) post(ctx *gin.Context) {
+	var req createUserRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hashedPassword, err := util.HashPassword(req.Password)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	user, err := h.store.CreateUser(ctx, db.CreateUserParams{
+		Username:       req.Username,
+		HashedPassword: hashedPassword,
+		FullName:       req.FullName,
+		Email:          req.Email,
+	})
+	if err != nil {
+		if pqErrorCode(err) == uniqueViolation {
+			ctx.JSON(http.StatusConflict, errorResponse(errors.New("username or email already taken")))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, newUserResponse(user))
+}
+
+func (h userHandler) login(ctx *gin.Context) {
+	var req loginUserRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := h.store.GetUser(ctx, req.Username)
+	if err != nil {
+		//An unknown username answers like a wrong password, so logins can't be used to find out who has an account
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errors.New("invalid credentials")))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	if err := util.CheckPassword(req.Password, user.HashedPassword); err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errors.New("invalid credentials")))
+		return
+	}
+
+	accessToken, accessPayload, err := h.tokenMaker.CreateToken(user.Username, token.TokenTypeAccess, h.accessTokenDuration)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	refreshToken, refreshPayload, err := h.tokenMaker.CreateToken(user.Username, token.TokenTypeRefresh, h.refreshTokenDuration)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	session, err := h.store.CreateSession(ctx, db.CreateSessionParams{
+		ID:               refreshPayload.ID,
+		Username:         user.Username,
+		RefreshTokenHash: token.Hash(refreshToken),
+		UserAgent:        ctx.Request.UserAgent(),
+		ClientIp:         ctx.ClientIP(),
+		IsBlocked:        false,
+		ExpiresAt:        refreshPayload.ExpiredAt,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, loginUserResponse{
+		SessionID:             session.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
+		User:                  newUserResponse(user),
+	})
+}
@@ -0,0 +1,325 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	db "simplebank/db/sqlc"
+	mockdb "simplebank/db/sqlc/mock"
+	"simplebank/token"
+	"simplebank/util"
+	"testing"
+	"time"
+)
+
+//createUserParamsMatcher matches db.CreateUserParams checking the plain password against the generated hash
+type createUserParamsMatcher struct {
+	params   db.CreateUserParams
+	password string
+}
+
+func (m createUserParamsMatcher) Matches(x interface{}) bool {
+	params, ok := x.(db.CreateUserParams)
+	if !ok {
+		return false
+	}
+
+	if err := util.CheckPassword(m.password, params.HashedPassword); err != nil {
+		return false
+	}
+
+	m.params.HashedPassword = params.HashedPassword
+	return m.params == params
+}
+
+func (m createUserParamsMatcher) String() string {
+	return fmt.Sprintf("matches params %v and password %v", m.params, m.password)
+}
+
+func Test_userHandler_post(t *testing.T) {
+	password := "secret123"
+	user := db.User{
+		Username:       "perotto",
+		HashedPassword: "hashed",
+		FullName:       "Emmanuel Perotto",
+		Email:          "perotto@email.com",
+		CreatedAt:      defaultCreatedAt,
+	}
+
+	tests := []struct {
+		name          string
+		requestBody   gin.H
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "When it succeeds",
+			requestBody: gin.H{
+				"username":  user.Username,
+				"password":  password,
+				"full_name": user.FullName,
+				"email":     user.Email,
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().CreateUser(gomock.Any(), createUserParamsMatcher{
+					params: db.CreateUserParams{
+						Username: user.Username,
+						FullName: user.FullName,
+						Email:    user.Email,
+					},
+					password: password,
+				}).
+					Times(1).
+					Return(user, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody gin.H
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				wantResponseBody := gin.H{
+					"username":            "perotto",
+					"full_name":           "Emmanuel Perotto",
+					"email":               "perotto@email.com",
+					"password_changed_at": "0001-01-01T00:00:00Z",
+					"created_at":          "2022-04-24T21:18:00Z",
+				}
+
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+				assert.Equal(t, wantResponseBody, responseBody)
+			},
+		},
+		{
+			name: "When email is invalid",
+			requestBody: gin.H{
+				"username":  user.Username,
+				"password":  password,
+				"full_name": user.FullName,
+				"email":     "invalid-email",
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "When password is too short",
+			requestBody: gin.H{
+				"username":  user.Username,
+				"password":  "123",
+				"full_name": user.FullName,
+				"email":     user.Email,
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "When username is already taken",
+			requestBody: gin.H{
+				"username":  user.Username,
+				"password":  password,
+				"full_name": user.FullName,
+				"email":     user.Email,
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, &pq.Error{Code: uniqueViolation})
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody gin.H
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+				assert.Equal(t, "username or email already taken", responseBody["error"])
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			url := "/users"
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			bodyBytes, err := json.Marshal(tt.requestBody)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(bodyBytes))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
+
+func Test_userHandler_login(t *testing.T) {
+	password := "secret123"
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+
+	user := db.User{
+		Username:       "perotto",
+		HashedPassword: hashedPassword,
+		FullName:       "Emmanuel Perotto",
+		Email:          "perotto@email.com",
+		CreatedAt:      defaultCreatedAt,
+	}
+	//refreshTokenHash is the hash the successful login stored in its session
+	var refreshTokenHash string
+
+	tests := []struct {
+		name          string
+		requestBody   gin.H
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "When it succeeds",
+			requestBody: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, params db.CreateSessionParams) (db.Session, error) {
+						refreshTokenHash = params.RefreshTokenHash
+						return db.Session{
+							ID:               params.ID,
+							Username:         params.Username,
+							RefreshTokenHash: params.RefreshTokenHash,
+							ExpiresAt:        params.ExpiresAt,
+						}, nil
+					})
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.NotContains(t, recorder.Body.String(), "password\":")
+				assert.NotContains(t, recorder.Body.String(), hashedPassword)
+
+				var responseBody loginUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+				assert.Equal(t, newUserResponse(user), responseBody.User)
+				assert.NotEmpty(t, responseBody.SessionID)
+				assert.NotEmpty(t, responseBody.AccessToken)
+				assert.WithinDuration(t, time.Now().Add(time.Minute), responseBody.AccessTokenExpiresAt, time.Second)
+				assert.NotEmpty(t, responseBody.RefreshToken)
+				assert.WithinDuration(t, time.Now().Add(time.Hour), responseBody.RefreshTokenExpiresAt, time.Second)
+				//Only the hash of the refresh token is stored
+				assert.Equal(t, token.Hash(responseBody.RefreshToken), refreshTokenHash)
+				assert.NotEqual(t, responseBody.RefreshToken, refreshTokenHash)
+			},
+		},
+		{
+			name: "When user is not found",
+			requestBody: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody gin.H
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+				assert.Equal(t, "invalid credentials", responseBody["error"])
+			},
+		},
+		{
+			name: "When password is wrong",
+			requestBody: gin.H{
+				"username": user.Username,
+				"password": "wrong-password",
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetUser(gomock.Any(), user.Username).
+					Times(1).
+					Return(user, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody gin.H
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+				assert.Equal(t, "invalid credentials", responseBody["error"])
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			url := "/users/login"
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			bodyBytes, err := json.Marshal(tt.requestBody)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(bodyBytes))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
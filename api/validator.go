@@ -0,0 +1,12 @@
+package api
+
+import (
+	"github.com/go-playground/validator/v10"
+	"simplebank/currency"
+)
+
+//validCurrency backs the "currency" binding tag, it accepts the enabled currencies of the registry
+var validCurrency validator.Func = func(fl validator.FieldLevel) bool {
+	code, ok := fl.Field().Interface().(string)
+	return ok && currency.Default.IsEnabled(code)
+}
@@ -0,0 +1,372 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net"
+	"net/http"
+	db "simplebank/db/sqlc"
+	"simplebank/webhook"
+	"time"
+)
+
+//errWebhookNotOwned is returned when the webhook subscription doesn't belong to the authenticated user
+var errWebhookNotOwned = errors.New("webhook subscription doesn't belong to the authenticated user")
+
+//webhookHandler handles all HTTP requests in Webhooks domain.
+type (
+	webhookHandler struct {
+		store    db.Store
+		resolver *net.Resolver
+	}
+	//createWebhookRequest subscribes URL to the events of the authenticated user, a secret is generated when none is sent
+	createWebhookRequest struct {
+		URL        string   `json:"url" binding:"required,url,startswith=https,max=2048"`
+		EventTypes []string `json:"event_types" binding:"required,min=1,unique,dive,oneof=AccountCreated TransferCompleted BalanceChanged"`
+		Secret     string   `json:"secret" binding:"omitempty,min=16,max=128"`
+	}
+	getWebhookRequest struct {
+		ID int64 `uri:"id" binding:"required,min=1"`
+	}
+	//updateWebhookRequest only changes the fields that are sent, reactivating a subscription resets its failures
+	updateWebhookRequest struct {
+		URL        string   `json:"url" binding:"omitempty,url,startswith=https,max=2048"`
+		EventTypes []string `json:"event_types" binding:"omitempty,min=1,unique,dive,oneof=AccountCreated TransferCompleted BalanceChanged"`
+		Status     string   `json:"status" binding:"omitempty,oneof=active disabled"`
+	}
+	listWebhookDeliveriesRequest struct {
+		Status   string `form:"status" binding:"omitempty,oneof=pending succeeded failed"`
+		PageID   int32  `form:"page_id" binding:"required,min=1"`
+		PageSize int32  `form:"page_size" binding:"required,min=5,max=20"`
+	}
+	getWebhookDeliveryRequest struct {
+		ID         int64 `uri:"id" binding:"required,min=1"`
+		DeliveryID int64 `uri:"delivery_id" binding:"required,min=1"`
+	}
+	//webhookResponse is the public representation of db.WebhookSubscription, the secret is only shown on creation
+	webhookResponse struct {
+		ID                  int64      `json:"id"`
+		Owner               string     `json:"owner"`
+		URL                 string     `json:"url"`
+		EventTypes          []string   `json:"event_types"`
+		Secret              string     `json:"secret,omitempty"`
+		Status              string     `json:"status"`
+		ConsecutiveFailures int32      `json:"consecutive_failures"`
+		CreatedAt           time.Time  `json:"created_at"`
+		DisabledAt          *time.Time `json:"disabled_at"`
+	}
+	//webhookDeliveryResponse is the public representation of db.WebhookDelivery, it hides the worker lease
+	webhookDeliveryResponse struct {
+		ID                 int64           `json:"id"`
+		SubscriptionID     int64           `json:"subscription_id"`
+		EventID            int64           `json:"event_id"`
+		EventType          string          `json:"event_type"`
+		Payload            json.RawMessage `json:"payload"`
+		Status             string          `json:"status"`
+		Attempts           int32           `json:"attempts"`
+		NextAttemptAt      time.Time       `json:"next_attempt_at"`
+		LastResponseStatus *int32          `json:"last_response_status"`
+		LastError          string          `json:"last_error"`
+		CreatedAt          time.Time       `json:"created_at"`
+		DeliveredAt        *time.Time      `json:"delivered_at"`
+	}
+	//webhookDeliveryDetailResponse is a delivery with every attempt made, oldest first
+	webhookDeliveryDetailResponse struct {
+		webhookDeliveryResponse
+		AttemptLog []webhookDeliveryAttemptResponse `json:"attempt_log"`
+	}
+	//webhookDeliveryAttemptResponse is one attempt of a delivery, ResponseStatus is null when no response was received
+	webhookDeliveryAttemptResponse struct {
+		Attempt        int32     `json:"attempt"`
+		ResponseStatus *int32    `json:"response_status"`
+		Error          string    `json:"error"`
+		DurationMs     int64     `json:"duration_ms"`
+		CreatedAt      time.Time `json:"created_at"`
+	}
+)
+
+//newWebhookHandler builds webhookHandler struct
+func newWebhookHandler(store db.Store) webhookHandler {
+	return webhookHandler{
+		store:    store,
+		resolver: net.DefaultResolver,
+	}
+}
+
+func newWebhookResponse(subscription db.WebhookSubscription) webhookResponse {
+	rsp := webhookResponse{
+		ID:                  subscription.ID,
+		Owner:               subscription.Owner,
+		URL:                 subscription.Url,
+		EventTypes:          subscription.EventTypes,
+		Status:              subscription.Status,
+		ConsecutiveFailures: subscription.ConsecutiveFailures,
+		CreatedAt:           subscription.CreatedAt,
+	}
+	if subscription.DisabledAt.Valid {
+		rsp.DisabledAt = &subscription.DisabledAt.Time
+	}
+	return rsp
+}
+
+func newWebhookDeliveryResponse(delivery db.WebhookDelivery) webhookDeliveryResponse {
+	rsp := webhookDeliveryResponse{
+		ID:             delivery.ID,
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Payload:        delivery.Payload,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+	}
+	if delivery.LastResponseStatus.Valid {
+		rsp.LastResponseStatus = &delivery.LastResponseStatus.Int32
+	}
+	if delivery.DeliveredAt.Valid {
+		rsp.DeliveredAt = &delivery.DeliveredAt.Time
+	}
+	return rsp
+}
+
+func (h webhookHandler) post(ctx *gin.Context) {
+	var req createWebhookRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := webhook.ValidateURL(ctx, h.resolver, req.URL); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = webhook.NewSecret(); err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+			return
+		}
+	}
+
+	subscription, err := h.store.CreateWebhookSubscription(ctx, db.CreateWebhookSubscriptionParams{
+		Owner:      authPayload(ctx).Username,
+		Url:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     secret,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	rsp := newWebhookResponse(subscription)
+	rsp.Secret = subscription.Secret
+	ctx.JSON(http.StatusCreated, rsp)
+}
+
+func (h webhookHandler) list(ctx *gin.Context) {
+	subscriptions, err := h.store.ListWebhookSubscriptionsByOwner(ctx, authPayload(ctx).Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	rsp := make([]webhookResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		rsp = append(rsp, newWebhookResponse(subscription))
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+func (h webhookHandler) get(ctx *gin.Context) {
+	var uri getWebhookRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	subscription, ok := h.ownedWebhook(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newWebhookResponse(subscription))
+}
+
+func (h webhookHandler) patch(ctx *gin.Context) {
+	var uri getWebhookRequest
+	var req updateWebhookRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.URL != "" {
+		if err := webhook.ValidateURL(ctx, h.resolver, req.URL); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	subscription, ok := h.ownedWebhook(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.store.UpdateWebhookSubscription(ctx, db.UpdateWebhookSubscriptionParams{
+		Url:        sql.NullString{String: req.URL, Valid: req.URL != ""},
+		EventTypes: req.EventTypes,
+		Status:     sql.NullString{String: req.Status, Valid: req.Status != ""},
+		ID:         subscription.ID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newWebhookResponse(subscription))
+}
+
+//delete removes the subscription together with its delivery log
+func (h webhookHandler) delete(ctx *gin.Context) {
+	var uri getWebhookRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := h.ownedWebhook(ctx, uri.ID); !ok {
+		return
+	}
+
+	if err := h.store.DeleteWebhookSubscription(ctx, uri.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+//listDeliveries returns the deliveries of the subscription, newest first
+func (h webhookHandler) listDeliveries(ctx *gin.Context) {
+	var uri getWebhookRequest
+	var req listWebhookDeliveriesRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := h.ownedWebhook(ctx, uri.ID); !ok {
+		return
+	}
+
+	deliveries, err := h.store.ListWebhookDeliveries(ctx, db.ListWebhookDeliveriesParams{
+		SubscriptionID: uri.ID,
+		Status:         sql.NullString{String: req.Status, Valid: req.Status != ""},
+		Limit:          req.PageSize,
+		Offset:         (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	rsp := make([]webhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		rsp = append(rsp, newWebhookDeliveryResponse(delivery))
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+//getDelivery returns a delivery of the subscription with its attempts
+func (h webhookHandler) getDelivery(ctx *gin.Context) {
+	var uri getWebhookDeliveryRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := h.ownedWebhook(ctx, uri.ID); !ok {
+		return
+	}
+
+	delivery, err := h.store.GetWebhookDelivery(ctx, uri.DeliveryID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+	if err != nil || delivery.SubscriptionID != uri.ID {
+		ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("webhook delivery %d not found", uri.DeliveryID)))
+		return
+	}
+
+	attempts, err := h.store.ListWebhookDeliveryAttempts(ctx, delivery.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return
+	}
+
+	rsp := webhookDeliveryDetailResponse{
+		webhookDeliveryResponse: newWebhookDeliveryResponse(delivery),
+		AttemptLog:              make([]webhookDeliveryAttemptResponse, 0, len(attempts)),
+	}
+	for _, attempt := range attempts {
+		attemptRsp := webhookDeliveryAttemptResponse{
+			Attempt:    attempt.Attempt,
+			Error:      attempt.Error,
+			DurationMs: attempt.DurationMs,
+			CreatedAt:  attempt.CreatedAt,
+		}
+		if attempt.ResponseStatus.Valid {
+			responseStatus := attempt.ResponseStatus.Int32
+			attemptRsp.ResponseStatus = &responseStatus
+		}
+		rsp.AttemptLog = append(rsp.AttemptLog, attemptRsp)
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+//ownedWebhook loads the subscription and checks it belongs to the authenticated user.
+//It writes the error response and returns false when the subscription can't be used.
+func (h webhookHandler) ownedWebhook(ctx *gin.Context, id int64) (db.WebhookSubscription, bool) {
+	subscription, err := h.store.GetWebhookSubscription(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("webhook subscription %d not found", id)))
+			return subscription, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("unknown error")))
+		return subscription, false
+	}
+
+	if subscription.Owner != authPayload(ctx).Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(errWebhookNotOwned))
+		return subscription, false
+	}
+
+	return subscription, true
+}
@@ -0,0 +1,603 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	db "simplebank/db/sqlc"
+	mockdb "simplebank/db/sqlc/mock"
+	"simplebank/token"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_webhookHandler_post(t *testing.T) {
+	subscription := db.WebhookSubscription{
+		ID:         3,
+		Owner:      "Perotto",
+		Url:        "https://203.0.113.10/simplebank",
+		EventTypes: []string{db.EventTransferCompleted},
+		Secret:     "0123456789abcdef0123",
+		Status:     db.WebhookSubscriptionStatusActive,
+		CreatedAt:  defaultCreatedAt,
+	}
+
+	tests := []struct {
+		name          string
+		requestBody   gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "When it subscribes with a given secret",
+			requestBody: gin.H{
+				"url":         subscription.Url,
+				"event_types": subscription.EventTypes,
+				"secret":      subscription.Secret,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, subscription.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), db.CreateWebhookSubscriptionParams{
+					Owner:      subscription.Owner,
+					Url:        subscription.Url,
+					EventTypes: subscription.EventTypes,
+					Secret:     subscription.Secret,
+				}).
+					Times(1).
+					Return(subscription, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody webhookResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+				assert.Equal(t, subscription.ID, responseBody.ID)
+				assert.Equal(t, subscription.Url, responseBody.URL)
+				assert.Equal(t, subscription.Secret, responseBody.Secret)
+				assert.Nil(t, responseBody.DisabledAt)
+			},
+		},
+		{
+			name: "When it generates the secret",
+			requestBody: gin.H{
+				"url":         subscription.Url,
+				"event_types": subscription.EventTypes,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, subscription.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, params db.CreateWebhookSubscriptionParams) (db.WebhookSubscription, error) {
+						assert.True(t, strings.HasPrefix(params.Secret, "whsec_"))
+						created := subscription
+						created.Secret = params.Secret
+						return created, nil
+					})
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody webhookResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+				assert.True(t, strings.HasPrefix(responseBody.Secret, "whsec_"))
+			},
+		},
+		{
+			name: "When the URL is not HTTP",
+			requestBody: gin.H{
+				"url":         "ftp://hooks.example.com/simplebank",
+				"event_types": subscription.EventTypes,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, subscription.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "When the URL is not HTTPS",
+			requestBody: gin.H{
+				"url":         "http://203.0.113.10/simplebank",
+				"event_types": subscription.EventTypes,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, subscription.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "When the URL is on a private address",
+			requestBody: gin.H{
+				"url":         "https://10.0.0.5/simplebank",
+				"event_types": subscription.EventTypes,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, subscription.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "When the URL is on a loopback address",
+			requestBody: gin.H{
+				"url":         "https://127.0.0.1:8080/simplebank",
+				"event_types": subscription.EventTypes,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, subscription.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "When the URL is on a link-local address",
+			requestBody: gin.H{
+				"url":         "https://169.254.169.254/latest/meta-data",
+				"event_types": subscription.EventTypes,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, subscription.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "When an event type is unknown",
+			requestBody: gin.H{
+				"url":         subscription.Url,
+				"event_types": []string{"AccountDeleted"},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, subscription.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "When the secret is too short",
+			requestBody: gin.H{
+				"url":         subscription.Url,
+				"event_types": subscription.EventTypes,
+				"secret":      "short",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, subscription.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "When there is no authorization",
+			requestBody: gin.H{
+				"url":         subscription.Url,
+				"event_types": subscription.EventTypes,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			bodyBytes, err := json.Marshal(tt.requestBody)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(bodyBytes))
+			require.NoError(t, err)
+			tt.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
+
+func Test_webhookHandler_patch(t *testing.T) {
+	disabled := db.WebhookSubscription{
+		ID:                  3,
+		Owner:               "Perotto",
+		Url:                 "https://203.0.113.10/simplebank",
+		EventTypes:          []string{db.EventTransferCompleted},
+		Secret:              "0123456789abcdef0123",
+		Status:              db.WebhookSubscriptionStatusDisabled,
+		ConsecutiveFailures: 20,
+		CreatedAt:           defaultCreatedAt,
+		DisabledAt:          sql.NullTime{Time: defaultCreatedAt, Valid: true},
+	}
+
+	tests := []struct {
+		name          string
+		requestBody   gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "When it reactivates a disabled subscription",
+			requestBody: gin.H{"status": db.WebhookSubscriptionStatusActive},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, disabled.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				active := disabled
+				active.Status = db.WebhookSubscriptionStatusActive
+				active.ConsecutiveFailures = 0
+				active.DisabledAt = sql.NullTime{}
+
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), disabled.ID).Times(1).Return(disabled, nil)
+				store.EXPECT().UpdateWebhookSubscription(gomock.Any(), db.UpdateWebhookSubscriptionParams{
+					Status: sql.NullString{String: db.WebhookSubscriptionStatusActive, Valid: true},
+					ID:     disabled.ID,
+				}).
+					Times(1).
+					Return(active, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody webhookResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, db.WebhookSubscriptionStatusActive, responseBody.Status)
+				assert.Zero(t, responseBody.ConsecutiveFailures)
+				assert.Nil(t, responseBody.DisabledAt)
+				assert.Empty(t, responseBody.Secret)
+			},
+		},
+		{
+			name: "When it changes the URL and the event types",
+			requestBody: gin.H{
+				"url":         "https://203.0.113.10/v2",
+				"event_types": []string{db.EventAccountCreated, db.EventBalanceChanged},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, disabled.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), disabled.ID).Times(1).Return(disabled, nil)
+				store.EXPECT().UpdateWebhookSubscription(gomock.Any(), db.UpdateWebhookSubscriptionParams{
+					Url:        sql.NullString{String: "https://203.0.113.10/v2", Valid: true},
+					EventTypes: []string{db.EventAccountCreated, db.EventBalanceChanged},
+					ID:         disabled.ID,
+				}).
+					Times(1).
+					Return(disabled, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:        "When the new URL is on a private address",
+			requestBody: gin.H{"url": "https://192.168.1.20/simplebank"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, disabled.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().UpdateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "When an event type is repeated",
+			requestBody: gin.H{"event_types": []string{db.EventAccountCreated, db.EventAccountCreated}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, disabled.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().UpdateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "When the subscription belongs to someone else",
+			requestBody: gin.H{"status": db.WebhookSubscriptionStatusActive},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "Emmanuel", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), disabled.ID).Times(1).Return(disabled, nil)
+				store.EXPECT().UpdateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:        "When the subscription doesn't exist",
+			requestBody: gin.H{"status": db.WebhookSubscriptionStatusActive},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, disabled.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), disabled.ID).Times(1).Return(db.WebhookSubscription{}, sql.ErrNoRows)
+				store.EXPECT().UpdateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			bodyBytes, err := json.Marshal(tt.requestBody)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/webhooks/%d", disabled.ID), bytes.NewReader(bodyBytes))
+			require.NoError(t, err)
+			tt.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
+
+func Test_webhookHandler_getDelivery(t *testing.T) {
+	subscription := db.WebhookSubscription{
+		ID:         3,
+		Owner:      "Perotto",
+		Url:        "https://203.0.113.10/simplebank",
+		EventTypes: []string{db.EventTransferCompleted},
+		Status:     db.WebhookSubscriptionStatusActive,
+	}
+	delivery := db.WebhookDelivery{
+		ID:                 11,
+		SubscriptionID:     subscription.ID,
+		EventID:            40,
+		EventType:          db.EventTransferCompleted,
+		Payload:            json.RawMessage(`{"id":40,"type":"TransferCompleted"}`),
+		Status:             db.WebhookDeliverySucceeded,
+		Attempts:           2,
+		LastResponseStatus: sql.NullInt32{Int32: http.StatusOK, Valid: true},
+		ClaimedUntil:       sql.NullTime{Time: defaultCreatedAt, Valid: true},
+		DeliveredAt:        sql.NullTime{Time: defaultCreatedAt, Valid: true},
+	}
+	attempts := []db.WebhookDeliveryAttempt{
+		{ID: 1, DeliveryID: delivery.ID, Attempt: 1, Error: "dial tcp: connection refused", DurationMs: 3},
+		{ID: 2, DeliveryID: delivery.ID, Attempt: 2, ResponseStatus: sql.NullInt32{Int32: http.StatusOK, Valid: true}, DurationMs: 41},
+	}
+
+	tests := []struct {
+		name          string
+		deliveryID    int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(ctrl *gomock.Controller) stub
+		runAssertions func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "When it returns the delivery with its attempts",
+			deliveryID: delivery.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, subscription.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), subscription.ID).Times(1).Return(subscription, nil)
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), delivery.ID).Times(1).Return(delivery, nil)
+				store.EXPECT().ListWebhookDeliveryAttempts(gomock.Any(), delivery.ID).Times(1).Return(attempts, nil)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				var responseBody webhookDeliveryDetailResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, delivery.ID, responseBody.ID)
+				assert.Equal(t, db.WebhookDeliverySucceeded, responseBody.Status)
+				require.NotNil(t, responseBody.DeliveredAt)
+				assert.NotContains(t, recorder.Body.String(), "claimed_until")
+
+				require.Len(t, responseBody.AttemptLog, 2)
+				assert.Nil(t, responseBody.AttemptLog[0].ResponseStatus)
+				assert.Equal(t, "dial tcp: connection refused", responseBody.AttemptLog[0].Error)
+				require.NotNil(t, responseBody.AttemptLog[1].ResponseStatus)
+				assert.Equal(t, int32(http.StatusOK), *responseBody.AttemptLog[1].ResponseStatus)
+			},
+		},
+		{
+			name:       "When the delivery belongs to another subscription",
+			deliveryID: delivery.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, subscription.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				other := delivery
+				other.SubscriptionID = 4
+
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), subscription.ID).Times(1).Return(subscription, nil)
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), delivery.ID).Times(1).Return(other, nil)
+				store.EXPECT().ListWebhookDeliveryAttempts(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:       "When the delivery doesn't exist",
+			deliveryID: 99,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, subscription.Owner, time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), subscription.ID).Times(1).Return(subscription, nil)
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), int64(99)).Times(1).Return(db.WebhookDelivery{}, sql.ErrNoRows)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:       "When the subscription belongs to someone else",
+			deliveryID: delivery.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "Emmanuel", time.Minute)
+			},
+			buildStubs: func(ctrl *gomock.Controller) stub {
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), subscription.ID).Times(1).Return(subscription, nil)
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Any()).Times(0)
+
+				return stub{store: store}
+			},
+			runAssertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			//Builds stubs
+			stubs := tt.buildStubs(ctrl)
+
+			//Start test server and send request
+			server := newTestServer(t, stubs.store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/webhooks/%d/deliveries/%d", subscription.ID, tt.deliveryID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+			tt.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			//Assertions
+			tt.runAssertions(t, recorder)
+		})
+	}
+}
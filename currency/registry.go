@@ -0,0 +1,165 @@
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//maxMinorUnits is the largest exponent used by ISO 4217
+const maxMinorUnits = 4
+
+var (
+	//ErrUnknownCurrency is returned when a code is not in the registry
+	ErrUnknownCurrency = errors.New("unknown currency")
+	//ErrInvalidCurrency is returned when a currency definition breaks ISO 4217 rules
+	ErrInvalidCurrency = errors.New("invalid currency")
+)
+
+type (
+	//Currency is an ISO 4217 currency. Amounts are stored as integers of its minor unit, e.g. cents for USD.
+	Currency struct {
+		Code        string `json:"code"`
+		NumericCode int32  `json:"numeric_code"`
+		MinorUnits  int32  `json:"minor_units"`
+		Enabled     bool   `json:"enabled"`
+	}
+	//Registry holds the known currencies, it is safe for concurrent use
+	Registry struct {
+		mu         sync.RWMutex
+		currencies map[string]Currency
+	}
+)
+
+//defaults mirrors the rows seeded by the currencies migration, it is used until the table is loaded
+var defaults = []Currency{
+	{Code: "BRL", NumericCode: 986, MinorUnits: 2, Enabled: false},
+	{Code: "CAD", NumericCode: 124, MinorUnits: 2, Enabled: false},
+	{Code: "EUR", NumericCode: 978, MinorUnits: 2, Enabled: true},
+	{Code: "USD", NumericCode: 840, MinorUnits: 2, Enabled: true},
+}
+
+//Default is the process-wide registry used by validators and response formatting
+var Default = MustNewRegistry(defaults)
+
+//NewRegistry creates a Registry holding the given currencies
+func NewRegistry(currencies []Currency) (*Registry, error) {
+	r := &Registry{}
+	if err := r.Load(currencies); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+//MustNewRegistry is like NewRegistry but panics when a currency is invalid
+func MustNewRegistry(currencies []Currency) *Registry {
+	r, err := NewRegistry(currencies)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+//Load replaces all the currencies of the registry
+func (r *Registry) Load(currencies []Currency) error {
+	loaded := make(map[string]Currency, len(currencies))
+	for _, c := range currencies {
+		if err := c.validate(); err != nil {
+			return err
+		}
+		loaded[c.Code] = c
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.currencies = loaded
+	return nil
+}
+
+//Get returns the currency registered under code
+func (r *Registry) Get(code string) (Currency, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.currencies[code]
+	if !ok {
+		return Currency{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+	}
+	return c, nil
+}
+
+//IsEnabled reports whether new accounts and transfers may use the currency
+func (r *Registry) IsEnabled(code string) bool {
+	c, err := r.Get(code)
+	return err == nil && c.Enabled
+}
+
+//EnabledCodes returns the sorted codes of the enabled currencies
+func (r *Registry) EnabledCodes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	codes := make([]string, 0, len(r.currencies))
+	for code, c := range r.currencies {
+		if c.Enabled {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+//FormatAmount formats an amount of minor units with the decimals of the currency, e.g. 1050 USD is "10.50".
+//Unknown currencies are formatted without decimals.
+func (r *Registry) FormatAmount(code string, amount int64) string {
+	c, err := r.Get(code)
+	if err != nil {
+		return strconv.FormatInt(amount, 10)
+	}
+	return c.FormatAmount(amount)
+}
+
+//FormatAmount formats an amount of minor units with the decimals of the currency
+func (c Currency) FormatAmount(amount int64) string {
+	digits := strconv.FormatUint(absolute(amount), 10)
+	if c.MinorUnits == 0 {
+		return sign(amount) + digits
+	}
+
+	minor := int(c.MinorUnits)
+	if len(digits) <= minor {
+		digits = strings.Repeat("0", minor-len(digits)+1) + digits
+	}
+	return sign(amount) + digits[:len(digits)-minor] + "." + digits[len(digits)-minor:]
+}
+
+func (c Currency) validate() error {
+	if len(c.Code) != 3 || strings.Trim(c.Code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return fmt.Errorf("%w: code %q must have 3 upper case letters", ErrInvalidCurrency, c.Code)
+	}
+	if c.NumericCode < 1 || c.NumericCode > 999 {
+		return fmt.Errorf("%w: %s numeric code %d must have 3 digits", ErrInvalidCurrency, c.Code, c.NumericCode)
+	}
+	if c.MinorUnits < 0 || c.MinorUnits > maxMinorUnits {
+		return fmt.Errorf("%w: %s minor units %d must be between 0 and %d", ErrInvalidCurrency, c.Code, c.MinorUnits, maxMinorUnits)
+	}
+	return nil
+}
+
+//absolute returns |v| as unsigned so the smallest int64 doesn't overflow
+func absolute(v int64) uint64 {
+	if v < 0 {
+		return uint64(-(v + 1)) + 1
+	}
+	return uint64(v)
+}
+
+func sign(v int64) string {
+	if v < 0 {
+		return "-"
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package currency
+
+import (
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func TestCurrency_FormatAmount(t *testing.T) {
+	usd := Currency{Code: "USD", NumericCode: 840, MinorUnits: 2}
+	jpy := Currency{Code: "JPY", NumericCode: 392, MinorUnits: 0}
+	bhd := Currency{Code: "BHD", NumericCode: 48, MinorUnits: 3}
+
+	tests := []struct {
+		name     string
+		currency Currency
+		amount   int64
+		want     string
+	}{
+		{name: "When amount has whole units", currency: usd, amount: 1050, want: "10.50"},
+		{name: "When amount is below one unit", currency: usd, amount: 5, want: "0.05"},
+		{name: "When amount is zero", currency: usd, amount: 0, want: "0.00"},
+		{name: "When amount is negative", currency: usd, amount: -1050, want: "-10.50"},
+		{name: "When negative amount is below one unit", currency: usd, amount: -5, want: "-0.05"},
+		{name: "When currency has no minor unit", currency: jpy, amount: -1050, want: "-1050"},
+		{name: "When currency has three decimals", currency: bhd, amount: 1050, want: "1.050"},
+		{name: "When amount is the smallest int64", currency: usd, amount: math.MinInt64, want: "-92233720368547758.08"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, tt.currency.FormatAmount(tt.amount))
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	registry, err := NewRegistry([]Currency{
+		{Code: "USD", NumericCode: 840, MinorUnits: 2, Enabled: true},
+		{Code: "JPY", NumericCode: 392, MinorUnits: 0, Enabled: true},
+		{Code: "CAD", NumericCode: 124, MinorUnits: 2, Enabled: false},
+	})
+	require.NoError(t, err)
+
+	require.True(t, registry.IsEnabled("USD"))
+	require.False(t, registry.IsEnabled("CAD"))
+	require.False(t, registry.IsEnabled("EUR"))
+	require.Equal(t, []string{"JPY", "USD"}, registry.EnabledCodes())
+
+	_, err = registry.Get("EUR")
+	require.ErrorIs(t, err, ErrUnknownCurrency)
+
+	require.Equal(t, "1050", registry.FormatAmount("JPY", 1050))
+	require.Equal(t, "1050", registry.FormatAmount("EUR", 1050))
+}
+
+func TestNewRegistry(t *testing.T) {
+	tests := []struct {
+		name     string
+		currency Currency
+	}{
+		{name: "When code is not upper case", currency: Currency{Code: "usd", NumericCode: 840, MinorUnits: 2}},
+		{name: "When code has digits", currency: Currency{Code: "US1", NumericCode: 840, MinorUnits: 2}},
+		{name: "When numeric code has more than 3 digits", currency: Currency{Code: "USD", NumericCode: 1840, MinorUnits: 2}},
+		{name: "When minor units are negative", currency: Currency{Code: "USD", NumericCode: 840, MinorUnits: -1}},
+		{name: "When minor units are above ISO 4217 maximum", currency: Currency{Code: "USD", NumericCode: 840, MinorUnits: 5}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := NewRegistry([]Currency{tt.currency})
+			require.ErrorIs(t, err, ErrInvalidCurrency)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const minSigningKeySize = 32
+
+//ErrInvalidCursor is returned when a cursor is malformed or its signature doesn't match
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+//Cursor is a keyset position in a list ordered by (created_at, id).
+//Backward cursors point to the rows before the position instead of the rows after it.
+type Cursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        int64     `json:"id"`
+	Backward  bool      `json:"backward"`
+}
+
+//Codec encodes cursors into opaque strings signed with HMAC-SHA256 so clients can't forge positions
+type Codec struct {
+	signingKey []byte
+}
+
+//NewCodec creates a new Codec
+func NewCodec(signingKey string) (Codec, error) {
+	if len(signingKey) < minSigningKeySize {
+		return Codec{}, fmt.Errorf("invalid key size: must be at least %d characters", minSigningKeySize)
+	}
+	return Codec{signingKey: []byte(signingKey)}, nil
+}
+
+//Encode returns the opaque representation of the cursor
+func (c Codec) Encode(cursor Cursor) string {
+	body, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(body) + "." + base64.RawURLEncoding.EncodeToString(c.sign(body))
+}
+
+//Decode checks the signature of an opaque cursor and returns its position
+func (c Codec) Decode(s string) (Cursor, error) {
+	var cursor Cursor
+
+	encodedBody, encodedSignature, ok := strings.Cut(s, ".")
+	if !ok {
+		return cursor, ErrInvalidCursor
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(encodedBody)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, c.sign(body)) {
+		return cursor, ErrInvalidCursor
+	}
+
+	if err := json.Unmarshal(body, &cursor); err != nil {
+		return cursor, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+func (c Codec) sign(body []byte) []byte {
+	mac := hmac.New(sha256.New, c.signingKey)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
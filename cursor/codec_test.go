@@ -0,0 +1,166 @@
+package cursor
+
+import (
+	"github.com/stretchr/testify/require"
+	"simplebank/util"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCodec(t *testing.T) {
+	codec, err := NewCodec(util.RandomString(32))
+	require.NoError(t, err)
+
+	otherCodec, err := NewCodec(util.RandomString(32))
+	require.NoError(t, err)
+
+	cursor := Cursor{
+		CreatedAt: time.Date(2022, 4, 24, 21, 18, 0, 0, time.UTC),
+		ID:        42,
+		Backward:  true,
+	}
+	encoded := codec.Encode(cursor)
+	body, _, _ := strings.Cut(encoded, ".")
+
+	tests := []struct {
+		name    string
+		cursor  string
+		want    Cursor
+		wantErr error
+	}{
+		{
+			name:   "When cursor is valid",
+			cursor: encoded,
+			want:   cursor,
+		},
+		{
+			name:    "When cursor has no signature",
+			cursor:  body,
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "When cursor is signed with another key",
+			cursor:  otherCodec.Encode(cursor),
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "When cursor signature is tampered",
+			cursor:  codec.Encode(Cursor{ID: 1}) + "x",
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "When cursor is not base64",
+			cursor:  "not a cursor.at all",
+			wantErr: ErrInvalidCursor,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := codec.Decode(tt.cursor)
+			require.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				require.True(t, tt.want.CreatedAt.Equal(got.CreatedAt))
+				require.Equal(t, tt.want.ID, got.ID)
+				require.Equal(t, tt.want.Backward, got.Backward)
+			}
+		})
+	}
+}
+
+func TestNewCodec(t *testing.T) {
+	_, err := NewCodec(util.RandomString(31))
+	require.Error(t, err)
+}
+
+func TestPaginate(t *testing.T) {
+	codec, err := NewCodec(util.RandomString(32))
+	require.NoError(t, err)
+
+	keyOf := func(id int64) Cursor {
+		return Cursor{ID: id}
+	}
+
+	tests := []struct {
+		name     string
+		rows     []int64
+		current  *Cursor
+		wantPage []int64
+		wantNext *Cursor
+		wantPrev *Cursor
+	}{
+		{
+			name:     "When first page has more rows",
+			rows:     []int64{1, 2, 3},
+			wantPage: []int64{1, 2},
+			wantNext: &Cursor{ID: 2},
+		},
+		{
+			name:     "When first page is the only one",
+			rows:     []int64{1, 2},
+			wantPage: []int64{1, 2},
+		},
+		{
+			name:     "When forward page is in the middle",
+			rows:     []int64{3, 4, 5},
+			current:  &Cursor{ID: 2},
+			wantPage: []int64{3, 4},
+			wantNext: &Cursor{ID: 4},
+			wantPrev: &Cursor{ID: 3, Backward: true},
+		},
+		{
+			name:     "When forward page is the last one",
+			rows:     []int64{5},
+			current:  &Cursor{ID: 4},
+			wantPage: []int64{5},
+			wantPrev: &Cursor{ID: 5, Backward: true},
+		},
+		{
+			name:     "When backward page has more rows",
+			rows:     []int64{4, 3, 2},
+			current:  &Cursor{ID: 5, Backward: true},
+			wantPage: []int64{3, 4},
+			wantNext: &Cursor{ID: 4},
+			wantPrev: &Cursor{ID: 3, Backward: true},
+		},
+		{
+			name:     "When backward page reaches the start",
+			rows:     []int64{2, 1},
+			current:  &Cursor{ID: 3, Backward: true},
+			wantPage: []int64{1, 2},
+			wantNext: &Cursor{ID: 2},
+		},
+		{
+			name:     "When page is empty",
+			rows:     []int64{},
+			current:  &Cursor{ID: 3},
+			wantPage: []int64{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			page, next, prev := Paginate(codec, tt.rows, 2, tt.current, keyOf)
+			require.Equal(t, tt.wantPage, page)
+
+			for _, c := range []struct {
+				got  string
+				want *Cursor
+			}{{next, tt.wantNext}, {prev, tt.wantPrev}} {
+				if c.want == nil {
+					require.Empty(t, c.got)
+					continue
+				}
+
+				got, err := codec.Decode(c.got)
+				require.NoError(t, err)
+				require.Equal(t, *c.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package cursor
+
+//Paginate builds a page from rows fetched with one extra row beyond pageSize, which tells whether more rows exist.
+//Rows fetched for a backward cursor come in descending order and are returned in ascending order.
+//current is nil on the first page, and keyOf returns the position of a row.
+func Paginate[T any](codec Codec, rows []T, pageSize int32, current *Cursor, keyOf func(T) Cursor) (page []T, next string, prev string) {
+	hasMore := len(rows) > int(pageSize)
+	if hasMore {
+		rows = rows[:pageSize]
+	}
+
+	backward := current != nil && current.Backward
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	if len(rows) == 0 {
+		return rows, "", ""
+	}
+
+	if hasMore || backward {
+		last := keyOf(rows[len(rows)-1])
+		last.Backward = false
+		next = codec.Encode(last)
+	}
+
+	if (backward && hasMore) || (!backward && current != nil) {
+		first := keyOf(rows[0])
+		first.Backward = true
+		prev = codec.Encode(first)
+	}
+
+	return rows, next, prev
+}
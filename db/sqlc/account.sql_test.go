@@ -16,7 +16,6 @@ func TestQueries_CreateAccount(t *testing.T) {
 		{
 			name: "When successfully creates it",
 			params: CreateAccountParams{
-				Owner:    util.RandomOwner(),
 				Balance:  util.RandomMoney(),
 				Currency: util.RandomCurrency(),
 			},
@@ -26,7 +25,13 @@ func TestQueries_CreateAccount(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			account, err := testQueries.CreateAccount(context.Background(), tt.params)
+			ctx := context.Background()
+
+			user, err := randomUser(ctx)
+			require.NoError(t, err)
+			tt.params.Owner = user.Username
+
+			account, err := testQueries.CreateAccount(ctx, tt.params)
 			require.NoError(t, err)
 			require.NotEmpty(t, account)
 
@@ -95,7 +100,32 @@ func TestQueries_DeleteAccount(t *testing.T) {
 
 				require.NoError(t, err)
 
-				err = testQueries.DeleteAccount(context.Background(), account.ID)
+				deleted, err := testQueries.DeleteAccount(context.Background(), account.ID)
+				require.NoError(t, err)
+				require.Equal(t, int64(1), deleted)
+			},
+		},
+		{
+			name: "When account has history",
+			testingFunc: func(t *testing.T) {
+				account, err := randomAccount(context.Background())
+				require.NoError(t, err)
+
+				journal, err := randomJournal(context.Background())
+				require.NoError(t, err)
+
+				_, err = testQueries.CreateEntry(context.Background(), CreateEntryParams{
+					AccountID: account.ID,
+					Amount:    10,
+					JournalID: journal.ID,
+				})
+				require.NoError(t, err)
+
+				deleted, err := testQueries.DeleteAccount(context.Background(), account.ID)
+				require.NoError(t, err)
+				require.Zero(t, deleted)
+
+				_, err = testQueries.GetAccount(context.Background(), account.ID)
 				require.NoError(t, err)
 			},
 		},
@@ -103,8 +133,9 @@ func TestQueries_DeleteAccount(t *testing.T) {
 		{
 			name: "When accounts doesn't exist",
 			testingFunc: func(t *testing.T) {
-				err := testQueries.DeleteAccount(context.Background(), -10)
+				deleted, err := testQueries.DeleteAccount(context.Background(), -10)
 				require.NoError(t, err)
+				require.Zero(t, deleted)
 			},
 		},
 	}
@@ -201,10 +232,66 @@ func TestQueries_UpdateAccount(t *testing.T) {
 	}
 }
 
+func TestQueries_ListAccountsByOwnerKeyset(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	user, err := randomUser(ctx)
+	require.NoError(t, err)
+
+	accounts := make([]Account, 3)
+	for i := range accounts {
+		accounts[i], err = testQueries.CreateAccount(ctx, CreateAccountParams{
+			Owner:    user.Username,
+			Balance:  util.RandomMoney(),
+			Currency: util.RandomCurrency(),
+		})
+		require.NoError(t, err)
+	}
+
+	first, err := testQueries.ListAccountsByOwnerAfter(ctx, ListAccountsByOwnerAfterParams{
+		Owner: user.Username,
+		Limit: 2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, accounts[:2], first)
+
+	next, err := testQueries.ListAccountsByOwnerAfter(ctx, ListAccountsByOwnerAfterParams{
+		Owner:           user.Username,
+		CursorCreatedAt: sql.NullTime{Time: first[1].CreatedAt, Valid: true},
+		CursorID:        sql.NullInt64{Int64: first[1].ID, Valid: true},
+		Limit:           2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, accounts[2:], next)
+
+	prev, err := testQueries.ListAccountsByOwnerBefore(ctx, ListAccountsByOwnerBeforeParams{
+		Owner:           user.Username,
+		CursorCreatedAt: next[0].CreatedAt,
+		CursorID:        next[0].ID,
+		Limit:           2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []Account{accounts[1], accounts[0]}, prev)
+}
+
 func randomAccount(ctx context.Context) (Account, error) {
+	return randomAccountWithBalance(ctx, util.RandomMoney())
+}
+
+func randomAccountWithBalance(ctx context.Context, balance int64) (Account, error) {
+	return randomAccountInCurrency(ctx, balance, util.RandomCurrency())
+}
+
+func randomAccountInCurrency(ctx context.Context, balance int64, currency string) (Account, error) {
+	user, err := randomUser(ctx)
+	if err != nil {
+		return Account{}, err
+	}
+
 	return testQueries.CreateAccount(ctx, CreateAccountParams{
-		Owner:    util.RandomOwner(),
-		Balance:  util.RandomMoney(),
-		Currency: util.RandomCurrency(),
+		Owner:    user.Username,
+		Balance:  balance,
+		Currency: currency,
 	})
 }
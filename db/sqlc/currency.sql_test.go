@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestQueries_GetCurrency(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		want    Currency
+		wantErr error
+	}{
+		{
+			name: "When currency is enabled",
+			code: "USD",
+			want: Currency{Code: "USD", NumericCode: 840, MinorUnits: 2, Enabled: true},
+		},
+		{
+			name: "When currency is disabled",
+			code: "CAD",
+			want: Currency{Code: "CAD", NumericCode: 124, MinorUnits: 2, Enabled: false},
+		},
+		{
+			name:    "When currency doesn't exist",
+			code:    "XYZ",
+			wantErr: sql.ErrNoRows,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			currency, err := testQueries.GetCurrency(context.Background(), tt.code)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, currency)
+		})
+	}
+}
+
+func TestQueries_ListCurrencies(t *testing.T) {
+	currencies, err := testQueries.ListCurrencies(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, currencies)
+
+	for i := 1; i < len(currencies); i++ {
+		require.Less(t, currencies[i-1].Code, currencies[i].Code)
+	}
+}
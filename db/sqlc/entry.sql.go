@@ -5,33 +5,44 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
-INSERT INTO entries(account_id, amount)
-VALUES ($1, $2)
-RETURNING id, account_id, amount, created_at
+INSERT INTO entries(account_id, amount, transfer_id, journal_id)
+VALUES ($1, $2, $3, $4)
+RETURNING id, account_id, amount, created_at, transfer_id, journal_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID  int64         `json:"account_id"`
+	Amount     int64         `json:"amount"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	JournalID  int64         `json:"journal_id"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry,
+		arg.AccountID,
+		arg.Amount,
+		arg.TransferID,
+		arg.JournalID,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.JournalID,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at
+SELECT id, account_id, amount, created_at, transfer_id, journal_id
 FROM entries
 WHERE id = $1
 LIMIT 1
@@ -45,12 +56,14 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.JournalID,
 	)
 	return i, err
 }
 
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at
+SELECT id, account_id, amount, created_at, transfer_id, journal_id
 FROM entries
 ORDER BY id
 LIMIT $1 OFFSET $2
@@ -75,6 +88,276 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+			&i.JournalID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesByAccount = `-- name: ListEntriesByAccount :many
+SELECT id, account_id, amount, created_at, running_balance
+FROM (SELECT e.id,
+             e.account_id,
+             e.amount,
+             e.created_at,
+             (a.balance - SUM(e.amount) OVER (ORDER BY e.id DESC) + e.amount)::bigint AS running_balance
+      FROM entries e
+               JOIN accounts a ON a.id = e.account_id
+      WHERE e.account_id = $1) AS account_entries
+WHERE ($2::timestamp IS NULL OR created_at >= $2)
+  AND ($3::timestamp IS NULL OR created_at < $3)
+ORDER BY id
+LIMIT $4 OFFSET $5
+`
+
+type ListEntriesByAccountParams struct {
+	AccountID int64        `json:"account_id"`
+	FromTime  sql.NullTime `json:"from_time"`
+	ToTime    sql.NullTime `json:"to_time"`
+	Limit     int32        `json:"limit"`
+	Offset    int32        `json:"offset"`
+}
+
+type ListEntriesByAccountRow struct {
+	ID             int64     `json:"id"`
+	AccountID      int64     `json:"account_id"`
+	Amount         int64     `json:"amount"`
+	CreatedAt      time.Time `json:"created_at"`
+	RunningBalance int64     `json:"running_balance"`
+}
+
+func (q *Queries) ListEntriesByAccount(ctx context.Context, arg ListEntriesByAccountParams) ([]ListEntriesByAccountRow, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesByAccount,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListEntriesByAccountRow{}
+	for rows.Next() {
+		var i ListEntriesByAccountRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.RunningBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesByAccountAfter = `-- name: ListEntriesByAccountAfter :many
+SELECT id, account_id, amount, created_at, running_balance
+FROM (SELECT e.id,
+             e.account_id,
+             e.amount,
+             e.created_at,
+             (a.balance - SUM(e.amount) OVER (ORDER BY e.created_at DESC, e.id DESC) + e.amount)::bigint AS running_balance
+      FROM entries e
+               JOIN accounts a ON a.id = e.account_id
+      WHERE e.account_id = $1) AS account_entries
+WHERE ($2::timestamp IS NULL OR created_at >= $2)
+  AND ($3::timestamp IS NULL OR created_at < $3)
+  AND ($4::timestamp IS NULL
+    OR (created_at, id) > ($4, $5::bigint))
+ORDER BY created_at, id
+LIMIT $6
+`
+
+type ListEntriesByAccountAfterParams struct {
+	AccountID       int64         `json:"account_id"`
+	FromTime        sql.NullTime  `json:"from_time"`
+	ToTime          sql.NullTime  `json:"to_time"`
+	CursorCreatedAt sql.NullTime  `json:"cursor_created_at"`
+	CursorID        sql.NullInt64 `json:"cursor_id"`
+	Limit           int32         `json:"limit"`
+}
+
+type ListEntriesByAccountAfterRow struct {
+	ID             int64     `json:"id"`
+	AccountID      int64     `json:"account_id"`
+	Amount         int64     `json:"amount"`
+	CreatedAt      time.Time `json:"created_at"`
+	RunningBalance int64     `json:"running_balance"`
+}
+
+func (q *Queries) ListEntriesByAccountAfter(ctx context.Context, arg ListEntriesByAccountAfterParams) ([]ListEntriesByAccountAfterRow, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesByAccountAfter,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListEntriesByAccountAfterRow{}
+	for rows.Next() {
+		var i ListEntriesByAccountAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.RunningBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesByAccountBefore = `-- name: ListEntriesByAccountBefore :many
+SELECT id, account_id, amount, created_at, running_balance
+FROM (SELECT e.id,
+             e.account_id,
+             e.amount,
+             e.created_at,
+             (a.balance - SUM(e.amount) OVER (ORDER BY e.created_at DESC, e.id DESC) + e.amount)::bigint AS running_balance
+      FROM entries e
+               JOIN accounts a ON a.id = e.account_id
+      WHERE e.account_id = $1) AS account_entries
+WHERE ($2::timestamp IS NULL OR created_at >= $2)
+  AND ($3::timestamp IS NULL OR created_at < $3)
+  AND (created_at, id) < ($4::timestamp, $5::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT $6
+`
+
+type ListEntriesByAccountBeforeParams struct {
+	AccountID       int64        `json:"account_id"`
+	FromTime        sql.NullTime `json:"from_time"`
+	ToTime          sql.NullTime `json:"to_time"`
+	CursorCreatedAt time.Time    `json:"cursor_created_at"`
+	CursorID        int64        `json:"cursor_id"`
+	Limit           int32        `json:"limit"`
+}
+
+type ListEntriesByAccountBeforeRow struct {
+	ID             int64     `json:"id"`
+	AccountID      int64     `json:"account_id"`
+	Amount         int64     `json:"amount"`
+	CreatedAt      time.Time `json:"created_at"`
+	RunningBalance int64     `json:"running_balance"`
+}
+
+func (q *Queries) ListEntriesByAccountBefore(ctx context.Context, arg ListEntriesByAccountBeforeParams) ([]ListEntriesByAccountBeforeRow, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesByAccountBefore,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListEntriesByAccountBeforeRow{}
+	for rows.Next() {
+		var i ListEntriesByAccountBeforeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.RunningBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStatementEntries = `-- name: ListStatementEntries :many
+SELECT e.id,
+       e.amount,
+       e.created_at,
+       e.transfer_id,
+       t.from_account_id AS transfer_from_account_id,
+       t.to_account_id   AS transfer_to_account_id
+FROM entries e
+         LEFT JOIN transfers t ON t.id = e.transfer_id
+WHERE e.account_id = $1
+  AND e.created_at >= $2
+  AND e.created_at < $3
+ORDER BY e.created_at, e.id
+`
+
+type ListStatementEntriesParams struct {
+	AccountID int64     `json:"account_id"`
+	FromTime  time.Time `json:"from_time"`
+	ToTime    time.Time `json:"to_time"`
+}
+
+type ListStatementEntriesRow struct {
+	ID                    int64         `json:"id"`
+	Amount                int64         `json:"amount"`
+	CreatedAt             time.Time     `json:"created_at"`
+	TransferID            sql.NullInt64 `json:"transfer_id"`
+	TransferFromAccountID sql.NullInt64 `json:"transfer_from_account_id"`
+	TransferToAccountID   sql.NullInt64 `json:"transfer_to_account_id"`
+}
+
+func (q *Queries) ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listStatementEntries, arg.AccountID, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListStatementEntriesRow{}
+	for rows.Next() {
+		var i ListStatementEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.TransferFromAccountID,
+			&i.TransferToAccountID,
 		); err != nil {
 			return nil, err
 		}
@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"simplebank/util"
 	"testing"
+	"time"
 )
 
 func TestQueries_CreateEntry(t *testing.T) {
@@ -36,9 +37,13 @@ func TestQueries_CreateEntry(t *testing.T) {
 			require.NoError(t, err)
 			require.NotZero(t, account.ID)
 
+			journal, err := randomJournal(ctx)
+			require.NoError(t, err)
+
 			entry, err := testQueries.CreateEntry(ctx, CreateEntryParams{
 				AccountID: account.ID,
 				Amount:    tt.params.Amount,
+				JournalID: journal.ID,
 			})
 			require.NoError(t, err)
 
@@ -65,9 +70,13 @@ func TestQueries_GetEntry(t *testing.T) {
 				require.NoError(t, err)
 				require.NotZero(t, account.ID)
 
+				journal, err := randomJournal(ctx)
+				require.NoError(t, err)
+
 				entry, err := testQueries.CreateEntry(ctx, CreateEntryParams{
 					AccountID: account.ID,
 					Amount:    util.RandomMoney(),
+					JournalID: journal.ID,
 				})
 				require.NoError(t, err)
 				require.NotZero(t, entry.ID)
@@ -112,9 +121,13 @@ func TestQueries_ListEntries(t *testing.T) {
 				require.NotZero(t, account.ID)
 
 				for i := 0; i < numEntries; i++ {
+					journal, err := randomJournal(ctx)
+					require.NoError(t, err)
+
 					entry, err := testQueries.CreateEntry(ctx, CreateEntryParams{
 						AccountID: account.ID,
 						Amount:    util.RandomMoney(),
+						JournalID: journal.ID,
 					})
 					require.NoError(t, err)
 					require.NotZero(t, entry.ID)
@@ -137,3 +150,181 @@ func TestQueries_ListEntries(t *testing.T) {
 		})
 	}
 }
+
+func TestQueries_ListEntriesByAccount(t *testing.T) {
+	tests := []struct {
+		name        string
+		testingFunc func(t *testing.T)
+	}{
+		{
+			name: "When it returns entries with running balance",
+			testingFunc: func(t *testing.T) {
+				ctx := context.Background()
+				store := NewStore(testDb)
+
+				fromAcc, err := randomAccountWithBalance(ctx, 1000)
+				require.NoError(t, err)
+
+				toAcc, err := randomAccountWithBalance(ctx, 1000)
+				require.NoError(t, err)
+
+				for i := 0; i < 3; i++ {
+					_, err := store.TransferTx(ctx, TransferTxParams{
+						FromAccountID: fromAcc.ID,
+						ToAccountID:   toAcc.ID,
+						Amount:        10,
+					})
+					require.NoError(t, err)
+				}
+
+				entries, err := testQueries.ListEntriesByAccount(ctx, ListEntriesByAccountParams{
+					AccountID: fromAcc.ID,
+					Limit:     5,
+					Offset:    0,
+				})
+				require.NoError(t, err)
+				require.Len(t, entries, 3)
+
+				for i, entry := range entries {
+					require.Equal(t, fromAcc.ID, entry.AccountID)
+					require.Equal(t, int64(-10), entry.Amount)
+					require.Equal(t, fromAcc.Balance-int64(10*(i+1)), entry.RunningBalance)
+				}
+			},
+		},
+		{
+			name: "When it filters entries by created_at range",
+			testingFunc: func(t *testing.T) {
+				ctx := context.Background()
+
+				account, err := randomAccount(ctx)
+				require.NoError(t, err)
+
+				journal, err := randomJournal(ctx)
+				require.NoError(t, err)
+
+				_, err = testQueries.CreateEntry(ctx, CreateEntryParams{
+					AccountID: account.ID,
+					Amount:    util.RandomMoney(),
+					JournalID: journal.ID,
+				})
+				require.NoError(t, err)
+
+				entries, err := testQueries.ListEntriesByAccount(ctx, ListEntriesByAccountParams{
+					AccountID: account.ID,
+					FromTime:  sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+					Limit:     5,
+					Offset:    0,
+				})
+				require.NoError(t, err)
+				require.Empty(t, entries)
+
+				entries, err = testQueries.ListEntriesByAccount(ctx, ListEntriesByAccountParams{
+					AccountID: account.ID,
+					ToTime:    sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+					Limit:     5,
+					Offset:    0,
+				})
+				require.NoError(t, err)
+				require.Len(t, entries, 1)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.testingFunc(t)
+		})
+	}
+}
+
+func TestQueries_ListEntriesByAccountAfter(t *testing.T) {
+	ctx := context.Background()
+
+	account, err := randomAccount(ctx)
+	require.NoError(t, err)
+
+	journal, err := randomJournal(ctx)
+	require.NoError(t, err)
+
+	var created []Entry
+	for _, amount := range []int64{10, 20, 30} {
+		entry, err := testQueries.CreateEntry(ctx, CreateEntryParams{
+			AccountID: account.ID,
+			Amount:    amount,
+			JournalID: journal.ID,
+		})
+		require.NoError(t, err)
+		created = append(created, entry)
+	}
+
+	//The last entry is backdated, so its id and created_at orders disagree
+	_, err = testDb.ExecContext(ctx, "UPDATE entries SET created_at = created_at - interval '1 hour' WHERE id = $1", created[2].ID)
+	require.NoError(t, err)
+
+	entries, err := testQueries.ListEntriesByAccountAfter(ctx, ListEntriesByAccountAfterParams{
+		AccountID: account.ID,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	//The running balance follows the page order, and the last entry ends on the account balance
+	require.Equal(t, []int64{created[2].ID, created[0].ID, created[1].ID}, []int64{entries[0].ID, entries[1].ID, entries[2].ID})
+	require.Equal(t, account.Balance-30, entries[0].RunningBalance)
+	require.Equal(t, account.Balance-20, entries[1].RunningBalance)
+	require.Equal(t, account.Balance, entries[2].RunningBalance)
+
+	next, err := testQueries.ListEntriesByAccountAfter(ctx, ListEntriesByAccountAfterParams{
+		AccountID:       account.ID,
+		CursorCreatedAt: sql.NullTime{Time: entries[0].CreatedAt, Valid: true},
+		CursorID:        sql.NullInt64{Int64: entries[0].ID, Valid: true},
+		Limit:           5,
+	})
+	require.NoError(t, err)
+	require.Len(t, next, 2)
+	require.Equal(t, entries[1:], next)
+}
+
+func TestQueries_ListStatementEntries(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := NewStore(testDb)
+
+	fromAcc, err := randomAccountWithBalance(ctx, 1000)
+	require.NoError(t, err)
+
+	toAcc, err := randomAccountWithBalance(ctx, 1000)
+	require.NoError(t, err)
+
+	from := time.Now().Add(-time.Hour)
+
+	result, err := store.TransferTx(ctx, TransferTxParams{
+		FromAccountID: fromAcc.ID,
+		ToAccountID:   toAcc.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, sql.NullInt64{Int64: result.Transfer.ID, Valid: true}, result.FromEntry.TransferID)
+
+	entries, err := testQueries.ListStatementEntries(ctx, ListStatementEntriesParams{
+		AccountID: fromAcc.ID,
+		FromTime:  from,
+		ToTime:    time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, result.FromEntry.ID, entries[0].ID)
+	require.Equal(t, int64(-10), entries[0].Amount)
+	require.Equal(t, result.Transfer.ID, entries[0].TransferID.Int64)
+	require.Equal(t, fromAcc.ID, entries[0].TransferFromAccountID.Int64)
+	require.Equal(t, toAcc.ID, entries[0].TransferToAccountID.Int64)
+
+	opening, err := testQueries.GetAccountBalanceAt(ctx, GetAccountBalanceAtParams{
+		At:        from,
+		AccountID: fromAcc.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, fromAcc.Balance, opening)
+}
@@ -0,0 +1,42 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func randomJournal(ctx context.Context) (JournalTransaction, error) {
+	return testQueries.CreateJournalTransaction(ctx)
+}
+
+func TestQueries_ListEntriesByJournal(t *testing.T) {
+	ctx := context.Background()
+
+	account, err := randomAccount(ctx)
+	require.NoError(t, err)
+
+	journal, err := randomJournal(ctx)
+	require.NoError(t, err)
+	require.NotZero(t, journal.ID)
+	require.NotZero(t, journal.CreatedAt)
+
+	other, err := randomJournal(ctx)
+	require.NoError(t, err)
+
+	for _, params := range []CreateEntryParams{
+		{AccountID: account.ID, Amount: 10, JournalID: journal.ID},
+		{AccountID: account.ID, Amount: -10, JournalID: journal.ID},
+		{AccountID: account.ID, Amount: 5, JournalID: other.ID},
+	} {
+		_, err := testQueries.CreateEntry(ctx, params)
+		require.NoError(t, err)
+	}
+
+	entries, err := testQueries.ListEntriesByJournal(ctx, journal.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	for _, entry := range entries {
+		require.Equal(t, journal.ID, entry.JournalID)
+	}
+}
@@ -0,0 +1,5 @@
+alter table transfers
+    drop constraint if exists transfers_amount_positive;
+
+alter table accounts
+    drop constraint if exists accounts_balance_non_negative;
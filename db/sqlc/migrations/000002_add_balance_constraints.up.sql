@@ -0,0 +1,5 @@
+alter table accounts
+    add constraint accounts_balance_non_negative check (balance >= 0);
+
+alter table transfers
+    add constraint transfers_amount_positive check (amount > 0);
@@ -0,0 +1,4 @@
+alter table if exists accounts
+    drop constraint if exists accounts_owner_fkey;
+
+drop table if exists users cascade;
@@ -0,0 +1,25 @@
+create table users
+(
+    username            varchar
+        primary key,
+    hashed_password     varchar                                   not null,
+    full_name           varchar                                   not null,
+    email               varchar                                   not null
+        unique,
+    password_changed_at timestamp default '0001-01-01 00:00:00'   not null,
+    created_at          timestamp default now()                   not null
+);
+
+alter table users
+    owner to root;
+
+-- accounts created before users existed get a placeholder user so the foreign key holds,
+-- the empty password hash never matches so these users can't log in until an operator resets them
+insert into users (username, hashed_password, full_name, email)
+select distinct a.owner, '', a.owner, a.owner || '@migrated.invalid'
+from accounts a
+where not exists(select 1 from users u where u.username = a.owner);
+
+alter table accounts
+    add constraint accounts_owner_fkey
+        foreign key (owner) references users (username);
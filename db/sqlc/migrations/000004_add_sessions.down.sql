@@ -0,0 +1,4 @@
+drop table if exists sessions cascade;
+
+alter table if exists users
+    drop column if exists role;
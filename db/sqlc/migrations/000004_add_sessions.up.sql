@@ -0,0 +1,22 @@
+alter table users
+    add column role varchar default 'depositor' not null;
+
+create table sessions
+(
+    id            uuid
+        primary key,
+    username      varchar                 not null
+        references users,
+    refresh_token varchar                 not null,
+    user_agent    varchar                 not null,
+    client_ip     varchar                 not null,
+    is_blocked    boolean default false   not null,
+    expires_at    timestamp               not null,
+    created_at    timestamp default now() not null
+);
+
+alter table sessions
+    owner to root;
+
+create index sessions_username_idx
+    on sessions (username);
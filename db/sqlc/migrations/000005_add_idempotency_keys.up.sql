@@ -0,0 +1,16 @@
+create table idempotency_keys
+(
+    username      varchar                 not null
+        references users,
+    key           varchar                 not null,
+    request_hash  varchar                 not null,
+    response_body jsonb   default '{}'    not null,
+    expires_at    timestamp               not null,
+    created_at    timestamp default now() not null,
+    primary key (username, key)
+);
+
+comment on column idempotency_keys.request_hash is 'hex encoded SHA-256 of the canonical request';
+
+alter table idempotency_keys
+    owner to root;
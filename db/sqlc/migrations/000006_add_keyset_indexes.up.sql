@@ -0,0 +1,8 @@
+create index accounts_owner_created_at_id_idx
+    on accounts (owner, created_at, id);
+
+create index entries_account_id_created_at_id_idx
+    on entries (account_id, created_at, id);
+
+create index transfers_created_at_id_idx
+    on transfers (created_at, id);
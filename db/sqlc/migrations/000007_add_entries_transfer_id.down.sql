@@ -0,0 +1,4 @@
+drop index if exists entries_transfer_id_idx;
+
+alter table entries
+    drop column if exists transfer_id;
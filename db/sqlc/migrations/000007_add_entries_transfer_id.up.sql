@@ -0,0 +1,17 @@
+alter table entries
+    add column transfer_id bigint
+        references transfers;
+
+comment on column entries.transfer_id is 'transfer that originated the entry, used to resolve the counterparty';
+
+-- entries and their transfer are created in the same transaction, so they share created_at
+update entries e
+set transfer_id = t.id
+from transfers t
+where e.transfer_id is null
+  and e.created_at = t.created_at
+  and ((e.account_id = t.from_account_id and e.amount = -t.amount)
+    or (e.account_id = t.to_account_id and e.amount = t.amount));
+
+create index entries_transfer_id_idx
+    on entries (transfer_id);
@@ -0,0 +1,9 @@
+alter table transfers
+    drop constraint if exists transfers_spread_bps_range,
+    drop constraint if exists transfers_exchange_rate_positive,
+    drop constraint if exists transfers_to_amount_positive,
+    drop column if exists spread_bps,
+    drop column if exists exchange_rate,
+    drop column if exists to_amount;
+
+comment on column transfers.amount is 'must be positive';
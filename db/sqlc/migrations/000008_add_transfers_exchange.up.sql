@@ -0,0 +1,22 @@
+alter table transfers
+    add column to_amount     bigint,
+    add column exchange_rate bigint  default 1000000 not null,
+    add column spread_bps    integer default 0       not null;
+
+update transfers
+set to_amount = amount
+where to_amount is null;
+
+alter table transfers
+    alter column to_amount set not null,
+    add constraint transfers_to_amount_positive check (to_amount > 0),
+    add constraint transfers_exchange_rate_positive check (exchange_rate > 0),
+    add constraint transfers_spread_bps_range check (spread_bps >= 0 and spread_bps < 10000);
+
+comment on column transfers.amount is 'must be positive, debited in the source account currency';
+
+comment on column transfers.to_amount is 'must be positive, credited in the destination account currency';
+
+comment on column transfers.exchange_rate is 'mid-market rate from source to destination currency scaled by 1e6';
+
+comment on column transfers.spread_bps is 'spread charged over the converted amount in basis points';
@@ -0,0 +1,4 @@
+alter table accounts
+    drop constraint if exists accounts_currency_fkey;
+
+drop table if exists currencies;
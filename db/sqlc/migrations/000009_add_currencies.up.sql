@@ -0,0 +1,31 @@
+create table currencies
+(
+    code         varchar(3)
+        primary key,
+    numeric_code integer               not null
+        unique,
+    minor_units  integer               not null,
+    enabled      boolean default false not null,
+    constraint currencies_code_format check (code ~ '^[A-Z]{3}$'),
+    constraint currencies_numeric_code_range check (numeric_code between 1 and 999),
+    constraint currencies_minor_units_range check (minor_units between 0 and 4)
+);
+
+comment on column currencies.numeric_code is 'ISO 4217 numeric code';
+
+comment on column currencies.minor_units is 'ISO 4217 exponent, amounts are stored in this minor unit';
+
+comment on column currencies.enabled is 'whether new accounts and transfers may use the currency';
+
+alter table currencies
+    owner to root;
+
+insert into currencies (code, numeric_code, minor_units, enabled)
+values ('BRL', 986, 2, false),
+       ('CAD', 124, 2, false),
+       ('EUR', 978, 2, true),
+       ('USD', 840, 2, true);
+
+alter table accounts
+    add constraint accounts_currency_fkey
+        foreign key (currency) references currencies (code);
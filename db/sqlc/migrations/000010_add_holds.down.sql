@@ -0,0 +1,6 @@
+drop table if exists holds;
+
+alter table accounts
+    drop constraint if exists accounts_available_balance_non_negative,
+    drop constraint if exists accounts_held_balance_non_negative,
+    drop column if exists held_balance;
@@ -0,0 +1,43 @@
+alter table accounts
+    add column held_balance bigint default 0 not null,
+    add constraint accounts_held_balance_non_negative check (held_balance >= 0),
+    add constraint accounts_available_balance_non_negative check (balance - held_balance >= 0);
+
+comment on column accounts.held_balance is 'sum of the active holds reserving part of the balance';
+
+create table holds
+(
+    id              bigserial
+        primary key,
+    account_id      bigint                   not null
+        references accounts,
+    to_account_id   bigint                   not null
+        references accounts,
+    amount          bigint                   not null,
+    captured_amount bigint    default 0        not null,
+    status          varchar   default 'active' not null,
+    transfer_id     bigint
+        references transfers,
+    expires_at      timestamp                not null,
+    created_at      timestamp default now()  not null,
+    constraint holds_amount_positive check (amount > 0),
+    constraint holds_captured_amount_range check (captured_amount between 0 and amount),
+    constraint holds_status_valid check (status in ('active', 'captured', 'released', 'expired')),
+    constraint holds_accounts_differ check (account_id <> to_account_id)
+);
+
+comment on column holds.amount is 'must be positive';
+
+comment on column holds.captured_amount is 'amount moved by the capture, zero until captured';
+
+comment on column holds.status is 'active, captured, released or expired';
+
+alter table holds
+    owner to root;
+
+create index holds_account_id_idx
+    on holds (account_id);
+
+create index holds_active_expires_at_idx
+    on holds (expires_at)
+    where status = 'active';
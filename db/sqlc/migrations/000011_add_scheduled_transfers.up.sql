@@ -0,0 +1,66 @@
+create table scheduled_transfers
+(
+    id              bigserial
+        primary key,
+    owner           varchar                    not null
+        references users,
+    from_account_id bigint                     not null
+        references accounts,
+    to_account_id   bigint                     not null
+        references accounts,
+    amount          bigint                     not null,
+    recurrence      varchar   default ''       not null,
+    scheduled_for   timestamp                  not null,
+    next_run_at     timestamp                  not null,
+    end_at          timestamp,
+    status          varchar   default 'active' not null,
+    attempts        integer   default 0        not null,
+    last_error      varchar   default ''       not null,
+    claimed_until   timestamp,
+    created_at      timestamp default now()    not null,
+    constraint scheduled_transfers_amount_positive check (amount > 0),
+    constraint scheduled_transfers_accounts_differ check (from_account_id <> to_account_id),
+    constraint scheduled_transfers_status_valid check (status in ('active', 'paused', 'completed', 'failed', 'cancelled'))
+);
+
+comment on column scheduled_transfers.recurrence is 'RRULE such as FREQ=MONTHLY;BYMONTHDAY=1, empty for a one-off transfer';
+
+comment on column scheduled_transfers.scheduled_for is 'occurrence being executed';
+
+comment on column scheduled_transfers.next_run_at is 'when the occurrence is attempted, later than scheduled_for while retrying';
+
+comment on column scheduled_transfers.attempts is 'failed attempts of the current occurrence';
+
+comment on column scheduled_transfers.claimed_until is 'lease of the worker executing the occurrence';
+
+alter table scheduled_transfers
+    owner to root;
+
+create index scheduled_transfers_owner_idx
+    on scheduled_transfers (owner);
+
+create index scheduled_transfers_due_idx
+    on scheduled_transfers (next_run_at)
+    where status = 'active';
+
+create table scheduled_transfer_runs
+(
+    id                    bigserial
+        primary key,
+    scheduled_transfer_id bigint                  not null
+        references scheduled_transfers,
+    scheduled_for         timestamp               not null,
+    attempt               integer                 not null,
+    transfer_id           bigint
+        references transfers,
+    error                 varchar default ''      not null,
+    created_at            timestamp default now() not null
+);
+
+comment on column scheduled_transfer_runs.error is 'empty when the transfer succeeded';
+
+alter table scheduled_transfer_runs
+    owner to root;
+
+create index scheduled_transfer_runs_scheduled_transfer_id_idx
+    on scheduled_transfer_runs (scheduled_transfer_id);
@@ -0,0 +1,4 @@
+alter table accounts
+    drop constraint if exists accounts_closed_zero_balance,
+    drop constraint if exists accounts_status_valid,
+    drop column if exists status;
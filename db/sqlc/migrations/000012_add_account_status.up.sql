@@ -0,0 +1,6 @@
+alter table accounts
+    add column status varchar default 'active' not null,
+    add constraint accounts_status_valid check (status in ('active', 'frozen', 'closed')),
+    add constraint accounts_closed_zero_balance check (status <> 'closed' or (balance = 0 and held_balance = 0));
+
+comment on column accounts.status is 'active, frozen or closed, a closed account is never reopened';
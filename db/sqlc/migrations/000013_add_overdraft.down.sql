@@ -0,0 +1,11 @@
+drop table if exists overdraft_interest_charges;
+
+drop table if exists overdraft_limit_changes;
+
+alter table accounts
+    drop constraint if exists accounts_available_balance_within_overdraft,
+    drop constraint if exists accounts_balance_within_overdraft,
+    add constraint accounts_available_balance_non_negative check (balance - held_balance >= 0),
+    add constraint accounts_balance_non_negative check (balance >= 0),
+    drop constraint if exists accounts_overdraft_limit_non_negative,
+    drop column if exists overdraft_limit;
@@ -0,0 +1,53 @@
+alter table accounts
+    add column overdraft_limit bigint default 0 not null,
+    add constraint accounts_overdraft_limit_non_negative check (overdraft_limit >= 0),
+    drop constraint accounts_balance_non_negative,
+    drop constraint accounts_available_balance_non_negative,
+    add constraint accounts_balance_within_overdraft check (balance + overdraft_limit >= 0),
+    add constraint accounts_available_balance_within_overdraft check (balance - held_balance + overdraft_limit >= 0);
+
+comment on column accounts.overdraft_limit is 'how far below zero the balance may go';
+
+create table overdraft_limit_changes
+(
+    id         bigserial
+        primary key,
+    account_id bigint                  not null
+        references accounts,
+    old_limit  bigint                  not null,
+    new_limit  bigint                  not null,
+    changed_by varchar                 not null
+        references users,
+    reason     varchar                 not null,
+    created_at timestamp default now() not null
+);
+
+comment on table overdraft_limit_changes is 'audit trail of the overdraft limits set by admins';
+
+alter table overdraft_limit_changes
+    owner to root;
+
+create index overdraft_limit_changes_account_id_idx
+    on overdraft_limit_changes (account_id);
+
+create table overdraft_interest_charges
+(
+    account_id bigint                  not null
+        references accounts,
+    day        date                    not null,
+    balance    bigint                  not null,
+    rate_bps   bigint                  not null,
+    amount     bigint                  not null,
+    entry_id   bigint                  not null
+        references entries,
+    created_at timestamp default now() not null,
+    primary key (account_id, day),
+    constraint overdraft_interest_charges_amount_positive check (amount > 0)
+);
+
+comment on column overdraft_interest_charges.balance is 'negative end-of-day balance the interest was charged on';
+
+comment on column overdraft_interest_charges.rate_bps is 'yearly interest rate in basis points';
+
+alter table overdraft_interest_charges
+    owner to root;
@@ -0,0 +1,40 @@
+create table transfer_limits
+(
+    id             bigserial
+        primary key,
+    account_id     bigint
+        references accounts,
+    owner          varchar
+        references users,
+    currency       varchar
+        references currencies,
+    daily_amount   bigint,
+    monthly_amount bigint,
+    daily_count    bigint,
+    updated_at     timestamp default now() not null,
+    constraint transfer_limits_scope check ((account_id is not null and owner is null and currency is null)
+        or (account_id is null and owner is not null and currency is not null)),
+    constraint transfer_limits_positive check (daily_amount > 0 and monthly_amount > 0 and daily_count > 0)
+);
+
+comment on table transfer_limits is 'outbound velocity limits of an account, or of all the accounts of a user in a currency';
+
+comment on column transfer_limits.daily_amount is 'maximum amount sent per UTC day, null is unlimited';
+
+comment on column transfer_limits.monthly_amount is 'maximum amount sent per UTC month, null is unlimited';
+
+comment on column transfer_limits.daily_count is 'maximum number of transfers sent per UTC day, null is unlimited';
+
+alter table transfer_limits
+    owner to root;
+
+create unique index transfer_limits_account_id_key
+    on transfer_limits (account_id)
+    where account_id is not null;
+
+create unique index transfer_limits_owner_currency_key
+    on transfer_limits (owner, currency)
+    where owner is not null;
+
+create index transfers_from_account_id_created_at_idx
+    on transfers (from_account_id, created_at);
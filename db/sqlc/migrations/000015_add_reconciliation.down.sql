@@ -0,0 +1,3 @@
+drop table if exists reconciliation_issues;
+
+drop table if exists reconciliation_runs;
@@ -0,0 +1,42 @@
+create table reconciliation_runs
+(
+    id                bigserial
+        primary key,
+    accounts_checked  bigint                  not null,
+    transfers_checked bigint                  not null,
+    issues_count      bigint                  not null,
+    started_at        timestamp               not null,
+    finished_at       timestamp default now() not null
+);
+
+comment on table reconciliation_runs is 'ledger checks proving account balances and transfers match their entries';
+
+alter table reconciliation_runs
+    owner to root;
+
+create table reconciliation_issues
+(
+    id          bigserial
+        primary key,
+    run_id      bigint             not null
+        references reconciliation_runs,
+    kind        varchar            not null,
+    account_id  bigint,
+    transfer_id bigint,
+    expected    bigint             not null,
+    actual      bigint             not null,
+    detail      varchar default '' not null,
+    constraint reconciliation_issues_kind_valid check (kind in ('account_drift', 'transfer_entries'))
+);
+
+comment on column reconciliation_issues.kind is 'account_drift when the balance differs from the sum of entries, transfer_entries when a transfer lacks its debit and credit entries';
+
+comment on column reconciliation_issues.expected is 'sum of the account entries, or the 2 entries of a transfer';
+
+comment on column reconciliation_issues.actual is 'account balance, or the entries found for the transfer';
+
+alter table reconciliation_issues
+    owner to root;
+
+create index reconciliation_issues_run_id_idx
+    on reconciliation_issues (run_id);
@@ -0,0 +1,6 @@
+drop index if exists entries_journal_id_idx;
+
+alter table entries
+    drop column if exists journal_id;
+
+drop table if exists journal_transactions;
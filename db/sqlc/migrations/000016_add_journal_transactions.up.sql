@@ -0,0 +1,51 @@
+create table journal_transactions
+(
+    id         bigserial
+        primary key,
+    created_at timestamp default now() not null
+);
+
+comment on table journal_transactions is 'balanced set of entries posted atomically';
+
+alter table journal_transactions
+    owner to root;
+
+alter table entries
+    add column journal_id bigint
+        references journal_transactions;
+
+comment on column entries.journal_id is 'journal transaction the entry was posted in';
+
+-- the entries of a transfer were posted together, so they share a journal
+with transfer_journals as (select t.id                                        as transfer_id,
+                                  t.created_at,
+                                  nextval('journal_transactions_id_seq') as journal_id
+                           from transfers t
+                           where exists(select 1 from entries e where e.transfer_id = t.id)),
+     journals as (insert into journal_transactions (id, created_at)
+         select journal_id, created_at
+         from transfer_journals)
+update entries e
+set journal_id = tj.journal_id
+from transfer_journals tj
+where e.transfer_id = tj.transfer_id;
+
+-- any other entry was posted on its own
+with entry_journals as (select e.id                                        as entry_id,
+                               e.created_at,
+                               nextval('journal_transactions_id_seq') as journal_id
+                        from entries e
+                        where e.journal_id is null),
+     journals as (insert into journal_transactions (id, created_at)
+         select journal_id, created_at
+         from entry_journals)
+update entries e
+set journal_id = ej.journal_id
+from entry_journals ej
+where e.id = ej.entry_id;
+
+alter table entries
+    alter column journal_id set not null;
+
+create index entries_journal_id_idx
+    on entries (journal_id);
@@ -0,0 +1,27 @@
+create table transfer_reversals
+(
+    id                   bigserial
+        primary key,
+    transfer_id          bigint                  not null
+        references transfers,
+    reversal_transfer_id bigint                  not null
+        unique
+        references transfers,
+    amount               bigint                  not null,
+    reversed_by          varchar                 not null
+        references users,
+    reason               varchar                 not null,
+    created_at           timestamp default now() not null,
+    constraint transfer_reversals_amount_positive check (amount > 0),
+    constraint transfer_reversals_transfers_differ check (transfer_id <> reversal_transfer_id)
+);
+
+comment on table transfer_reversals is 'compensating transfers booked by staff to undo a transfer in full or in part';
+
+comment on column transfer_reversals.amount is 'returned to the source account, in the currency of the original transfer amount';
+
+alter table transfer_reversals
+    owner to root;
+
+create index transfer_reversals_transfer_id_idx
+    on transfer_reversals (transfer_id);
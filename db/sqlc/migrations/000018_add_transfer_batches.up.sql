@@ -0,0 +1,65 @@
+create table transfer_batches
+(
+    id              bigserial
+        primary key,
+    owner           varchar                     not null
+        references users,
+    from_account_id bigint                      not null
+        references accounts,
+    mode            varchar                     not null,
+    status          varchar   default 'pending' not null,
+    total_amount    bigint                      not null,
+    items_count     integer                     not null,
+    succeeded_count integer   default 0         not null,
+    failed_count    integer   default 0         not null,
+    claimed_until   timestamp,
+    created_at      timestamp default now()     not null,
+    finished_at     timestamp,
+    constraint transfer_batches_total_amount_positive check (total_amount > 0),
+    constraint transfer_batches_mode_valid check (mode in ('all_or_nothing', 'best_effort')),
+    constraint transfer_batches_status_valid check (status in
+                                                    ('pending', 'processing', 'completed', 'partially_completed',
+                                                     'failed'))
+);
+
+comment on table transfer_batches is 'transfers from one account submitted together and processed in the background';
+
+comment on column transfer_batches.mode is 'all_or_nothing books every transfer or none, best_effort books those that can be';
+
+comment on column transfer_batches.claimed_until is 'lease of the worker processing the batch';
+
+alter table transfer_batches
+    owner to root;
+
+create index transfer_batches_owner_idx
+    on transfer_batches (owner);
+
+create index transfer_batches_unfinished_idx
+    on transfer_batches (id)
+    where status in ('pending', 'processing');
+
+create table transfer_batch_items
+(
+    id            bigserial
+        primary key,
+    batch_id      bigint                      not null
+        references transfer_batches,
+    position      integer                     not null,
+    to_account_id bigint                      not null
+        references accounts,
+    amount        bigint                      not null,
+    status        varchar   default 'pending' not null,
+    transfer_id   bigint
+        references transfers,
+    error         varchar   default ''        not null,
+    constraint transfer_batch_items_amount_positive check (amount > 0),
+    constraint transfer_batch_items_status_valid check (status in ('pending', 'succeeded', 'failed')),
+    constraint transfer_batch_items_batch_id_position_key unique (batch_id, position)
+);
+
+comment on column transfer_batch_items.position is 'index of the transfer in the submitted batch, from 0';
+
+comment on column transfer_batch_items.error is 'why the transfer was not booked, empty unless it failed';
+
+alter table transfer_batch_items
+    owner to root;
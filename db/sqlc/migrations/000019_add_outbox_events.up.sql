@@ -0,0 +1,30 @@
+create table outbox_events
+(
+    id              bigserial
+        primary key,
+    aggregate_type  varchar                 not null,
+    aggregate_id    bigint                  not null,
+    event_type      varchar                 not null,
+    payload         jsonb                   not null,
+    attempts        integer   default 0     not null,
+    next_attempt_at timestamp default now() not null,
+    last_error      varchar   default ''    not null,
+    claimed_until   timestamp,
+    created_at      timestamp default now() not null,
+    published_at    timestamp
+);
+
+comment on table outbox_events is 'domain events written with the change they describe and relayed to the publisher';
+
+comment on column outbox_events.attempts is 'failed publish attempts, the delay before the next one doubles with each';
+
+comment on column outbox_events.claimed_until is 'lease of the relay publishing the event';
+
+comment on column outbox_events.published_at is 'null until the publisher acknowledged the event';
+
+alter table outbox_events
+    owner to root;
+
+create index outbox_events_unpublished_idx
+    on outbox_events (aggregate_type, aggregate_id, id)
+    where published_at is null;
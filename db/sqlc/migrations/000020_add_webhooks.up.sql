@@ -0,0 +1,85 @@
+create table webhook_subscriptions
+(
+    id                   bigserial
+        primary key,
+    owner                varchar                    not null
+        references users,
+    url                  varchar                    not null,
+    event_types          varchar[]                  not null,
+    secret               varchar                    not null,
+    status               varchar   default 'active' not null,
+    consecutive_failures integer   default 0        not null,
+    created_at           timestamp default now()    not null,
+    disabled_at          timestamp,
+    constraint webhook_subscriptions_status_valid check (status in ('active', 'disabled')),
+    constraint webhook_subscriptions_event_types_not_empty check (cardinality(event_types) > 0)
+);
+
+comment on column webhook_subscriptions.secret is 'key of the HMAC-SHA256 signature of every delivery';
+
+comment on column webhook_subscriptions.consecutive_failures is 'failed attempts since the last successful one, the subscription is disabled past a threshold';
+
+alter table webhook_subscriptions
+    owner to root;
+
+create index webhook_subscriptions_owner_idx
+    on webhook_subscriptions (owner);
+
+create table webhook_deliveries
+(
+    id                   bigserial
+        primary key,
+    subscription_id      bigint                      not null
+        references webhook_subscriptions
+            on delete cascade,
+    event_id             bigint                      not null,
+    event_type           varchar                     not null,
+    payload              jsonb                       not null,
+    status               varchar   default 'pending' not null,
+    attempts             integer   default 0         not null,
+    next_attempt_at      timestamp default now()     not null,
+    last_response_status integer,
+    last_error           varchar   default ''        not null,
+    claimed_until        timestamp,
+    created_at           timestamp default now()     not null,
+    delivered_at         timestamp,
+    constraint webhook_deliveries_status_valid check (status in ('pending', 'succeeded', 'failed')),
+    constraint webhook_deliveries_subscription_id_event_id_key unique (subscription_id, event_id)
+);
+
+comment on table webhook_deliveries is 'an outbox event to be posted to a webhook subscription';
+
+comment on column webhook_deliveries.event_id is 'outbox event delivered, the same event is delivered once per subscription';
+
+comment on column webhook_deliveries.claimed_until is 'lease of the dispatcher posting the delivery';
+
+alter table webhook_deliveries
+    owner to root;
+
+create index webhook_deliveries_due_idx
+    on webhook_deliveries (next_attempt_at)
+    where status = 'pending';
+
+create table webhook_delivery_attempts
+(
+    id              bigserial
+        primary key,
+    delivery_id     bigint                  not null
+        references webhook_deliveries
+            on delete cascade,
+    attempt         integer                 not null,
+    response_status integer,
+    error           varchar default ''      not null,
+    duration_ms     bigint                  not null,
+    created_at      timestamp default now() not null
+);
+
+comment on column webhook_delivery_attempts.response_status is 'null when no response was received';
+
+comment on column webhook_delivery_attempts.error is 'empty when the receiver acknowledged the delivery';
+
+alter table webhook_delivery_attempts
+    owner to root;
+
+create index webhook_delivery_attempts_delivery_id_idx
+    on webhook_delivery_attempts (delivery_id);
@@ -0,0 +1,8 @@
+-- the refresh tokens can't be recovered from their hashes, so the existing sessions can't be used anymore
+update sessions
+set is_blocked = true;
+
+comment on column sessions.refresh_token_hash is null;
+
+alter table sessions
+    rename column refresh_token_hash to refresh_token;
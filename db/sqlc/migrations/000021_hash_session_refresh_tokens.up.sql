@@ -0,0 +1,7 @@
+alter table sessions
+    rename column refresh_token to refresh_token_hash;
+
+update sessions
+set refresh_token_hash = encode(sha256(convert_to(refresh_token_hash, 'UTF8')), 'hex');
+
+comment on column sessions.refresh_token_hash is 'hex SHA-256 of the refresh token, the token itself is never stored';
@@ -0,0 +1 @@
+drop index if exists idempotency_keys_expires_at_idx;
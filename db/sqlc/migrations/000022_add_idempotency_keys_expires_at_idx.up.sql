@@ -0,0 +1,2 @@
+create index idempotency_keys_expires_at_idx
+    on idempotency_keys (expires_at);
@@ -0,0 +1,2 @@
+-- the accounts of the bank and their entries are part of the ledger, they are kept
+drop table if exists system_accounts;
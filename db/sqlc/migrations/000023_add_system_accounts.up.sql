@@ -0,0 +1,45 @@
+-- the bank owns the accounts balancing its side of journals, the empty password hash never matches
+-- and the username can't be registered through the API, so nobody can log in as it
+insert into users (username, hashed_password, full_name, email)
+values ('simplebank-system', '', 'Simple Bank', 'system@simplebank.invalid');
+
+create table system_accounts
+(
+    purpose    varchar not null,
+    currency   varchar not null
+        references currencies,
+    account_id bigint  not null
+        unique
+        references accounts,
+    primary key (purpose, currency),
+    constraint system_accounts_purpose_valid check (purpose in ('fx_position', 'interest_income'))
+);
+
+comment on table system_accounts is 'accounts of the bank: its FX position and overdraft interest income in every currency';
+
+comment on column system_accounts.purpose is 'fx_position takes the side of cross-currency transfers, interest_income is credited the overdraft interest';
+
+alter table system_accounts
+    owner to root;
+
+-- FX positions go negative in the currencies the bank sells. Their overdraft is half the bigint range, not all of it,
+-- so balance + overdraft_limit in the accounts checks can't overflow once a position is positive
+with created as (
+    insert into accounts (owner, balance, currency, overdraft_limit)
+        select 'simplebank-system', 0, code, 4611686018427387903
+        from currencies
+        returning id, currency)
+insert
+into system_accounts (purpose, currency, account_id)
+select 'fx_position', currency, id
+from created;
+
+with created as (
+    insert into accounts (owner, balance, currency)
+        select 'simplebank-system', 0, code
+        from currencies
+        returning id, currency)
+insert
+into system_accounts (purpose, currency, account_id)
+select 'interest_income', currency, id
+from created;
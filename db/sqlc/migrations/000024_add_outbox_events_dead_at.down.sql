@@ -0,0 +1,7 @@
+drop index if exists outbox_events_unpublished_idx;
+create index outbox_events_unpublished_idx
+    on outbox_events (aggregate_type, aggregate_id, id)
+    where published_at is null;
+
+alter table outbox_events
+    drop column if exists dead_at;
@@ -0,0 +1,9 @@
+alter table outbox_events
+    add column dead_at timestamp;
+
+comment on column outbox_events.dead_at is 'set when the relay gave up publishing the event, it no longer holds back its aggregate';
+
+drop index if exists outbox_events_unpublished_idx;
+create index outbox_events_unpublished_idx
+    on outbox_events (aggregate_type, aggregate_id, id)
+    where published_at is null and dead_at is null;
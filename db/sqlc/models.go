@@ -3,7 +3,11 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Account struct {
@@ -12,6 +16,22 @@ type Account struct {
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// sum of the active holds reserving part of the balance
+	HeldBalance int64 `json:"held_balance"`
+	// active, frozen or closed, a closed account is never reopened
+	Status string `json:"status"`
+	// how far below zero the balance may go
+	OverdraftLimit int64 `json:"overdraft_limit"`
+}
+
+type Currency struct {
+	Code string `json:"code"`
+	// ISO 4217 numeric code
+	NumericCode int32 `json:"numeric_code"`
+	// ISO 4217 exponent, amounts are stored in this minor unit
+	MinorUnits int32 `json:"minor_units"`
+	// whether new accounts and transfers may use the currency
+	Enabled bool `json:"enabled"`
 }
 
 type Entry struct {
@@ -20,13 +40,287 @@ type Entry struct {
 	// can be negative or positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// transfer that originated the entry, used to resolve the counterparty
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	// journal transaction the entry was posted in
+	JournalID int64 `json:"journal_id"`
+}
+
+type Hold struct {
+	ID          int64 `json:"id"`
+	AccountID   int64 `json:"account_id"`
+	ToAccountID int64 `json:"to_account_id"`
+	// must be positive
+	Amount int64 `json:"amount"`
+	// amount moved by the capture, zero until captured
+	CapturedAmount int64 `json:"captured_amount"`
+	// active, captured, released or expired
+	Status     string        `json:"status"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type IdempotencyKey struct {
+	Username string `json:"username"`
+	Key      string `json:"key"`
+	// hex encoded SHA-256 of the canonical request
+	RequestHash  string          `json:"request_hash"`
+	ResponseBody json.RawMessage `json:"response_body"`
+	ExpiresAt    time.Time       `json:"expires_at"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// balanced set of entries posted atomically
+type JournalTransaction struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// domain events written with the change they describe and relayed to the publisher
+type OutboxEvent struct {
+	ID            int64           `json:"id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int64           `json:"aggregate_id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	// failed publish attempts, the delay before the next one doubles with each
+	Attempts      int32     `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LastError     string    `json:"last_error"`
+	// lease of the relay publishing the event
+	ClaimedUntil sql.NullTime `json:"claimed_until"`
+	CreatedAt    time.Time    `json:"created_at"`
+	// null until the publisher acknowledged the event
+	PublishedAt sql.NullTime `json:"published_at"`
+	// set when the relay gave up publishing the event, it no longer holds back its aggregate
+	DeadAt sql.NullTime `json:"dead_at"`
+}
+
+type OverdraftInterestCharge struct {
+	AccountID int64     `json:"account_id"`
+	Day       time.Time `json:"day"`
+	// negative end-of-day balance the interest was charged on
+	Balance int64 `json:"balance"`
+	// yearly interest rate in basis points
+	RateBps   int64     `json:"rate_bps"`
+	Amount    int64     `json:"amount"`
+	EntryID   int64     `json:"entry_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// audit trail of the overdraft limits set by admins
+type OverdraftLimitChange struct {
+	ID        int64     `json:"id"`
+	AccountID int64     `json:"account_id"`
+	OldLimit  int64     `json:"old_limit"`
+	NewLimit  int64     `json:"new_limit"`
+	ChangedBy string    `json:"changed_by"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ReconciliationIssue struct {
+	ID    int64 `json:"id"`
+	RunID int64 `json:"run_id"`
+	// account_drift when the balance differs from the sum of entries, transfer_entries when a transfer lacks its debit and credit entries
+	Kind       string        `json:"kind"`
+	AccountID  sql.NullInt64 `json:"account_id"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	// sum of the account entries, or the 2 entries of a transfer
+	Expected int64 `json:"expected"`
+	// account balance, or the entries found for the transfer
+	Actual int64  `json:"actual"`
+	Detail string `json:"detail"`
+}
+
+// ledger checks proving account balances and transfers match their entries
+type ReconciliationRun struct {
+	ID               int64     `json:"id"`
+	AccountsChecked  int64     `json:"accounts_checked"`
+	TransfersChecked int64     `json:"transfers_checked"`
+	IssuesCount      int64     `json:"issues_count"`
+	StartedAt        time.Time `json:"started_at"`
+	FinishedAt       time.Time `json:"finished_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64  `json:"id"`
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	// RRULE such as FREQ=MONTHLY;BYMONTHDAY=1, empty for a one-off transfer
+	Recurrence string `json:"recurrence"`
+	// occurrence being executed
+	ScheduledFor time.Time `json:"scheduled_for"`
+	// when the occurrence is attempted, later than scheduled_for while retrying
+	NextRunAt time.Time    `json:"next_run_at"`
+	EndAt     sql.NullTime `json:"end_at"`
+	Status    string       `json:"status"`
+	// failed attempts of the current occurrence
+	Attempts  int32  `json:"attempts"`
+	LastError string `json:"last_error"`
+	// lease of the worker executing the occurrence
+	ClaimedUntil sql.NullTime `json:"claimed_until"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+type ScheduledTransferRun struct {
+	ID                  int64         `json:"id"`
+	ScheduledTransferID int64         `json:"scheduled_transfer_id"`
+	ScheduledFor        time.Time     `json:"scheduled_for"`
+	Attempt             int32         `json:"attempt"`
+	TransferID          sql.NullInt64 `json:"transfer_id"`
+	// empty when the transfer succeeded
+	Error     string    `json:"error"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Session struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	// hex SHA-256 of the refresh token, the token itself is never stored
+	RefreshTokenHash string    `json:"refresh_token_hash"`
+	UserAgent        string    `json:"user_agent"`
+	ClientIp         string    `json:"client_ip"`
+	IsBlocked        bool      `json:"is_blocked"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// accounts of the bank: its FX position and overdraft interest income in every currency
+type SystemAccount struct {
+	// fx_position takes the side of cross-currency transfers, interest_income is credited the overdraft interest
+	Purpose   string `json:"purpose"`
+	Currency  string `json:"currency"`
+	AccountID int64  `json:"account_id"`
 }
 
 type Transfer struct {
 	ID            int64 `json:"id"`
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
-	// must be positive
+	// must be positive, debited in the source account currency
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// must be positive, credited in the destination account currency
+	ToAmount int64 `json:"to_amount"`
+	// mid-market rate from source to destination currency scaled by 1e6
+	ExchangeRate int64 `json:"exchange_rate"`
+	// spread charged over the converted amount in basis points
+	SpreadBps int32 `json:"spread_bps"`
+}
+
+// transfers from one account submitted together and processed in the background
+type TransferBatch struct {
+	ID            int64  `json:"id"`
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"from_account_id"`
+	// all_or_nothing books every transfer or none, best_effort books those that can be
+	Mode           string `json:"mode"`
+	Status         string `json:"status"`
+	TotalAmount    int64  `json:"total_amount"`
+	ItemsCount     int32  `json:"items_count"`
+	SucceededCount int32  `json:"succeeded_count"`
+	FailedCount    int32  `json:"failed_count"`
+	// lease of the worker processing the batch
+	ClaimedUntil sql.NullTime `json:"claimed_until"`
+	CreatedAt    time.Time    `json:"created_at"`
+	FinishedAt   sql.NullTime `json:"finished_at"`
+}
+
+type TransferBatchItem struct {
+	ID      int64 `json:"id"`
+	BatchID int64 `json:"batch_id"`
+	// index of the transfer in the submitted batch, from 0
+	Position    int32         `json:"position"`
+	ToAccountID int64         `json:"to_account_id"`
+	Amount      int64         `json:"amount"`
+	Status      string        `json:"status"`
+	TransferID  sql.NullInt64 `json:"transfer_id"`
+	// why the transfer was not booked, empty unless it failed
+	Error string `json:"error"`
+}
+
+// outbound velocity limits of an account, or of all the accounts of a user in a currency
+type TransferLimit struct {
+	ID        int64          `json:"id"`
+	AccountID sql.NullInt64  `json:"account_id"`
+	Owner     sql.NullString `json:"owner"`
+	Currency  sql.NullString `json:"currency"`
+	// maximum amount sent per UTC day, null is unlimited
+	DailyAmount sql.NullInt64 `json:"daily_amount"`
+	// maximum amount sent per UTC month, null is unlimited
+	MonthlyAmount sql.NullInt64 `json:"monthly_amount"`
+	// maximum number of transfers sent per UTC day, null is unlimited
+	DailyCount sql.NullInt64 `json:"daily_count"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+// compensating transfers booked by staff to undo a transfer in full or in part
+type TransferReversal struct {
+	ID                 int64 `json:"id"`
+	TransferID         int64 `json:"transfer_id"`
+	ReversalTransferID int64 `json:"reversal_transfer_id"`
+	// returned to the source account, in the currency of the original transfer amount
+	Amount     int64     `json:"amount"`
+	ReversedBy string    `json:"reversed_by"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type User struct {
+	Username          string    `json:"username"`
+	HashedPassword    string    `json:"hashed_password"`
+	FullName          string    `json:"full_name"`
+	Email             string    `json:"email"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	CreatedAt         time.Time `json:"created_at"`
+	Role              string    `json:"role"`
+}
+
+// an outbox event to be posted to a webhook subscription
+type WebhookDelivery struct {
+	ID             int64 `json:"id"`
+	SubscriptionID int64 `json:"subscription_id"`
+	// outbox event delivered, the same event is delivered once per subscription
+	EventID            int64           `json:"event_id"`
+	EventType          string          `json:"event_type"`
+	Payload            json.RawMessage `json:"payload"`
+	Status             string          `json:"status"`
+	Attempts           int32           `json:"attempts"`
+	NextAttemptAt      time.Time       `json:"next_attempt_at"`
+	LastResponseStatus sql.NullInt32   `json:"last_response_status"`
+	LastError          string          `json:"last_error"`
+	// lease of the dispatcher posting the delivery
+	ClaimedUntil sql.NullTime `json:"claimed_until"`
+	CreatedAt    time.Time    `json:"created_at"`
+	DeliveredAt  sql.NullTime `json:"delivered_at"`
+}
+
+type WebhookDeliveryAttempt struct {
+	ID         int64 `json:"id"`
+	DeliveryID int64 `json:"delivery_id"`
+	Attempt    int32 `json:"attempt"`
+	// null when no response was received
+	ResponseStatus sql.NullInt32 `json:"response_status"`
+	// empty when the receiver acknowledged the delivery
+	Error      string    `json:"error"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookSubscription struct {
+	ID         int64    `json:"id"`
+	Owner      string   `json:"owner"`
+	Url        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	// key of the HMAC-SHA256 signature of every delivery
+	Secret string `json:"secret"`
+	Status string `json:"status"`
+	// failed attempts since the last successful one, the subscription is disabled past a threshold
+	ConsecutiveFailures int32        `json:"consecutive_failures"`
+	CreatedAt           time.Time    `json:"created_at"`
+	DisabledAt          sql.NullTime `json:"disabled_at"`
 }
@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"simplebank/util"
+	"testing"
+	"time"
+)
+
+func randomOutboxEvent(ctx context.Context, aggregateType string, aggregateID int64) (OutboxEvent, error) {
+	return testQueries.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     "Tested",
+		Payload:       json.RawMessage(`{}`),
+	})
+}
+
+func TestQueries_ClaimOutboxEvents(t *testing.T) {
+	ctx := context.Background()
+	aggregateType := "test_" + util.RandomString(8)
+
+	created, err := randomOutboxEvent(ctx, aggregateType, 1)
+	require.NoError(t, err)
+	changed, err := randomOutboxEvent(ctx, aggregateType, 1)
+	require.NoError(t, err)
+	other, err := randomOutboxEvent(ctx, aggregateType, 2)
+	require.NoError(t, err)
+	retried, err := randomOutboxEvent(ctx, aggregateType, 3)
+	require.NoError(t, err)
+	heldBack, err := randomOutboxEvent(ctx, aggregateType, 3)
+	require.NoError(t, err)
+	dead, err := randomOutboxEvent(ctx, aggregateType, 4)
+	require.NoError(t, err)
+	released, err := randomOutboxEvent(ctx, aggregateType, 4)
+	require.NoError(t, err)
+
+	_, err = testQueries.MarkOutboxEventDead(ctx, MarkOutboxEventDeadParams{
+		ID:        dead.ID,
+		LastError: "payload rejected",
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.RecordOutboxEventFailure(ctx, RecordOutboxEventFailureParams{
+		ID:            retried.ID,
+		NextAttemptAt: time.Now().Add(time.Hour),
+		LastError:     "broker unavailable",
+	})
+	require.NoError(t, err)
+
+	//Events of other tests may be pending too, they are published along the way
+	claimedIn := make(map[int64]int)
+	for round := 0; round < 10; round++ {
+		claimed, err := testQueries.ClaimOutboxEvents(ctx, ClaimOutboxEventsParams{
+			ClaimedUntil: sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true},
+			Limit:        1000,
+		})
+		require.NoError(t, err)
+
+		for _, event := range claimed {
+			if event.AggregateType == aggregateType {
+				claimedIn[event.ID] = round
+			}
+			_, err := testQueries.MarkOutboxEventPublished(ctx, event.ID)
+			require.NoError(t, err)
+		}
+	}
+
+	require.Contains(t, claimedIn, created.ID)
+	require.Contains(t, claimedIn, changed.ID)
+	require.Contains(t, claimedIn, other.ID)
+	require.Less(t, claimedIn[created.ID], claimedIn[changed.ID])
+
+	//A failed event waits for its next attempt and holds back the later events of its aggregate
+	require.NotContains(t, claimedIn, retried.ID)
+	require.NotContains(t, claimedIn, heldBack.ID)
+
+	//A dead event is never claimed again and releases its aggregate
+	require.NotContains(t, claimedIn, dead.ID)
+	require.Contains(t, claimedIn, released.ID)
+}
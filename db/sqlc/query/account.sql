@@ -24,6 +24,13 @@ FROM accounts
 ORDER BY id
 LIMIT $1 OFFSET $2;
 
+-- name: ListAccountsByOwner :many
+SELECT *
+FROM accounts
+WHERE owner = $1
+ORDER BY id
+LIMIT $2 OFFSET $3;
+
 -- name: UpdateAccount :one
 UPDATE accounts
 SET balance = $1
@@ -36,7 +43,65 @@ SET balance = balance + sqlc.arg(amount)
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
--- name: DeleteAccount :exec
+-- name: DeleteAccount :execrows
 DELETE
+FROM accounts a
+WHERE a.id = $1
+  AND NOT EXISTS(SELECT 1 FROM entries e WHERE e.account_id = a.id)
+  AND NOT EXISTS(SELECT 1 FROM holds h WHERE h.account_id = a.id OR h.to_account_id = a.id)
+  AND NOT EXISTS(SELECT 1
+                 FROM scheduled_transfers st
+                 WHERE st.from_account_id = a.id
+                    OR st.to_account_id = a.id);
+
+-- name: ListAccountsByOwnerAfter :many
+SELECT *
 FROM accounts
-WHERE id = $1;
\ No newline at end of file
+WHERE owner = sqlc.arg(owner)
+  AND (sqlc.narg(cursor_created_at)::timestamp IS NULL
+    OR (created_at, id) > (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::bigint))
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit');
+
+-- name: ListAccountsByOwnerBefore :many
+SELECT *
+FROM accounts
+WHERE owner = sqlc.arg(owner)
+  AND (created_at, id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: GetAccountBalanceAt :one
+SELECT (a.balance - COALESCE((SELECT SUM(e.amount)
+                              FROM entries e
+                              WHERE e.account_id = a.id
+                                AND e.created_at >= sqlc.arg(at)), 0))::bigint AS balance
+FROM accounts a
+WHERE a.id = sqlc.arg(account_id);
+
+-- name: AddAccountHeldBalance :one
+UPDATE accounts
+SET held_balance = held_balance + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET status = sqlc.arg(status)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateAccountOverdraftLimit :one
+UPDATE accounts
+SET overdraft_limit = sqlc.arg(overdraft_limit)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ListOverdraftAccountIDs :many
+SELECT id
+FROM accounts
+WHERE (overdraft_limit > 0 OR balance < 0)
+  AND id > sqlc.arg(after_id)
+  AND NOT EXISTS(SELECT 1 FROM system_accounts s WHERE s.account_id = accounts.id)
+ORDER BY id
+LIMIT sqlc.arg('limit');
@@ -1,6 +1,6 @@
 -- name: CreateEntry :one
-INSERT INTO entries(account_id, amount)
-VALUES ($1, $2)
+INSERT INTO entries(account_id, amount, transfer_id, journal_id)
+VALUES ($1, $2, $3, $4)
 RETURNING *;
 
 -- name: GetEntry :one
@@ -14,3 +14,65 @@ SELECT *
 FROM entries
 ORDER BY id
 LIMIT $1 OFFSET $2;
+
+-- name: ListEntriesByAccount :many
+SELECT id, account_id, amount, created_at, running_balance
+FROM (SELECT e.id,
+             e.account_id,
+             e.amount,
+             e.created_at,
+             (a.balance - SUM(e.amount) OVER (ORDER BY e.id DESC) + e.amount)::bigint AS running_balance
+      FROM entries e
+               JOIN accounts a ON a.id = e.account_id
+      WHERE e.account_id = sqlc.arg(account_id)) AS account_entries
+WHERE (sqlc.narg(from_time)::timestamp IS NULL OR created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamp IS NULL OR created_at < sqlc.narg(to_time))
+ORDER BY id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListEntriesByAccountAfter :many
+SELECT id, account_id, amount, created_at, running_balance
+FROM (SELECT e.id,
+             e.account_id,
+             e.amount,
+             e.created_at,
+             (a.balance - SUM(e.amount) OVER (ORDER BY e.created_at DESC, e.id DESC) + e.amount)::bigint AS running_balance
+      FROM entries e
+               JOIN accounts a ON a.id = e.account_id
+      WHERE e.account_id = sqlc.arg(account_id)) AS account_entries
+WHERE (sqlc.narg(from_time)::timestamp IS NULL OR created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamp IS NULL OR created_at < sqlc.narg(to_time))
+  AND (sqlc.narg(cursor_created_at)::timestamp IS NULL
+    OR (created_at, id) > (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::bigint))
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit');
+
+-- name: ListEntriesByAccountBefore :many
+SELECT id, account_id, amount, created_at, running_balance
+FROM (SELECT e.id,
+             e.account_id,
+             e.amount,
+             e.created_at,
+             (a.balance - SUM(e.amount) OVER (ORDER BY e.created_at DESC, e.id DESC) + e.amount)::bigint AS running_balance
+      FROM entries e
+               JOIN accounts a ON a.id = e.account_id
+      WHERE e.account_id = sqlc.arg(account_id)) AS account_entries
+WHERE (sqlc.narg(from_time)::timestamp IS NULL OR created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamp IS NULL OR created_at < sqlc.narg(to_time))
+  AND (created_at, id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListStatementEntries :many
+SELECT e.id,
+       e.amount,
+       e.created_at,
+       e.transfer_id,
+       t.from_account_id AS transfer_from_account_id,
+       t.to_account_id   AS transfer_to_account_id
+FROM entries e
+         LEFT JOIN transfers t ON t.id = e.transfer_id
+WHERE e.account_id = sqlc.arg(account_id)
+  AND e.created_at >= sqlc.arg(from_time)
+  AND e.created_at < sqlc.arg(to_time)
+ORDER BY e.created_at, e.id;
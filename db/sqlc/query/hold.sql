@@ -0,0 +1,49 @@
+-- name: CreateHold :one
+INSERT INTO holds(account_id,
+                  to_account_id,
+                  amount,
+                  expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetHold :one
+SELECT *
+FROM holds
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT *
+FROM holds
+WHERE id = $1
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: UpdateHoldStatus :one
+UPDATE holds
+SET status          = sqlc.arg(status),
+    captured_amount = sqlc.arg(captured_amount),
+    transfer_id     = sqlc.narg(transfer_id)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ExpireAccountHolds :one
+WITH expired AS (
+    UPDATE holds
+        SET status = 'expired'
+        WHERE holds.account_id = sqlc.arg(account_id)
+            AND status = 'active'
+            AND expires_at <= now()
+        RETURNING amount)
+UPDATE accounts
+SET held_balance = held_balance - (SELECT COALESCE(SUM(amount), 0) FROM expired)
+WHERE id = sqlc.arg(account_id)
+RETURNING *;
+
+-- name: ListAccountsWithExpiredHolds :many
+SELECT DISTINCT account_id
+FROM holds
+WHERE status = 'active'
+  AND expires_at <= now()
+ORDER BY account_id
+LIMIT $1;
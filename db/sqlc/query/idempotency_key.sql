@@ -0,0 +1,38 @@
+-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys(username,
+                             key,
+                             request_hash,
+                             expires_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT DO NOTHING
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT *
+FROM idempotency_keys
+WHERE username = $1
+  AND key = $2
+LIMIT 1;
+
+-- name: UpdateIdempotencyKeyResponse :one
+UPDATE idempotency_keys
+SET response_body = sqlc.arg(response_body)
+WHERE username = sqlc.arg(username)
+  AND key = sqlc.arg(key)
+RETURNING *;
+
+-- name: DeleteExpiredIdempotencyKey :exec
+DELETE
+FROM idempotency_keys
+WHERE username = $1
+  AND key = $2
+  AND expires_at < now();
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE
+FROM idempotency_keys
+WHERE (username, key) IN (SELECT username, key
+                          FROM idempotency_keys
+                          WHERE expires_at < now()
+                          ORDER BY expires_at
+                          LIMIT $1 FOR UPDATE SKIP LOCKED);
@@ -0,0 +1,9 @@
+-- name: CreateJournalTransaction :one
+INSERT INTO journal_transactions DEFAULT VALUES
+RETURNING *;
+
+-- name: ListEntriesByJournal :many
+SELECT *
+FROM entries
+WHERE journal_id = $1
+ORDER BY id;
@@ -0,0 +1,56 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox_events(aggregate_type, aggregate_id, event_type, payload)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ClaimOutboxEvents :many
+UPDATE outbox_events
+SET claimed_until = sqlc.arg(claimed_until)
+WHERE id IN (SELECT e.id
+             FROM outbox_events e
+             WHERE e.published_at IS NULL
+               AND e.dead_at IS NULL
+               AND e.next_attempt_at <= now()
+               AND (e.claimed_until IS NULL OR e.claimed_until < now())
+               AND NOT EXISTS(SELECT 1
+                              FROM outbox_events earlier
+                              WHERE earlier.aggregate_type = e.aggregate_type
+                                AND earlier.aggregate_id = e.aggregate_id
+                                AND earlier.id < e.id
+                                AND earlier.published_at IS NULL
+                                AND earlier.dead_at IS NULL)
+             ORDER BY e.id
+             LIMIT sqlc.arg('limit') FOR UPDATE SKIP LOCKED)
+RETURNING *;
+
+-- name: MarkOutboxEventPublished :one
+UPDATE outbox_events
+SET published_at  = now(),
+    claimed_until = NULL
+WHERE id = $1
+RETURNING *;
+
+-- name: MarkOutboxEventDead :one
+UPDATE outbox_events
+SET attempts      = attempts + 1,
+    last_error    = $2,
+    dead_at       = now(),
+    claimed_until = NULL
+WHERE id = $1
+RETURNING *;
+
+-- name: RecordOutboxEventFailure :one
+UPDATE outbox_events
+SET attempts        = attempts + 1,
+    next_attempt_at = $2,
+    last_error      = $3,
+    claimed_until   = NULL
+WHERE id = $1
+RETURNING *;
+
+-- name: ListOutboxEventsByAggregate :many
+SELECT *
+FROM outbox_events
+WHERE aggregate_type = $1
+  AND aggregate_id = $2
+ORDER BY id;
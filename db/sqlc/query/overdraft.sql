@@ -0,0 +1,31 @@
+-- name: CreateOverdraftLimitChange :one
+INSERT INTO overdraft_limit_changes(account_id,
+                                    old_limit,
+                                    new_limit,
+                                    changed_by,
+                                    reason)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListOverdraftLimitChanges :many
+SELECT *
+FROM overdraft_limit_changes
+WHERE account_id = $1
+ORDER BY id;
+
+-- name: CreateOverdraftInterestCharge :one
+INSERT INTO overdraft_interest_charges(account_id,
+                                       day,
+                                       balance,
+                                       rate_bps,
+                                       amount,
+                                       entry_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetOverdraftInterestCharge :one
+SELECT *
+FROM overdraft_interest_charges
+WHERE account_id = $1
+  AND day = $2
+LIMIT 1;
@@ -0,0 +1,67 @@
+-- name: ListAccountDrifts :many
+SELECT a.id,
+       a.balance,
+       COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts a
+         LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id;
+
+-- name: ListTransferEntryMismatches :many
+SELECT t.id,
+       COUNT(e.id)                                                                      AS entries_count,
+       COUNT(e.id) FILTER (WHERE e.account_id = t.from_account_id AND e.amount = -t.amount) AS debits_count,
+       COUNT(e.id) FILTER (WHERE e.account_id = t.to_account_id AND e.amount = t.to_amount) AS credits_count
+FROM transfers t
+         LEFT JOIN entries e ON e.transfer_id = t.id
+GROUP BY t.id
+HAVING COUNT(e.id) <> 2
+    OR COUNT(e.id) FILTER (WHERE e.account_id = t.from_account_id AND e.amount = -t.amount) <> 1
+    OR COUNT(e.id) FILTER (WHERE e.account_id = t.to_account_id AND e.amount = t.to_amount) <> 1
+ORDER BY t.id;
+
+-- name: CountAccounts :one
+SELECT COUNT(*)
+FROM accounts;
+
+-- name: CountTransfers :one
+SELECT COUNT(*)
+FROM transfers;
+
+-- name: CreateReconciliationRun :one
+INSERT INTO reconciliation_runs(accounts_checked,
+                                transfers_checked,
+                                issues_count,
+                                started_at)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: CreateReconciliationIssue :one
+INSERT INTO reconciliation_issues(run_id,
+                                  kind,
+                                  account_id,
+                                  transfer_id,
+                                  expected,
+                                  actual,
+                                  detail)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetReconciliationRun :one
+SELECT *
+FROM reconciliation_runs
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListReconciliationRuns :many
+SELECT *
+FROM reconciliation_runs
+ORDER BY id DESC
+LIMIT $1;
+
+-- name: ListReconciliationIssues :many
+SELECT *
+FROM reconciliation_issues
+WHERE run_id = $1
+ORDER BY id;
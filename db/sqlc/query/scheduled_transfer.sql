@@ -0,0 +1,70 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers(owner,
+                                from_account_id,
+                                to_account_id,
+                                amount,
+                                recurrence,
+                                scheduled_for,
+                                next_run_at,
+                                end_at)
+VALUES (sqlc.arg(owner), sqlc.arg(from_account_id), sqlc.arg(to_account_id), sqlc.arg(amount),
+        sqlc.arg(recurrence), sqlc.arg(scheduled_for), sqlc.arg(scheduled_for), sqlc.narg(end_at))
+RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT *
+FROM scheduled_transfers
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListScheduledTransfersByOwner :many
+SELECT *
+FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY id;
+
+-- name: UpdateScheduledTransfer :one
+UPDATE scheduled_transfers
+SET amount = COALESCE(sqlc.narg(amount), amount),
+    end_at = COALESCE(sqlc.narg(end_at), end_at),
+    status = COALESCE(sqlc.narg(status), status)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ClaimDueScheduledTransfers :many
+UPDATE scheduled_transfers
+SET claimed_until = sqlc.arg(claimed_until)
+WHERE id IN (SELECT id
+             FROM scheduled_transfers
+             WHERE status = 'active'
+               AND next_run_at <= now()
+               AND (claimed_until IS NULL OR claimed_until < now())
+             ORDER BY next_run_at
+             LIMIT sqlc.arg('limit') FOR UPDATE SKIP LOCKED)
+RETURNING *;
+
+-- name: UpdateScheduledTransferRunState :one
+UPDATE scheduled_transfers
+SET scheduled_for = sqlc.arg(scheduled_for),
+    next_run_at   = sqlc.arg(next_run_at),
+    status        = CASE WHEN status = 'active' THEN sqlc.arg(status)::varchar ELSE status END,
+    attempts      = sqlc.arg(attempts),
+    last_error    = sqlc.arg(last_error),
+    claimed_until = NULL
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CreateScheduledTransferRun :one
+INSERT INTO scheduled_transfer_runs(scheduled_transfer_id,
+                                    scheduled_for,
+                                    attempt,
+                                    transfer_id,
+                                    error)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListScheduledTransferRuns :many
+SELECT *
+FROM scheduled_transfer_runs
+WHERE scheduled_transfer_id = $1
+ORDER BY id;
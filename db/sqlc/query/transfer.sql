@@ -1,6 +1,6 @@
 -- name: CreateTransfer :one
-INSERT INTO transfers(from_account_id, to_account_id, amount)
-VALUES ($1, $2, $3)
+INSERT INTO transfers(from_account_id, to_account_id, amount, to_amount, exchange_rate, spread_bps)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
 -- name: GetTransfer :one
@@ -9,8 +9,111 @@ FROM transfers
 WHERE id = $1
 LIMIT 1;
 
+-- name: GetTransferForUpdate :one
+SELECT *
+FROM transfers
+WHERE id = $1
+LIMIT 1
+FOR NO KEY UPDATE;
+
 -- name: ListTransfers :many
 SELECT *
 FROM transfers
 ORDER BY id
 LIMIT $1 OFFSET $2;
+
+-- name: SearchTransfers :many
+SELECT t.*
+FROM transfers t
+WHERE (t.from_account_id IN (SELECT id FROM accounts WHERE owner = sqlc.arg(owner))
+    OR t.to_account_id IN (SELECT id FROM accounts WHERE owner = sqlc.arg(owner)))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR t.amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR t.amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(from_time)::timestamp IS NULL OR t.created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamp IS NULL OR t.created_at < sqlc.narg(to_time))
+ORDER BY t.id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: SearchTransfersAfter :many
+SELECT t.*
+FROM transfers t
+WHERE (t.from_account_id IN (SELECT id FROM accounts WHERE owner = sqlc.arg(owner))
+    OR t.to_account_id IN (SELECT id FROM accounts WHERE owner = sqlc.arg(owner)))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR t.amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR t.amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(from_time)::timestamp IS NULL OR t.created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamp IS NULL OR t.created_at < sqlc.narg(to_time))
+  AND (sqlc.narg(cursor_created_at)::timestamp IS NULL
+    OR (t.created_at, t.id) > (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::bigint))
+ORDER BY t.created_at, t.id
+LIMIT sqlc.arg('limit');
+
+-- name: SearchTransfersBefore :many
+SELECT t.*
+FROM transfers t
+WHERE (t.from_account_id IN (SELECT id FROM accounts WHERE owner = sqlc.arg(owner))
+    OR t.to_account_id IN (SELECT id FROM accounts WHERE owner = sqlc.arg(owner)))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR t.amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR t.amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(from_time)::timestamp IS NULL OR t.created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamp IS NULL OR t.created_at < sqlc.narg(to_time))
+  AND (t.created_at, t.id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::bigint)
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: SearchAccountTransfers :many
+SELECT t.*
+FROM transfers t
+WHERE (t.from_account_id IN (SELECT id FROM accounts WHERE owner = sqlc.arg(owner))
+    OR t.to_account_id IN (SELECT id FROM accounts WHERE owner = sqlc.arg(owner)))
+  AND ((sqlc.arg(outgoing)::bool AND t.from_account_id = sqlc.arg(account_id)
+    AND (sqlc.narg(counterparty_id)::bigint IS NULL OR t.to_account_id = sqlc.narg(counterparty_id)))
+    OR (sqlc.arg(incoming)::bool AND t.to_account_id = sqlc.arg(account_id)
+        AND (sqlc.narg(counterparty_id)::bigint IS NULL OR t.from_account_id = sqlc.narg(counterparty_id))))
+  AND (sqlc.narg(from_account_id)::bigint IS NULL OR t.from_account_id = sqlc.narg(from_account_id))
+  AND (sqlc.narg(to_account_id)::bigint IS NULL OR t.to_account_id = sqlc.narg(to_account_id))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR t.amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR t.amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(from_time)::timestamp IS NULL OR t.created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamp IS NULL OR t.created_at < sqlc.narg(to_time))
+ORDER BY t.id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: SearchAccountTransfersAfter :many
+SELECT t.*
+FROM transfers t
+WHERE (t.from_account_id IN (SELECT id FROM accounts WHERE owner = sqlc.arg(owner))
+    OR t.to_account_id IN (SELECT id FROM accounts WHERE owner = sqlc.arg(owner)))
+  AND ((sqlc.arg(outgoing)::bool AND t.from_account_id = sqlc.arg(account_id)
+    AND (sqlc.narg(counterparty_id)::bigint IS NULL OR t.to_account_id = sqlc.narg(counterparty_id)))
+    OR (sqlc.arg(incoming)::bool AND t.to_account_id = sqlc.arg(account_id)
+        AND (sqlc.narg(counterparty_id)::bigint IS NULL OR t.from_account_id = sqlc.narg(counterparty_id))))
+  AND (sqlc.narg(from_account_id)::bigint IS NULL OR t.from_account_id = sqlc.narg(from_account_id))
+  AND (sqlc.narg(to_account_id)::bigint IS NULL OR t.to_account_id = sqlc.narg(to_account_id))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR t.amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR t.amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(from_time)::timestamp IS NULL OR t.created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamp IS NULL OR t.created_at < sqlc.narg(to_time))
+  AND (sqlc.narg(cursor_created_at)::timestamp IS NULL
+    OR (t.created_at, t.id) > (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::bigint))
+ORDER BY t.created_at, t.id
+LIMIT sqlc.arg('limit');
+
+-- name: SearchAccountTransfersBefore :many
+SELECT t.*
+FROM transfers t
+WHERE (t.from_account_id IN (SELECT id FROM accounts WHERE owner = sqlc.arg(owner))
+    OR t.to_account_id IN (SELECT id FROM accounts WHERE owner = sqlc.arg(owner)))
+  AND ((sqlc.arg(outgoing)::bool AND t.from_account_id = sqlc.arg(account_id)
+    AND (sqlc.narg(counterparty_id)::bigint IS NULL OR t.to_account_id = sqlc.narg(counterparty_id)))
+    OR (sqlc.arg(incoming)::bool AND t.to_account_id = sqlc.arg(account_id)
+        AND (sqlc.narg(counterparty_id)::bigint IS NULL OR t.from_account_id = sqlc.narg(counterparty_id))))
+  AND (sqlc.narg(from_account_id)::bigint IS NULL OR t.from_account_id = sqlc.narg(from_account_id))
+  AND (sqlc.narg(to_account_id)::bigint IS NULL OR t.to_account_id = sqlc.narg(to_account_id))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR t.amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR t.amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(from_time)::timestamp IS NULL OR t.created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamp IS NULL OR t.created_at < sqlc.narg(to_time))
+  AND (t.created_at, t.id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::bigint)
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT sqlc.arg('limit');
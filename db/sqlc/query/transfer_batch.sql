@@ -0,0 +1,65 @@
+-- name: CreateTransferBatch :one
+INSERT INTO transfer_batches(owner, from_account_id, mode, total_amount, items_count)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetTransferBatch :one
+SELECT *
+FROM transfer_batches
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetTransferBatchForUpdate :one
+SELECT *
+FROM transfer_batches
+WHERE id = $1
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ClaimTransferBatches :many
+UPDATE transfer_batches
+SET status        = 'processing',
+    claimed_until = sqlc.arg(claimed_until)
+WHERE id IN (SELECT id
+             FROM transfer_batches
+             WHERE status IN ('pending', 'processing')
+               AND (claimed_until IS NULL OR claimed_until < now())
+             ORDER BY id
+             LIMIT sqlc.arg('limit') FOR UPDATE SKIP LOCKED)
+RETURNING *;
+
+-- name: FinishTransferBatch :one
+UPDATE transfer_batches
+SET status          = $2,
+    succeeded_count = $3,
+    failed_count    = $4,
+    claimed_until   = NULL,
+    finished_at     = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateTransferBatchItem :one
+INSERT INTO transfer_batch_items(batch_id, position, to_account_id, amount)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetTransferBatchItemForUpdate :one
+SELECT *
+FROM transfer_batch_items
+WHERE id = $1
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListTransferBatchItems :many
+SELECT *
+FROM transfer_batch_items
+WHERE batch_id = $1
+ORDER BY position;
+
+-- name: UpdateTransferBatchItem :one
+UPDATE transfer_batch_items
+SET status      = $2,
+    transfer_id = $3,
+    error       = $4
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,68 @@
+-- name: UpsertAccountTransferLimit :one
+INSERT INTO transfer_limits(account_id,
+                            daily_amount,
+                            monthly_amount,
+                            daily_count)
+VALUES (sqlc.arg(account_id), sqlc.narg(daily_amount), sqlc.narg(monthly_amount), sqlc.narg(daily_count))
+ON CONFLICT (account_id) WHERE account_id IS NOT NULL
+    DO UPDATE SET daily_amount   = excluded.daily_amount,
+                  monthly_amount = excluded.monthly_amount,
+                  daily_count    = excluded.daily_count,
+                  updated_at     = now()
+RETURNING *;
+
+-- name: UpsertUserTransferLimit :one
+INSERT INTO transfer_limits(owner,
+                            currency,
+                            daily_amount,
+                            monthly_amount,
+                            daily_count)
+VALUES (sqlc.arg(owner), sqlc.arg(currency), sqlc.narg(daily_amount), sqlc.narg(monthly_amount),
+        sqlc.narg(daily_count))
+ON CONFLICT (owner, currency) WHERE owner IS NOT NULL
+    DO UPDATE SET daily_amount   = excluded.daily_amount,
+                  monthly_amount = excluded.monthly_amount,
+                  daily_count    = excluded.daily_count,
+                  updated_at     = now()
+RETURNING *;
+
+-- name: GetAccountTransferLimit :one
+SELECT *
+FROM transfer_limits
+WHERE account_id = $1
+LIMIT 1;
+
+-- name: GetUserTransferLimit :one
+SELECT *
+FROM transfer_limits
+WHERE owner = $1
+  AND currency = $2
+LIMIT 1;
+
+-- name: GetUserTransferLimitForUpdate :one
+SELECT *
+FROM transfer_limits
+WHERE owner = $1
+  AND currency = $2
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: GetAccountTransferUsage :one
+SELECT COALESCE(SUM(amount) FILTER (WHERE created_at >= sqlc.arg(day_start)), 0)::bigint AS daily_amount,
+       COUNT(*) FILTER (WHERE created_at >= sqlc.arg(day_start))                         AS daily_count,
+       COALESCE(SUM(amount), 0)::bigint                                                   AS monthly_amount
+FROM transfers
+WHERE from_account_id = sqlc.arg(account_id)
+  AND created_at >= sqlc.arg(month_start)
+  AND NOT EXISTS(SELECT 1 FROM transfer_reversals r WHERE r.reversal_transfer_id = transfers.id);
+
+-- name: GetUserTransferUsage :one
+SELECT COALESCE(SUM(t.amount) FILTER (WHERE t.created_at >= sqlc.arg(day_start)), 0)::bigint AS daily_amount,
+       COUNT(*) FILTER (WHERE t.created_at >= sqlc.arg(day_start))                           AS daily_count,
+       COALESCE(SUM(t.amount), 0)::bigint                                                     AS monthly_amount
+FROM transfers t
+         JOIN accounts a ON a.id = t.from_account_id
+WHERE a.owner = sqlc.arg(owner)
+  AND a.currency = sqlc.arg(currency)
+  AND t.created_at >= sqlc.arg(month_start)
+  AND NOT EXISTS(SELECT 1 FROM transfer_reversals r WHERE r.reversal_transfer_id = t.id);
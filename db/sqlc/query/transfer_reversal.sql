@@ -0,0 +1,21 @@
+-- name: CreateTransferReversal :one
+INSERT INTO transfer_reversals(transfer_id,
+                               reversal_transfer_id,
+                               amount,
+                               reversed_by,
+                               reason)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetTransferReversalStatus :one
+SELECT COALESCE(SUM(amount) FILTER (WHERE transfer_id = sqlc.arg(id)), 0)::bigint          AS reversed_amount,
+       COALESCE(MAX(transfer_id) FILTER (WHERE reversal_transfer_id = sqlc.arg(id)), 0)::bigint AS reversal_of
+FROM transfer_reversals
+WHERE transfer_id = sqlc.arg(id)
+   OR reversal_transfer_id = sqlc.arg(id);
+
+-- name: ListTransferReversals :many
+SELECT *
+FROM transfer_reversals
+WHERE transfer_id = $1
+ORDER BY id;
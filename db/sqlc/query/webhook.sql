@@ -0,0 +1,113 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions(owner, url, event_types, secret)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetWebhookSubscription :one
+SELECT *
+FROM webhook_subscriptions
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListWebhookSubscriptionsByOwner :many
+SELECT *
+FROM webhook_subscriptions
+WHERE owner = $1
+ORDER BY id;
+
+-- name: UpdateWebhookSubscription :one
+UPDATE webhook_subscriptions
+SET url                  = COALESCE(sqlc.narg(url), url),
+    event_types          = COALESCE(sqlc.narg(event_types), event_types),
+    status               = COALESCE(sqlc.narg(status), status),
+    consecutive_failures = CASE WHEN sqlc.narg(status) = 'active' THEN 0 ELSE consecutive_failures END,
+    disabled_at          = CASE
+                               WHEN sqlc.narg(status) = 'active' THEN NULL
+                               WHEN sqlc.narg(status) = 'disabled' AND status = 'active' THEN now()
+                               ELSE disabled_at END
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteWebhookSubscription :exec
+DELETE
+FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: ResetWebhookSubscriptionFailures :one
+UPDATE webhook_subscriptions
+SET consecutive_failures = 0
+WHERE id = $1
+RETURNING *;
+
+-- name: RecordWebhookSubscriptionFailure :one
+UPDATE webhook_subscriptions
+SET consecutive_failures = consecutive_failures + 1,
+    status               = CASE
+                               WHEN consecutive_failures + 1 >= sqlc.arg(disable_after)::integer THEN 'disabled'
+                               ELSE status END,
+    disabled_at          = CASE
+                               WHEN consecutive_failures + 1 >= sqlc.arg(disable_after)::integer AND status = 'active'
+                                   THEN now()
+                               ELSE disabled_at END
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: EnqueueWebhookDeliveries :execrows
+INSERT INTO webhook_deliveries(subscription_id, event_id, event_type, payload)
+SELECT s.id, sqlc.arg(event_id), sqlc.arg(event_type), sqlc.arg(payload)
+FROM webhook_subscriptions s
+WHERE s.owner = ANY (sqlc.arg(owners)::varchar[])
+  AND s.status = 'active'
+  AND sqlc.arg(event_type) = ANY (s.event_types)
+ON CONFLICT (subscription_id, event_id) DO NOTHING;
+
+-- name: ClaimWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET claimed_until = sqlc.arg(claimed_until)
+WHERE id IN (SELECT d.id
+             FROM webhook_deliveries d
+                      JOIN webhook_subscriptions s ON s.id = d.subscription_id
+             WHERE d.status = 'pending'
+               AND s.status = 'active'
+               AND d.next_attempt_at <= now()
+               AND (d.claimed_until IS NULL OR d.claimed_until < now())
+             ORDER BY d.next_attempt_at, d.id
+             LIMIT sqlc.arg('limit') FOR UPDATE OF d SKIP LOCKED)
+RETURNING *;
+
+-- name: GetWebhookDelivery :one
+SELECT *
+FROM webhook_deliveries
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListWebhookDeliveries :many
+SELECT *
+FROM webhook_deliveries
+WHERE subscription_id = sqlc.arg(subscription_id)
+  AND (sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: UpdateWebhookDeliveryAttempt :one
+UPDATE webhook_deliveries
+SET status               = sqlc.arg(status),
+    attempts             = sqlc.arg(attempts),
+    next_attempt_at      = sqlc.arg(next_attempt_at),
+    last_response_status = sqlc.narg(last_response_status),
+    last_error           = sqlc.arg(last_error),
+    delivered_at         = sqlc.narg(delivered_at),
+    claimed_until        = NULL
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CreateWebhookDeliveryAttempt :one
+INSERT INTO webhook_delivery_attempts(delivery_id, attempt, response_status, error, duration_ms)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListWebhookDeliveryAttempts :many
+SELECT *
+FROM webhook_delivery_attempts
+WHERE delivery_id = $1
+ORDER BY id;
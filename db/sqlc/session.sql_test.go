@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"simplebank/util"
+	"testing"
+	"time"
+)
+
+func randomSession(ctx context.Context) (Session, error) {
+	user, err := randomUser(ctx)
+	if err != nil {
+		return Session{}, err
+	}
+
+	return testQueries.CreateSession(ctx, CreateSessionParams{
+		ID:               uuid.New(),
+		Username:         user.Username,
+		RefreshTokenHash: util.RandomString(64),
+		UserAgent:        "Go-http-client/1.1",
+		ClientIp:         "127.0.0.1",
+		IsBlocked:        false,
+		ExpiresAt:        time.Now().Add(time.Hour),
+	})
+}
+
+func TestQueries_CreateSession(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	session, err := randomSession(ctx)
+	require.NoError(t, err)
+	require.NotEqual(t, uuid.Nil, session.ID)
+	require.NotEmpty(t, session.Username)
+	require.False(t, session.IsBlocked)
+	require.NotZero(t, session.CreatedAt)
+}
+
+func TestQueries_GetSession(t *testing.T) {
+	tests := []struct {
+		name        string
+		testingFunc func(t *testing.T)
+	}{
+		{
+			name: "When session exists",
+			testingFunc: func(t *testing.T) {
+				ctx := context.Background()
+
+				session, err := randomSession(ctx)
+				require.NoError(t, err)
+
+				s, err := testQueries.GetSession(ctx, session.ID)
+				require.NoError(t, err)
+				require.Equal(t, session.ID, s.ID)
+				require.Equal(t, session.RefreshTokenHash, s.RefreshTokenHash)
+				require.WithinDuration(t, session.ExpiresAt, s.ExpiresAt, time.Second)
+			},
+		},
+		{
+			name: "When session does not exist",
+			testingFunc: func(t *testing.T) {
+				_, err := testQueries.GetSession(context.Background(), uuid.New())
+				require.Error(t, err)
+				require.Equal(t, sql.ErrNoRows, err)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.testingFunc(t)
+		})
+	}
+}
+
+func TestQueries_BlockSession(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	session, err := randomSession(ctx)
+	require.NoError(t, err)
+
+	blocked, err := testQueries.BlockSession(ctx, session.ID)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+
+	s, err := testQueries.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	require.True(t, s.IsBlocked)
+}
@@ -3,7 +3,27 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/lib/pq"
+)
+
+var (
+	//ErrInvalidAmount is returned when a transfer amount is not positive
+	ErrInvalidAmount = errors.New("amount must be positive")
+	//ErrInsufficientFunds is returned when a transfer would take the source account past its overdraft limit
+	ErrInsufficientFunds = errors.New("insufficient funds")
+)
+
+const (
+	//sameCurrencyRate is the exchange rate recorded on same currency transfers, scaled by 1e6
+	sameCurrencyRate = 1_000_000
+
+	checkViolation              = "23514"
+	balanceWithinOverdraftCheck = "accounts_balance_within_overdraft"
+	availableBalanceCheck       = "accounts_available_balance_within_overdraft"
+	transferAmountPositiveCheck = "transfers_amount_positive"
+	transferToAmountPositive    = "transfers_to_amount_positive"
 )
 
 type (
@@ -11,6 +31,25 @@ type (
 	Store interface {
 		Querier
 		TransferTx(ctx context.Context, params TransferTxParams) (result TransferTxResult, err error)
+		IdempotentTransferTx(ctx context.Context, params IdempotentTransferTxParams) (result IdempotentTransferTxResult, err error)
+		PlaceHold(ctx context.Context, params PlaceHoldParams) (result PlaceHoldResult, err error)
+		CaptureHold(ctx context.Context, params CaptureHoldParams) (result CaptureHoldResult, err error)
+		ReleaseHold(ctx context.Context, id int64) (result ReleaseHoldResult, err error)
+		ExpireHolds(ctx context.Context, limit int32) (int, error)
+		RecordScheduledTransferRun(ctx context.Context, params RecordScheduledTransferRunParams) (result RecordScheduledTransferRunResult, err error)
+		FreezeAccount(ctx context.Context, id int64) (Account, error)
+		UnfreezeAccount(ctx context.Context, id int64) (Account, error)
+		CloseAccount(ctx context.Context, id int64) (Account, error)
+		CreateAccountTx(ctx context.Context, params CreateAccountParams) (account Account, err error)
+		SetOverdraftLimit(ctx context.Context, params SetOverdraftLimitParams) (result SetOverdraftLimitResult, err error)
+		ChargeOverdraftInterest(ctx context.Context, params ChargeOverdraftInterestParams) (result ChargeOverdraftInterestResult, err error)
+		GetTransferLimitsUsage(ctx context.Context, account Account) (AccountTransferLimitsUsage, error)
+		PostJournal(ctx context.Context, postings []Posting) (result PostJournalResult, err error)
+		ReverseTransfer(ctx context.Context, params ReverseTransferParams) (result ReverseTransferResult, err error)
+		Reconcile(ctx context.Context) (result ReconcileResult, err error)
+		SubmitTransferBatch(ctx context.Context, params SubmitTransferBatchParams) (result TransferBatchResult, err error)
+		ProcessTransferBatch(ctx context.Context, id int64) (result TransferBatchResult, err error)
+		RecordWebhookAttempt(ctx context.Context, params RecordWebhookAttemptParams) (result RecordWebhookAttemptResult, err error)
 	}
 
 	//SQLStore provides all functions to execute SQL queries and transactions
@@ -18,11 +57,18 @@ type (
 		*Queries
 		db *sql.DB
 	}
-	//TransferTxParams contains the input parameters of the transfer transaction
+	//TransferTxParams contains the input parameters of the transfer transaction.
+	// ToAmount, ExchangeRate and SpreadBps are only set for cross-currency transfers,
+	// when left zero the destination is credited the same Amount at a 1:1 rate.
 	TransferTxParams struct {
 		FromAccountID int64 `json:"from_account_id"`
 		ToAccountID   int64 `json:"to_account_id"`
 		Amount        int64 `json:"amount"`
+		ToAmount      int64 `json:"to_amount"`
+		ExchangeRate  int64 `json:"exchange_rate"`
+		SpreadBps     int32 `json:"spread_bps"`
+		//allowFrozenFrom lets the bank debit a frozen source account, e.g. to reverse a transfer into it
+		allowFrozenFrom bool
 	}
 	//TransferTxResult is the result of the transfer transaction
 	TransferTxResult struct {
@@ -32,13 +78,6 @@ type (
 		FromEntry   Entry   `json:"from_entry"`
 		ToEntry     Entry   `json:"to_entry"`
 	}
-
-	updateBalanceRequest struct {
-		fromId     int64
-		fromAmount int64
-		toId       int64
-		toAmount   int64
-	}
 )
 
 //NewStore creates a new SQLStore
@@ -50,7 +89,12 @@ func NewStore(db *sql.DB) SQLStore {
 }
 
 func (s SQLStore) execTx(ctx context.Context, fn func(queries *Queries) error) error {
-	tx, err := s.db.BeginTx(ctx, nil)
+	return s.execTxOptions(ctx, nil, fn)
+}
+
+//execTxOptions runs fn in a transaction started with opts, nil opts use the driver defaults
+func (s SQLStore) execTxOptions(ctx context.Context, opts *sql.TxOptions, fn func(queries *Queries) error) error {
+	tx, err := s.db.BeginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
@@ -70,71 +114,152 @@ func (s SQLStore) execTx(ctx context.Context, fn func(queries *Queries) error) e
 //TransferTx performs a money transfer from one account to the other
 // It creates a transfer record, add account entries and update accounts' balance within a single database transaction
 func (s SQLStore) TransferTx(ctx context.Context, params TransferTxParams) (result TransferTxResult, err error) {
+	if params.Amount <= 0 || params.ToAmount < 0 {
+		return result, ErrInvalidAmount
+	}
+
 	err = s.execTx(ctx, func(queries *Queries) error {
-		if result.Transfer, err = queries.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: params.FromAccountID,
-			ToAccountID:   params.ToAccountID,
-			Amount:        params.Amount,
-		}); err != nil {
-			return err
-		}
+		result, err = transfer(ctx, queries, params)
+		return err
+	})
 
-		if result.FromEntry, err = queries.CreateEntry(ctx, CreateEntryParams{
-			AccountID: params.FromAccountID,
-			Amount:    -params.Amount,
-		}); err != nil {
-			return err
-		}
+	return result, translateConstraintError(err)
+}
+
+//transfer moves money between accounts using queries bound to an already open transaction
+func transfer(ctx context.Context, queries *Queries, params TransferTxParams) (result TransferTxResult, err error) {
+	lockedFrom, lockedTo, err := lockAccounts(ctx, queries, params.FromAccountID, params.ToAccountID)
+	if err != nil {
+		return result, err
+	}
+
+	if err := checkAccountActive(lockedFrom); err != nil {
+		return result, err
+	}
+	if err := checkAccountActive(lockedTo); err != nil {
+		return result, err
+	}
+
+	//Overdue holds must not keep reserving the balance being spent
+	fromAcc, err := queries.ExpireAccountHolds(ctx, params.FromAccountID)
+	if err != nil {
+		return result, err
+	}
+
+	if availableBalance(fromAcc) < params.Amount {
+		return result, ErrInsufficientFunds
+	}
+
+	if err := checkTransferLimits(ctx, queries, fromAcc, params.Amount); err != nil {
+		return result, err
+	}
+
+	if params.ToAmount == 0 {
+		params.ToAmount = params.Amount
+		params.ExchangeRate = sameCurrencyRate
+		params.SpreadBps = 0
+	}
+
+	return bookTransfer(ctx, queries, params, lockedFrom.Currency, lockedTo.Currency)
+}
+
+//bookTransfer creates the transfer record and posts its debit and credit as a journal, once the accounts
+// are locked and the transfer is known to be allowed.
+func bookTransfer(ctx context.Context, queries *Queries, params TransferTxParams, fromCurrency string, toCurrency string) (result TransferTxResult, err error) {
+	if result.Transfer, err = queries.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: params.FromAccountID,
+		ToAccountID:   params.ToAccountID,
+		Amount:        params.Amount,
+		ToAmount:      params.ToAmount,
+		ExchangeRate:  params.ExchangeRate,
+		SpreadBps:     params.SpreadBps,
+	}); err != nil {
+		return result, err
+	}
+
+	transferID := sql.NullInt64{Int64: result.Transfer.ID, Valid: true}
+	postings := []Posting{
+		{AccountID: params.FromAccountID, Amount: -params.Amount, TransferID: transferID, allowFrozen: params.allowFrozenFrom},
+		{AccountID: params.ToAccountID, Amount: params.ToAmount, TransferID: transferID},
+	}
 
-		if result.ToEntry, err = queries.CreateEntry(ctx, CreateEntryParams{
-			AccountID: params.ToAccountID,
-			Amount:    params.Amount,
-		}); err != nil {
-			return err
+	if fromCurrency != toCurrency {
+		//The bank buys the debited currency and sells the credited one through its FX positions,
+		//so the journal balances in both currencies
+		fromPosition, err := systemAccountID(ctx, queries, SystemAccountFXPosition, fromCurrency)
+		if err != nil {
+			return result, err
+		}
+		toPosition, err := systemAccountID(ctx, queries, SystemAccountFXPosition, toCurrency)
+		if err != nil {
+			return result, err
 		}
 
-		result.FromAccount, result.ToAccount, err = updateAccountBalances(ctx, queries, updateBalanceRequest{
-			fromId:     params.FromAccountID,
-			fromAmount: result.FromEntry.Amount,
-			toId:       params.ToAccountID,
-			toAmount:   result.ToEntry.Amount,
-		})
+		postings = append(postings,
+			Posting{AccountID: fromPosition, Amount: params.Amount},
+			Posting{AccountID: toPosition, Amount: -params.ToAmount},
+		)
+	}
 
-		return err
-	})
+	journal, err := postJournal(ctx, queries, postings)
+	if err != nil {
+		return result, err
+	}
+
+	result.FromEntry, result.ToEntry = journal.Entries[0], journal.Entries[1]
+	result.FromAccount, result.ToAccount = journal.account(params.FromAccountID), journal.account(params.ToAccountID)
 
+	err = recordEvent(ctx, queries, OutboxAggregateTransfer, result.Transfer.ID, EventTransferCompleted, TransferCompletedPayload{
+		TransferID:    result.Transfer.ID,
+		FromAccountID: result.Transfer.FromAccountID,
+		FromOwner:     result.FromAccount.Owner,
+		ToAccountID:   result.Transfer.ToAccountID,
+		ToOwner:       result.ToAccount.Owner,
+		Amount:        result.Transfer.Amount,
+		ToAmount:      result.Transfer.ToAmount,
+		ExchangeRate:  result.Transfer.ExchangeRate,
+		CreatedAt:     result.Transfer.CreatedAt,
+	})
 	return result, err
 }
 
-//updateAccountBalances updates account balances ensuring the smallest id will be updated first to avoid deadlocks
-func updateAccountBalances(ctx context.Context, q *Queries, request updateBalanceRequest) (fromAcc Account, toAcc Account, err error) {
-	fromId := request.fromId
-	toId := request.toId
-	fromAmount := request.fromAmount
-	toAmount := request.toAmount
-
-	if fromId < toId {
-		fromAcc, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-			Amount: fromAmount,
-			ID:     fromId,
-		})
-
-		toAcc, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-			Amount: toAmount,
-			ID:     toId,
-		})
-
-		return
-	}
-	toAcc, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		Amount: toAmount,
-		ID:     toId,
-	})
+//availableBalance is what the account can still spend, its balance less active holds plus its overdraft limit
+func availableBalance(account Account) int64 {
+	return account.Balance - account.HeldBalance + account.OverdraftLimit
+}
 
-	fromAcc, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		Amount: fromAmount,
-		ID:     fromId,
-	})
+//lockAccounts locks both accounts of a transfer ensuring the smallest id will be locked first to avoid deadlocks.
+// It returns the locked accounts.
+func lockAccounts(ctx context.Context, q *Queries, fromId int64, toId int64) (fromAcc Account, toAcc Account, err error) {
+	accounts, err := lockAccountIDs(ctx, q, []int64{fromId, toId})
+	if err != nil {
+		return fromAcc, toAcc, err
+	}
 
-	return
+	for _, account := range accounts {
+		if account.ID == fromId {
+			fromAcc = account
+		}
+		if account.ID == toId {
+			toAcc = account
+		}
+	}
+	return fromAcc, toAcc, nil
+}
+
+//translateConstraintError maps CHECK constraint violations raised by the database to domain errors
+func translateConstraintError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != checkViolation {
+		return err
+	}
+
+	switch pqErr.Constraint {
+	case balanceWithinOverdraftCheck, availableBalanceCheck:
+		return ErrInsufficientFunds
+	case transferAmountPositiveCheck, transferToAmountPositive:
+		return ErrInvalidAmount
+	default:
+		return err
+	}
 }
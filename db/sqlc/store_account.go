@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+//Account statuses, only active accounts are debited or credited
+const (
+	AccountStatusActive = "active"
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
+
+var (
+	//ErrAccountFrozen is returned when a transfer or hold touches a frozen account
+	ErrAccountFrozen = errors.New("account is frozen")
+	//ErrAccountClosed is returned when a transfer or hold touches a closed account
+	ErrAccountClosed = errors.New("account is closed")
+	//ErrInvalidAccountTransition is returned when the account can't move from its current status to the requested one
+	ErrInvalidAccountTransition = errors.New("invalid account status transition")
+	//ErrAccountBalanceNotZero is returned when closing an account that still has a balance or active holds
+	ErrAccountBalanceNotZero = errors.New("account balance must be zero to close it")
+)
+
+//accountTransitions lists the statuses each status can move to, a closed account is never reopened
+var accountTransitions = map[string][]string{
+	AccountStatusActive: {AccountStatusFrozen, AccountStatusClosed},
+	AccountStatusFrozen: {AccountStatusActive, AccountStatusClosed},
+}
+
+//FreezeAccount stops an active account from being debited or credited
+func (s SQLStore) FreezeAccount(ctx context.Context, id int64) (Account, error) {
+	return s.changeAccountStatus(ctx, id, AccountStatusFrozen)
+}
+
+//UnfreezeAccount moves a frozen account back to active
+func (s SQLStore) UnfreezeAccount(ctx context.Context, id int64) (Account, error) {
+	return s.changeAccountStatus(ctx, id, AccountStatusActive)
+}
+
+//CloseAccount permanently closes an account with a zero balance and no active holds
+func (s SQLStore) CloseAccount(ctx context.Context, id int64) (Account, error) {
+	return s.changeAccountStatus(ctx, id, AccountStatusClosed)
+}
+
+//changeAccountStatus moves the locked account to status when its state machine allows it
+func (s SQLStore) changeAccountStatus(ctx context.Context, id int64, status string) (account Account, err error) {
+	err = s.execTx(ctx, func(queries *Queries) error {
+		if account, err = queries.GetAccountForUpdate(ctx, id); err != nil {
+			return err
+		}
+
+		if !canTransitionAccount(account.Status, status) {
+			return fmt.Errorf("%w: from %s to %s", ErrInvalidAccountTransition, account.Status, status)
+		}
+
+		if status == AccountStatusClosed {
+			//Overdue holds don't count as reserved balance
+			if account, err = queries.ExpireAccountHolds(ctx, id); err != nil {
+				return err
+			}
+
+			if account.Balance != 0 || account.HeldBalance != 0 {
+				return ErrAccountBalanceNotZero
+			}
+		}
+
+		account, err = queries.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			Status: status,
+			ID:     id,
+		})
+		return err
+	})
+
+	return account, err
+}
+
+func canTransitionAccount(from string, to string) bool {
+	for _, status := range accountTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+//checkAccountActive returns the error matching an account that can't be debited or credited
+func checkAccountActive(account Account) error {
+	switch account.Status {
+	case AccountStatusFrozen:
+		return fmt.Errorf("%w: account %d", ErrAccountFrozen, account.ID)
+	case AccountStatusClosed:
+		return fmt.Errorf("%w: account %d", ErrAccountClosed, account.ID)
+	default:
+		return nil
+	}
+}
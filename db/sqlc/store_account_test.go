@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestStore_FreezeAccount(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	account, err := randomAccountWithBalance(ctx, 100)
+	require.NoError(t, err)
+
+	other, err := randomAccountWithBalance(ctx, 100)
+	require.NoError(t, err)
+
+	frozen, err := store.FreezeAccount(ctx, account.ID)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusFrozen, frozen.Status)
+
+	_, err = store.FreezeAccount(ctx, account.ID)
+	require.ErrorIs(t, err, ErrInvalidAccountTransition)
+
+	//A frozen account is neither debited nor credited
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account.ID, ToAccountID: other.ID, Amount: 10})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: other.ID, ToAccountID: account.ID, Amount: 10})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	_, err = store.PlaceHold(ctx, PlaceHoldParams{AccountID: account.ID, ToAccountID: other.ID, Amount: 10, TTL: time.Hour})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	active, err := store.UnfreezeAccount(ctx, account.ID)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusActive, active.Status)
+
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account.ID, ToAccountID: other.ID, Amount: 10})
+	require.NoError(t, err)
+}
+
+func TestStore_CloseAccount(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	account, err := randomAccountWithBalance(ctx, 100)
+	require.NoError(t, err)
+
+	other, err := randomAccountWithBalance(ctx, 0)
+	require.NoError(t, err)
+
+	_, err = store.CloseAccount(ctx, account.ID)
+	require.ErrorIs(t, err, ErrAccountBalanceNotZero)
+
+	//Active holds keep the account open even once the balance is moved out
+	_, err = store.PlaceHold(ctx, PlaceHoldParams{AccountID: account.ID, ToAccountID: other.ID, Amount: 40, TTL: time.Hour})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account.ID, ToAccountID: other.ID, Amount: 60})
+	require.NoError(t, err)
+
+	_, err = store.CloseAccount(ctx, account.ID)
+	require.ErrorIs(t, err, ErrAccountBalanceNotZero)
+
+	empty, err := randomAccountWithBalance(ctx, 0)
+	require.NoError(t, err)
+
+	closed, err := store.CloseAccount(ctx, empty.ID)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusClosed, closed.Status)
+
+	_, err = store.UnfreezeAccount(ctx, empty.ID)
+	require.ErrorIs(t, err, ErrInvalidAccountTransition)
+
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: other.ID, ToAccountID: empty.ID, Amount: 10})
+	require.ErrorIs(t, err, ErrAccountClosed)
+}
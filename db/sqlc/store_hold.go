@@ -0,0 +1,236 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+//Hold statuses, only active holds reserve balance
+const (
+	HoldStatusActive   = "active"
+	HoldStatusCaptured = "captured"
+	HoldStatusReleased = "released"
+	HoldStatusExpired  = "expired"
+)
+
+var (
+	//ErrHoldNotActive is returned when capturing or releasing a hold that was already captured, released or expired
+	ErrHoldNotActive = errors.New("hold is not active")
+	//ErrCaptureExceedsHold is returned when capturing more than the held amount
+	ErrCaptureExceedsHold = errors.New("capture amount exceeds the held amount")
+)
+
+type (
+	//PlaceHoldParams contains the input parameters of the place hold transaction
+	PlaceHoldParams struct {
+		AccountID   int64         `json:"account_id"`
+		ToAccountID int64         `json:"to_account_id"`
+		Amount      int64         `json:"amount"`
+		TTL         time.Duration `json:"ttl"`
+	}
+	//PlaceHoldResult is the result of the place hold transaction
+	PlaceHoldResult struct {
+		Hold    Hold    `json:"hold"`
+		Account Account `json:"account"`
+	}
+	//CaptureHoldParams contains the input parameters of the capture hold transaction.
+	//A zero Amount captures the whole hold.
+	CaptureHoldParams struct {
+		ID     int64 `json:"id"`
+		Amount int64 `json:"amount"`
+	}
+	//CaptureHoldResult is the result of the capture hold transaction
+	CaptureHoldResult struct {
+		TransferTxResult
+		Hold Hold `json:"hold"`
+	}
+	//ReleaseHoldResult is the result of the release hold transaction
+	ReleaseHoldResult struct {
+		Hold    Hold    `json:"hold"`
+		Account Account `json:"account"`
+	}
+)
+
+//PlaceHold reserves part of the available balance of an account, to be captured to ToAccountID before TTL elapses
+func (s SQLStore) PlaceHold(ctx context.Context, params PlaceHoldParams) (result PlaceHoldResult, err error) {
+	if params.Amount <= 0 {
+		return result, ErrInvalidAmount
+	}
+
+	err = s.execTx(ctx, func(queries *Queries) error {
+		locked, err := queries.GetAccountForUpdate(ctx, params.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkAccountActive(locked); err != nil {
+			return err
+		}
+
+		account, err := queries.ExpireAccountHolds(ctx, params.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if availableBalance(account) < params.Amount {
+			return ErrInsufficientFunds
+		}
+
+		if result.Hold, err = queries.CreateHold(ctx, CreateHoldParams{
+			AccountID:   params.AccountID,
+			ToAccountID: params.ToAccountID,
+			Amount:      params.Amount,
+			ExpiresAt:   time.Now().Add(params.TTL),
+		}); err != nil {
+			return err
+		}
+
+		result.Account, err = queries.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+			Amount: params.Amount,
+			ID:     params.AccountID,
+		})
+		return err
+	})
+
+	return result, translateConstraintError(err)
+}
+
+//CaptureHold moves the captured amount with a normal transfer and releases the rest of the hold.
+// Accounts are locked before the hold, in the same order as TransferTx, to avoid deadlocks.
+func (s SQLStore) CaptureHold(ctx context.Context, params CaptureHoldParams) (result CaptureHoldResult, err error) {
+	if params.Amount < 0 {
+		return result, ErrInvalidAmount
+	}
+
+	err = s.execTx(ctx, func(queries *Queries) error {
+		hold, err := lockHold(ctx, queries, params.ID, true)
+		if err != nil {
+			return err
+		}
+
+		amount := params.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		if amount > hold.Amount {
+			return ErrCaptureExceedsHold
+		}
+
+		//The hold leaves the active status first so the transfer can't expire it a second time
+		if _, err := queries.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+			Status:         HoldStatusCaptured,
+			CapturedAmount: amount,
+			ID:             hold.ID,
+		}); err != nil {
+			return err
+		}
+
+		if _, err := queries.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+			Amount: -hold.Amount,
+			ID:     hold.AccountID,
+		}); err != nil {
+			return err
+		}
+
+		if result.TransferTxResult, err = transfer(ctx, queries, TransferTxParams{
+			FromAccountID: hold.AccountID,
+			ToAccountID:   hold.ToAccountID,
+			Amount:        amount,
+		}); err != nil {
+			return err
+		}
+
+		result.Hold, err = queries.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+			Status:         HoldStatusCaptured,
+			CapturedAmount: amount,
+			TransferID:     sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+			ID:             hold.ID,
+		})
+		return err
+	})
+
+	return result, translateConstraintError(err)
+}
+
+//ReleaseHold cancels an active hold giving its amount back to the available balance
+func (s SQLStore) ReleaseHold(ctx context.Context, id int64) (result ReleaseHoldResult, err error) {
+	err = s.execTx(ctx, func(queries *Queries) error {
+		hold, err := lockHold(ctx, queries, id, false)
+		if err != nil {
+			return err
+		}
+
+		if result.Account, err = queries.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+			Amount: -hold.Amount,
+			ID:     hold.AccountID,
+		}); err != nil {
+			return err
+		}
+
+		result.Hold, err = queries.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+			Status: HoldStatusReleased,
+			ID:     hold.ID,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+//ExpireHolds releases the holds past their expiry of up to limit accounts and returns how many accounts were swept.
+//Expired holds stop reserving balance as soon as an account is touched, this only keeps idle accounts up to date.
+func (s SQLStore) ExpireHolds(ctx context.Context, limit int32) (int, error) {
+	accountIDs, err := s.ListAccountsWithExpiredHolds(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, accountID := range accountIDs {
+		if err := s.execTx(ctx, func(queries *Queries) error {
+			if _, err := queries.GetAccountForUpdate(ctx, accountID); err != nil {
+				return err
+			}
+			_, err := queries.ExpireAccountHolds(ctx, accountID)
+			return err
+		}); err != nil {
+			return i, err
+		}
+	}
+
+	return len(accountIDs), nil
+}
+
+//lockHold locks the accounts of a hold, expires their overdue holds and then locks the hold itself.
+// It fails with ErrHoldNotActive unless the hold is still active.
+func lockHold(ctx context.Context, q *Queries, id int64, withDestination bool) (Hold, error) {
+	hold, err := q.GetHold(ctx, id)
+	if err != nil {
+		return hold, err
+	}
+
+	if withDestination {
+		_, _, err = lockAccounts(ctx, q, hold.AccountID, hold.ToAccountID)
+	} else {
+		_, err = q.GetAccountForUpdate(ctx, hold.AccountID)
+	}
+	if err != nil {
+		return hold, err
+	}
+
+	if _, err := q.ExpireAccountHolds(ctx, hold.AccountID); err != nil {
+		return hold, err
+	}
+
+	if hold, err = q.GetHoldForUpdate(ctx, id); err != nil {
+		return hold, err
+	}
+
+	if hold.Status != HoldStatusActive {
+		return hold, fmt.Errorf("%w: hold %d is %s", ErrHoldNotActive, hold.ID, hold.Status)
+	}
+
+	return hold, nil
+}
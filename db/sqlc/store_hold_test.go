@@ -0,0 +1,212 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestStore_PlaceHold(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	account, err := randomAccountWithBalance(ctx, 100)
+	require.NoError(t, err)
+
+	merchant, err := randomAccountWithBalance(ctx, 0)
+	require.NoError(t, err)
+
+	result, err := store.PlaceHold(ctx, PlaceHoldParams{
+		AccountID:   account.ID,
+		ToAccountID: merchant.ID,
+		Amount:      70,
+		TTL:         time.Hour,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, result.Hold.ID)
+	require.Equal(t, HoldStatusActive, result.Hold.Status)
+	require.Equal(t, int64(100), result.Account.Balance)
+	require.Equal(t, int64(70), result.Account.HeldBalance)
+
+	//The held amount is no longer available to holds nor transfers
+	_, err = store.PlaceHold(ctx, PlaceHoldParams{
+		AccountID:   account.ID,
+		ToAccountID: merchant.ID,
+		Amount:      40,
+		TTL:         time.Hour,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.TransferTx(ctx, TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   merchant.ID,
+		Amount:        40,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.TransferTx(ctx, TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   merchant.ID,
+		Amount:        30,
+	})
+	require.NoError(t, err)
+}
+
+func TestStore_CaptureHold(t *testing.T) {
+	tests := []struct {
+		name          string
+		captureAmount int64
+		wantAmount    int64
+		wantErr       error
+	}{
+		{
+			name:       "When it captures the whole hold",
+			wantAmount: 50,
+		},
+		{
+			name:          "When it captures part of the hold",
+			captureAmount: 20,
+			wantAmount:    20,
+		},
+		{
+			name:          "When capture exceeds the hold",
+			captureAmount: 60,
+			wantErr:       ErrCaptureExceedsHold,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := NewStore(testDb)
+			ctx := context.Background()
+
+			account, err := randomAccountWithBalance(ctx, 100)
+			require.NoError(t, err)
+
+			merchant, err := randomAccountWithBalance(ctx, 0)
+			require.NoError(t, err)
+
+			placed, err := store.PlaceHold(ctx, PlaceHoldParams{
+				AccountID:   account.ID,
+				ToAccountID: merchant.ID,
+				Amount:      50,
+				TTL:         time.Hour,
+			})
+			require.NoError(t, err)
+
+			result, err := store.CaptureHold(ctx, CaptureHoldParams{ID: placed.Hold.ID, Amount: tt.captureAmount})
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+
+				a, err := store.GetAccount(ctx, account.ID)
+				require.NoError(t, err)
+				require.Equal(t, int64(50), a.HeldBalance)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, HoldStatusCaptured, result.Hold.Status)
+			require.Equal(t, tt.wantAmount, result.Hold.CapturedAmount)
+			require.Equal(t, result.Transfer.ID, result.Hold.TransferID.Int64)
+			require.Equal(t, tt.wantAmount, result.Transfer.Amount)
+
+			//The remainder of a partial capture is released
+			require.Equal(t, 100-tt.wantAmount, result.FromAccount.Balance)
+			require.Zero(t, result.FromAccount.HeldBalance)
+			require.Equal(t, tt.wantAmount, result.ToAccount.Balance)
+
+			_, err = store.CaptureHold(ctx, CaptureHoldParams{ID: placed.Hold.ID})
+			require.ErrorIs(t, err, ErrHoldNotActive)
+		})
+	}
+}
+
+func TestStore_ReleaseHold(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	account, err := randomAccountWithBalance(ctx, 100)
+	require.NoError(t, err)
+
+	merchant, err := randomAccountWithBalance(ctx, 0)
+	require.NoError(t, err)
+
+	placed, err := store.PlaceHold(ctx, PlaceHoldParams{
+		AccountID:   account.ID,
+		ToAccountID: merchant.ID,
+		Amount:      50,
+		TTL:         time.Hour,
+	})
+	require.NoError(t, err)
+
+	result, err := store.ReleaseHold(ctx, placed.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusReleased, result.Hold.Status)
+	require.Zero(t, result.Account.HeldBalance)
+	require.Equal(t, int64(100), result.Account.Balance)
+
+	_, err = store.ReleaseHold(ctx, placed.Hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotActive)
+
+	_, err = store.CaptureHold(ctx, CaptureHoldParams{ID: placed.Hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestStore_ExpireHolds(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	account, err := randomAccountWithBalance(ctx, 100)
+	require.NoError(t, err)
+
+	merchant, err := randomAccountWithBalance(ctx, 0)
+	require.NoError(t, err)
+
+	placed, err := store.PlaceHold(ctx, PlaceHoldParams{
+		AccountID:   account.ID,
+		ToAccountID: merchant.ID,
+		Amount:      80,
+		TTL:         -time.Minute,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(80), placed.Account.HeldBalance)
+
+	//An overdue hold doesn't reserve funds even before the sweep runs
+	_, err = store.TransferTx(ctx, TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   merchant.ID,
+		Amount:        90,
+	})
+	require.NoError(t, err)
+
+	hold, err := store.GetHold(ctx, placed.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusExpired, hold.Status)
+
+	_, err = store.CaptureHold(ctx, CaptureHoldParams{ID: placed.Hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+
+	idle, err := randomAccountWithBalance(ctx, 100)
+	require.NoError(t, err)
+
+	idleHold, err := store.PlaceHold(ctx, PlaceHoldParams{
+		AccountID:   idle.ID,
+		ToAccountID: merchant.ID,
+		Amount:      10,
+		TTL:         -time.Minute,
+	})
+	require.NoError(t, err)
+
+	_, err = store.ExpireHolds(ctx, 1000)
+	require.NoError(t, err)
+
+	a, err := store.GetAccount(ctx, idle.ID)
+	require.NoError(t, err)
+	require.Zero(t, a.HeldBalance)
+
+	hold, err = store.GetHold(ctx, idleHold.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusExpired, hold.Status)
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+//ErrIdempotencyKeyReused is returned when an idempotency key is replayed with a different request
+var ErrIdempotencyKeyReused = errors.New("idempotency key already used with a different request")
+
+type (
+	//IdempotentTransferTxParams contains the input parameters of the idempotent transfer transaction
+	IdempotentTransferTxParams struct {
+		TransferTxParams
+		Username    string        `json:"username"`
+		Key         string        `json:"key"`
+		RequestHash string        `json:"request_hash"`
+		TTL         time.Duration `json:"ttl"`
+	}
+	//IdempotentTransferTxResult is the result of the idempotent transfer transaction.
+	//Replayed is true when the result was loaded from a previous request with the same key.
+	IdempotentTransferTxResult struct {
+		TransferTxResult
+		Replayed bool `json:"replayed"`
+	}
+)
+
+//IdempotentTransferTx performs TransferTx at most once per username and idempotency key.
+// The key is claimed and the serialized result is stored within the same database transaction as the transfer,
+// so concurrent requests with the same key wait for the first one and replay its result.
+func (s SQLStore) IdempotentTransferTx(ctx context.Context, params IdempotentTransferTxParams) (result IdempotentTransferTxResult, err error) {
+	if params.Amount <= 0 || params.ToAmount < 0 {
+		return result, ErrInvalidAmount
+	}
+
+	err = s.execTx(ctx, func(queries *Queries) error {
+		if err := queries.DeleteExpiredIdempotencyKey(ctx, DeleteExpiredIdempotencyKeyParams{
+			Username: params.Username,
+			Key:      params.Key,
+		}); err != nil {
+			return err
+		}
+
+		_, err := queries.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+			Username:    params.Username,
+			Key:         params.Key,
+			RequestHash: params.RequestHash,
+			ExpiresAt:   time.Now().Add(params.TTL),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			result, err = replayIdempotencyKey(ctx, queries, params)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		if result.TransferTxResult, err = transfer(ctx, queries, params.TransferTxParams); err != nil {
+			return err
+		}
+
+		responseBody, err := json.Marshal(result.TransferTxResult)
+		if err != nil {
+			return err
+		}
+
+		_, err = queries.UpdateIdempotencyKeyResponse(ctx, UpdateIdempotencyKeyResponseParams{
+			ResponseBody: responseBody,
+			Username:     params.Username,
+			Key:          params.Key,
+		})
+		return err
+	})
+
+	return result, translateConstraintError(err)
+}
+
+//replayIdempotencyKey loads the result stored for an idempotency key already claimed by a previous request
+func replayIdempotencyKey(ctx context.Context, queries *Queries, params IdempotentTransferTxParams) (result IdempotentTransferTxResult, err error) {
+	key, err := queries.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+		Username: params.Username,
+		Key:      params.Key,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if key.RequestHash != params.RequestHash {
+		return result, ErrIdempotencyKeyReused
+	}
+
+	if err := json.Unmarshal(key.ResponseBody, &result.TransferTxResult); err != nil {
+		return result, err
+	}
+	result.Replayed = true
+
+	return result, nil
+}
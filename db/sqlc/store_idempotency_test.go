@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"simplebank/util"
+	"testing"
+	"time"
+)
+
+func TestStore_IdempotentTransferTx(t *testing.T) {
+	t.Parallel()
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	fromAcc, err := randomAccountWithBalance(ctx, 1000)
+	require.NoError(t, err)
+
+	toAcc, err := randomAccountWithBalance(ctx, 1000)
+	require.NoError(t, err)
+
+	params := IdempotentTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: fromAcc.ID,
+			ToAccountID:   toAcc.ID,
+			Amount:        10,
+		},
+		Username:    fromAcc.Owner,
+		Key:         util.RandomString(16),
+		RequestHash: util.RandomString(64),
+		TTL:         time.Hour,
+	}
+
+	first, err := store.IdempotentTransferTx(ctx, params)
+	require.NoError(t, err)
+	assert.False(t, first.Replayed)
+	assert.NotZero(t, first.Transfer.ID)
+
+	//Replaying the same request returns the stored result without moving money again
+	second, err := store.IdempotentTransferTx(ctx, params)
+	require.NoError(t, err)
+	assert.True(t, second.Replayed)
+	assert.Equal(t, first.Transfer.ID, second.Transfer.ID)
+	assert.Equal(t, first.FromAccount.Balance, second.FromAccount.Balance)
+
+	updatedFromAcc, err := store.GetAccount(ctx, fromAcc.ID)
+	require.NoError(t, err)
+	assert.Equal(t, fromAcc.Balance-10, updatedFromAcc.Balance)
+
+	//Reusing the key for a different request is rejected
+	params.RequestHash = util.RandomString(64)
+	_, err = store.IdempotentTransferTx(ctx, params)
+	assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+}
+
+func TestStore_IdempotentTransferTxExpiredKey(t *testing.T) {
+	t.Parallel()
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	fromAcc, err := randomAccountWithBalance(ctx, 1000)
+	require.NoError(t, err)
+
+	toAcc, err := randomAccountWithBalance(ctx, 1000)
+	require.NoError(t, err)
+
+	params := IdempotentTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: fromAcc.ID,
+			ToAccountID:   toAcc.ID,
+			Amount:        10,
+		},
+		Username:    fromAcc.Owner,
+		Key:         util.RandomString(16),
+		RequestHash: util.RandomString(64),
+		TTL:         -time.Second,
+	}
+
+	first, err := store.IdempotentTransferTx(ctx, params)
+	require.NoError(t, err)
+
+	//An expired key is released, so the request runs again
+	second, err := store.IdempotentTransferTx(ctx, params)
+	require.NoError(t, err)
+	assert.False(t, second.Replayed)
+	assert.NotEqual(t, first.Transfer.ID, second.Transfer.ID)
+}
+
+func TestQueries_DeleteExpiredIdempotencyKeys(t *testing.T) {
+	ctx := context.Background()
+	user, err := randomUser(ctx)
+	require.NoError(t, err)
+
+	expired, err := testQueries.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+		Username:    user.Username,
+		Key:         util.RandomString(16),
+		RequestHash: util.RandomString(64),
+		ExpiresAt:   time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	live, err := testQueries.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+		Username:    user.Username,
+		Key:         util.RandomString(16),
+		RequestHash: util.RandomString(64),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	for {
+		deleted, err := testQueries.DeleteExpiredIdempotencyKeys(ctx, 100)
+		require.NoError(t, err)
+		if deleted < 100 {
+			break
+		}
+	}
+
+	_, err = testQueries.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{Username: expired.Username, Key: expired.Key})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = testQueries.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{Username: live.Username, Key: live.Key})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,193 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+//Purposes of the system accounts, the accounts of the bank taking its side of journals in every currency
+const (
+	//SystemAccountFXPosition buys the debited currency and sells the credited currency of cross-currency transfers
+	SystemAccountFXPosition = "fx_position"
+	//SystemAccountInterestIncome is credited the overdraft interest charged to customers
+	SystemAccountInterestIncome = "interest_income"
+)
+
+var (
+	//ErrInvalidPosting is returned when a journal has less than two postings or a posting without amount
+	ErrInvalidPosting = errors.New("a journal needs at least two postings with a non-zero amount")
+	//ErrUnbalancedJournal is returned when the postings of a journal don't sum to zero in every currency
+	ErrUnbalancedJournal = errors.New("journal postings don't sum to zero per currency")
+)
+
+type (
+	//Posting is a leg of a journal transaction, Amount is in the account currency and negative for debits
+	Posting struct {
+		AccountID  int64         `json:"account_id"`
+		Amount     int64         `json:"amount"`
+		TransferID sql.NullInt64 `json:"transfer_id"`
+		//allowFrozen lets the bank post to a frozen account, e.g. to charge it interest
+		allowFrozen bool
+	}
+	//PostJournalResult is the result of the post journal transaction.
+	//Entries follow the order of the postings, Accounts are sorted by id with their balance after posting.
+	PostJournalResult struct {
+		Journal  JournalTransaction `json:"journal"`
+		Entries  []Entry            `json:"entries"`
+		Accounts []Account          `json:"accounts"`
+	}
+)
+
+//PostJournal posts the postings as a single journal transaction.
+//The postings must sum to zero in every currency, and every account must be active.
+func (s SQLStore) PostJournal(ctx context.Context, postings []Posting) (result PostJournalResult, err error) {
+	err = s.execTx(ctx, func(queries *Queries) error {
+		result, err = postJournal(ctx, queries, postings)
+		return err
+	})
+
+	return result, translateConstraintError(err)
+}
+
+//account returns the account with the given id after posting
+func (r PostJournalResult) account(id int64) Account {
+	i := sort.Search(len(r.Accounts), func(i int) bool { return r.Accounts[i].ID >= id })
+	if i < len(r.Accounts) && r.Accounts[i].ID == id {
+		return r.Accounts[i]
+	}
+	return Account{}
+}
+
+//postJournal posts a journal transaction using queries bound to an already open transaction.
+//Accounts are locked and updated by ascending id to avoid deadlocks with concurrent journals.
+func postJournal(ctx context.Context, queries *Queries, postings []Posting) (result PostJournalResult, err error) {
+	if len(postings) < 2 {
+		return result, ErrInvalidPosting
+	}
+
+	netAmounts := make(map[int64]int64, len(postings))
+	transferIDs := make(map[int64]sql.NullInt64, len(postings))
+	frozenAllowed := make(map[int64]bool)
+	for _, posting := range postings {
+		if posting.Amount == 0 {
+			return result, ErrInvalidPosting
+		}
+		netAmounts[posting.AccountID] += posting.Amount
+		if posting.TransferID.Valid {
+			transferIDs[posting.AccountID] = posting.TransferID
+		}
+		if posting.allowFrozen {
+			frozenAllowed[posting.AccountID] = true
+		}
+	}
+
+	ids := make([]int64, 0, len(netAmounts))
+	for id := range netAmounts {
+		ids = append(ids, id)
+	}
+
+	locked, err := lockAccountIDs(ctx, queries, ids)
+	if err != nil {
+		return result, err
+	}
+
+	currencies := make(map[int64]string, len(locked))
+	for _, account := range locked {
+		if err := checkAccountActive(account); err != nil && !(frozenAllowed[account.ID] && errors.Is(err, ErrAccountFrozen)) {
+			return result, err
+		}
+		currencies[account.ID] = account.Currency
+	}
+
+	if err := checkBalanced(postings, currencies); err != nil {
+		return result, err
+	}
+
+	if result.Journal, err = queries.CreateJournalTransaction(ctx); err != nil {
+		return result, err
+	}
+
+	result.Entries = make([]Entry, 0, len(postings))
+	for _, posting := range postings {
+		entry, err := queries.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  posting.AccountID,
+			Amount:     posting.Amount,
+			TransferID: posting.TransferID,
+			JournalID:  result.Journal.ID,
+		})
+		if err != nil {
+			return result, err
+		}
+		result.Entries = append(result.Entries, entry)
+	}
+
+	result.Accounts = make([]Account, 0, len(locked))
+	for _, account := range locked {
+		if netAmounts[account.ID] != 0 {
+			if account, err = queries.AddAccountBalance(ctx, AddAccountBalanceParams{
+				Amount: netAmounts[account.ID],
+				ID:     account.ID,
+			}); err != nil {
+				return result, err
+			}
+
+			if err := recordBalanceChanged(ctx, queries, account, netAmounts[account.ID], result.Journal.ID, transferIDs[account.ID]); err != nil {
+				return result, err
+			}
+		}
+		result.Accounts = append(result.Accounts, account)
+	}
+
+	return result, nil
+}
+
+//checkBalanced returns ErrUnbalancedJournal unless the postings sum to zero in every currency
+func checkBalanced(postings []Posting, currencies map[int64]string) error {
+	totals := make(map[string]int64)
+	for _, posting := range postings {
+		totals[currencies[posting.AccountID]] += posting.Amount
+	}
+
+	for currency, total := range totals {
+		if total != 0 {
+			return fmt.Errorf("%w: %s is off by %d", ErrUnbalancedJournal, currency, total)
+		}
+	}
+	return nil
+}
+
+//systemAccountID returns the id of the system account with the given purpose in currency
+func systemAccountID(ctx context.Context, queries *Queries, purpose string, currency string) (int64, error) {
+	id, err := queries.GetSystemAccountID(ctx, GetSystemAccountIDParams{
+		Purpose:  purpose,
+		Currency: currency,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("can't find the %s account in %s: %w", purpose, currency, err)
+	}
+	return id, nil
+}
+
+//lockAccountIDs locks the accounts by ascending id to avoid deadlocks and returns them in that order
+func lockAccountIDs(ctx context.Context, q *Queries, ids []int64) ([]Account, error) {
+	sorted := append([]int64(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	accounts := make([]Account, 0, len(sorted))
+	for i, id := range sorted {
+		if i > 0 && id == sorted[i-1] {
+			continue
+		}
+
+		account, err := q.GetAccountForUpdate(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, nil
+}
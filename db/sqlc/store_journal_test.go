@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStore_PostJournal(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	payer, err := randomAccountWithBalance(ctx, 1000)
+	require.NoError(t, err)
+
+	first, err := randomAccountInCurrency(ctx, 0, payer.Currency)
+	require.NoError(t, err)
+
+	second, err := randomAccountInCurrency(ctx, 0, payer.Currency)
+	require.NoError(t, err)
+
+	result, err := store.PostJournal(ctx, []Posting{
+		{AccountID: payer.ID, Amount: -300},
+		{AccountID: first.ID, Amount: 100},
+		{AccountID: second.ID, Amount: 200},
+	})
+	require.NoError(t, err)
+	require.NotZero(t, result.Journal.ID)
+	require.Len(t, result.Entries, 3)
+	require.Len(t, result.Accounts, 3)
+
+	for i, want := range []int64{-300, 100, 200} {
+		require.Equal(t, want, result.Entries[i].Amount)
+		require.Equal(t, result.Journal.ID, result.Entries[i].JournalID)
+	}
+	require.Equal(t, int64(700), result.account(payer.ID).Balance)
+	require.Equal(t, int64(100), result.account(first.ID).Balance)
+	require.Equal(t, int64(200), result.account(second.ID).Balance)
+
+	entries, err := store.ListEntriesByJournal(ctx, result.Journal.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.Entries, entries)
+}
+
+func TestStore_PostJournalValidation(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	account, err := randomAccountInCurrency(ctx, 100, "USD")
+	require.NoError(t, err)
+
+	other, err := randomAccountInCurrency(ctx, 0, account.Currency)
+	require.NoError(t, err)
+
+	foreign, err := randomAccountInCurrency(ctx, 0, "EUR")
+	require.NoError(t, err)
+
+	_, err = store.PostJournal(ctx, []Posting{{AccountID: account.ID, Amount: -10}})
+	require.ErrorIs(t, err, ErrInvalidPosting)
+
+	_, err = store.PostJournal(ctx, []Posting{{AccountID: account.ID, Amount: 0}, {AccountID: other.ID, Amount: 0}})
+	require.ErrorIs(t, err, ErrInvalidPosting)
+
+	_, err = store.PostJournal(ctx, []Posting{{AccountID: account.ID, Amount: -10}, {AccountID: other.ID, Amount: 9}})
+	require.ErrorIs(t, err, ErrUnbalancedJournal)
+
+	_, err = store.PostJournal(ctx, []Posting{{AccountID: account.ID, Amount: -10}, {AccountID: foreign.ID, Amount: 10}})
+	require.ErrorIs(t, err, ErrUnbalancedJournal)
+
+	_, err = store.PostJournal(ctx, []Posting{{AccountID: account.ID, Amount: -1000}, {AccountID: other.ID, Amount: 1000}})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	//Nothing was posted by the failed journals
+	got, err := store.GetAccount(ctx, account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), got.Balance)
+}
+
+func Test_checkBalanced(t *testing.T) {
+	currencies := map[int64]string{1: "USD", 2: "USD", 3: "EUR", 4: "EUR"}
+
+	tests := []struct {
+		name     string
+		postings []Posting
+		wantErr  bool
+	}{
+		{
+			name:     "When same currency legs sum to zero",
+			postings: []Posting{{AccountID: 1, Amount: -10}, {AccountID: 2, Amount: 10}},
+		},
+		{
+			name:     "When same currency legs don't sum to zero",
+			postings: []Posting{{AccountID: 1, Amount: -10}, {AccountID: 2, Amount: 9}},
+			wantErr:  true,
+		},
+		{
+			name:     "When currencies differ",
+			postings: []Posting{{AccountID: 1, Amount: -10}, {AccountID: 3, Amount: 9}},
+			wantErr:  true,
+		},
+		{
+			name: "When position legs balance every currency",
+			postings: []Posting{
+				{AccountID: 1, Amount: -10},
+				{AccountID: 2, Amount: 10},
+				{AccountID: 3, Amount: 9},
+				{AccountID: 4, Amount: -9},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := checkBalanced(tt.postings, currencies)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrUnbalancedJournal)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+//Aggregates events are ordered by, the relay publishes the events of one aggregate in the order they were written
+const (
+	OutboxAggregateAccount  = "account"
+	OutboxAggregateTransfer = "transfer"
+)
+
+//Domain event types
+const (
+	EventAccountCreated    = "AccountCreated"
+	EventTransferCompleted = "TransferCompleted"
+	EventBalanceChanged    = "BalanceChanged"
+)
+
+type (
+	//AccountCreatedPayload is the payload of EventAccountCreated
+	AccountCreatedPayload struct {
+		AccountID int64     `json:"account_id"`
+		Owner     string    `json:"owner"`
+		Currency  string    `json:"currency"`
+		Balance   int64     `json:"balance"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+	//TransferCompletedPayload is the payload of EventTransferCompleted, ToAmount is in the destination currency
+	TransferCompletedPayload struct {
+		TransferID    int64     `json:"transfer_id"`
+		FromAccountID int64     `json:"from_account_id"`
+		FromOwner     string    `json:"from_owner"`
+		ToAccountID   int64     `json:"to_account_id"`
+		ToOwner       string    `json:"to_owner"`
+		Amount        int64     `json:"amount"`
+		ToAmount      int64     `json:"to_amount"`
+		ExchangeRate  int64     `json:"exchange_rate"`
+		CreatedAt     time.Time `json:"created_at"`
+	}
+	//BalanceChangedPayload is the payload of EventBalanceChanged, Balance is the balance once Amount was applied
+	BalanceChangedPayload struct {
+		AccountID  int64  `json:"account_id"`
+		Owner      string `json:"owner"`
+		Currency   string `json:"currency"`
+		Amount     int64  `json:"amount"`
+		Balance    int64  `json:"balance"`
+		JournalID  int64  `json:"journal_id"`
+		TransferID *int64 `json:"transfer_id"`
+	}
+)
+
+//CreateAccountTx creates an account and records its AccountCreated event within a single database transaction
+func (s SQLStore) CreateAccountTx(ctx context.Context, params CreateAccountParams) (account Account, err error) {
+	err = s.execTx(ctx, func(queries *Queries) error {
+		if account, err = queries.CreateAccount(ctx, params); err != nil {
+			return err
+		}
+
+		return recordEvent(ctx, queries, OutboxAggregateAccount, account.ID, EventAccountCreated, AccountCreatedPayload{
+			AccountID: account.ID,
+			Owner:     account.Owner,
+			Currency:  account.Currency,
+			Balance:   account.Balance,
+			CreatedAt: account.CreatedAt,
+		})
+	})
+
+	return account, err
+}
+
+//recordBalanceChanged records the BalanceChanged event of an account updated by a journal.
+//It must run while the account is locked, so the events of an account are written in commit order.
+func recordBalanceChanged(ctx context.Context, queries *Queries, account Account, amount int64, journalID int64, transferID sql.NullInt64) error {
+	payload := BalanceChangedPayload{
+		AccountID: account.ID,
+		Owner:     account.Owner,
+		Currency:  account.Currency,
+		Amount:    amount,
+		Balance:   account.Balance,
+		JournalID: journalID,
+	}
+	if transferID.Valid {
+		payload.TransferID = &transferID.Int64
+	}
+
+	return recordEvent(ctx, queries, OutboxAggregateAccount, account.ID, EventBalanceChanged, payload)
+}
+
+//recordEvent writes an event to the outbox using queries bound to the transaction making the change it describes,
+//so the event is relayed if and only if the change is committed
+func recordEvent(ctx context.Context, queries *Queries, aggregateType string, aggregateID int64, eventType string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = queries.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       body,
+	})
+	return err
+}
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStore_CreateAccountTx(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	user, err := randomUser(ctx)
+	require.NoError(t, err)
+
+	account, err := store.CreateAccountTx(ctx, CreateAccountParams{
+		Owner:    user.Username,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+
+	events, err := store.ListOutboxEventsByAggregate(ctx, ListOutboxEventsByAggregateParams{
+		AggregateType: OutboxAggregateAccount,
+		AggregateID:   account.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, EventAccountCreated, events[0].EventType)
+	require.False(t, events[0].PublishedAt.Valid)
+
+	var payload AccountCreatedPayload
+	require.NoError(t, json.Unmarshal(events[0].Payload, &payload))
+	require.Equal(t, account.ID, payload.AccountID)
+	require.Equal(t, user.Username, payload.Owner)
+	require.Equal(t, "USD", payload.Currency)
+}
+
+func TestStore_TransferTxRecordsEvents(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	from, err := randomAccountInCurrency(ctx, 100, "USD")
+	require.NoError(t, err)
+	to, err := randomAccountInCurrency(ctx, 0, "USD")
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(ctx, TransferTxParams{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 30})
+	require.NoError(t, err)
+
+	events, err := store.ListOutboxEventsByAggregate(ctx, ListOutboxEventsByAggregateParams{
+		AggregateType: OutboxAggregateTransfer,
+		AggregateID:   result.Transfer.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, EventTransferCompleted, events[0].EventType)
+
+	var completed TransferCompletedPayload
+	require.NoError(t, json.Unmarshal(events[0].Payload, &completed))
+	require.Equal(t, result.Transfer.ID, completed.TransferID)
+	require.Equal(t, from.Owner, completed.FromOwner)
+	require.Equal(t, to.Owner, completed.ToOwner)
+	require.Equal(t, int64(30), completed.Amount)
+
+	for _, tt := range []struct {
+		account     Account
+		wantAmount  int64
+		wantBalance int64
+	}{
+		{account: from, wantAmount: -30, wantBalance: 70},
+		{account: to, wantAmount: 30, wantBalance: 30},
+	} {
+		events, err := store.ListOutboxEventsByAggregate(ctx, ListOutboxEventsByAggregateParams{
+			AggregateType: OutboxAggregateAccount,
+			AggregateID:   tt.account.ID,
+		})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, EventBalanceChanged, events[0].EventType)
+
+		var changed BalanceChangedPayload
+		require.NoError(t, json.Unmarshal(events[0].Payload, &changed))
+		require.Equal(t, tt.wantAmount, changed.Amount)
+		require.Equal(t, tt.wantBalance, changed.Balance)
+		require.Equal(t, result.FromEntry.JournalID, changed.JournalID)
+		require.NotNil(t, changed.TransferID)
+		require.Equal(t, result.Transfer.ID, *changed.TransferID)
+	}
+}
+
+func TestStore_TransferTxRejectedRecordsNoEvent(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	from, err := randomAccountInCurrency(ctx, 10, "USD")
+	require.NoError(t, err)
+	to, err := randomAccountInCurrency(ctx, 0, "USD")
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 30})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	events, err := store.ListOutboxEventsByAggregate(ctx, ListOutboxEventsByAggregateParams{
+		AggregateType: OutboxAggregateAccount,
+		AggregateID:   from.ID,
+	})
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
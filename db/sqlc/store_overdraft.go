@@ -0,0 +1,163 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+//daysPerYear spreads the yearly overdraft interest rate over daily charges
+const daysPerYear = 365
+
+var (
+	//ErrOverdraftLimitTooLow is returned when the new limit doesn't cover what the account already owes
+	ErrOverdraftLimitTooLow = errors.New("overdraft limit doesn't cover the current negative available balance")
+	//ErrOverdraftInterestCharged is returned when the overdraft interest of the day was already charged to the account
+	ErrOverdraftInterestCharged = errors.New("overdraft interest already charged for the day")
+)
+
+type (
+	//SetOverdraftLimitParams contains the input parameters of the set overdraft limit transaction
+	SetOverdraftLimitParams struct {
+		AccountID int64  `json:"account_id"`
+		Limit     int64  `json:"limit"`
+		ChangedBy string `json:"changed_by"`
+		Reason    string `json:"reason"`
+	}
+	//SetOverdraftLimitResult is the result of the set overdraft limit transaction
+	SetOverdraftLimitResult struct {
+		Account Account              `json:"account"`
+		Change  OverdraftLimitChange `json:"change"`
+	}
+	//ChargeOverdraftInterestParams contains the input parameters of the charge overdraft interest transaction.
+	//RateBps is the yearly interest rate in basis points, Day is truncated to its UTC date.
+	ChargeOverdraftInterestParams struct {
+		AccountID int64     `json:"account_id"`
+		Day       time.Time `json:"day"`
+		RateBps   int64     `json:"rate_bps"`
+	}
+	//ChargeOverdraftInterestResult is the result of the charge overdraft interest transaction.
+	//Charge and Entry are left empty when the account owed no interest for the day.
+	ChargeOverdraftInterestResult struct {
+		Charge  OverdraftInterestCharge `json:"charge"`
+		Entry   Entry                   `json:"entry"`
+		Account Account                 `json:"account"`
+	}
+)
+
+//SetOverdraftLimit changes how far below zero the account may go and records who changed it and why
+func (s SQLStore) SetOverdraftLimit(ctx context.Context, params SetOverdraftLimitParams) (result SetOverdraftLimitResult, err error) {
+	err = s.execTx(ctx, func(queries *Queries) error {
+		account, err := queries.GetAccountForUpdate(ctx, params.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if account.Status == AccountStatusClosed {
+			return ErrAccountClosed
+		}
+
+		if account, err = queries.ExpireAccountHolds(ctx, params.AccountID); err != nil {
+			return err
+		}
+
+		if availableBalance(account)-account.OverdraftLimit+params.Limit < 0 {
+			return ErrOverdraftLimitTooLow
+		}
+
+		if result.Account, err = queries.UpdateAccountOverdraftLimit(ctx, UpdateAccountOverdraftLimitParams{
+			OverdraftLimit: params.Limit,
+			ID:             params.AccountID,
+		}); err != nil {
+			return err
+		}
+
+		result.Change, err = queries.CreateOverdraftLimitChange(ctx, CreateOverdraftLimitChangeParams{
+			AccountID: params.AccountID,
+			OldLimit:  account.OverdraftLimit,
+			NewLimit:  params.Limit,
+			ChangedBy: params.ChangedBy,
+			Reason:    params.Reason,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+//ChargeOverdraftInterest debits the daily interest owed on a negative end-of-day balance.
+//A day is charged at most once per account, and the interest never takes the account past its overdraft limit.
+func (s SQLStore) ChargeOverdraftInterest(ctx context.Context, params ChargeOverdraftInterestParams) (result ChargeOverdraftInterestResult, err error) {
+	day := time.Date(params.Day.Year(), params.Day.Month(), params.Day.Day(), 0, 0, 0, 0, time.UTC)
+
+	err = s.execTx(ctx, func(queries *Queries) error {
+		if result.Account, err = queries.GetAccountForUpdate(ctx, params.AccountID); err != nil {
+			return err
+		}
+
+		_, err := queries.GetOverdraftInterestCharge(ctx, GetOverdraftInterestChargeParams{
+			AccountID: params.AccountID,
+			Day:       day,
+		})
+		if err == nil {
+			return ErrOverdraftInterestCharged
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		balance, err := queries.GetAccountBalanceAt(ctx, GetAccountBalanceAtParams{
+			At:        day.AddDate(0, 0, 1),
+			AccountID: params.AccountID,
+		})
+		if err != nil {
+			return err
+		}
+
+		amount := overdraftInterest(balance, params.RateBps)
+		if headroom := availableBalance(result.Account); amount > headroom {
+			amount = headroom
+		}
+		if amount <= 0 {
+			return nil
+		}
+
+		incomeID, err := systemAccountID(ctx, queries, SystemAccountInterestIncome, result.Account.Currency)
+		if err != nil {
+			return err
+		}
+
+		//A frozen account keeps accruing interest on what it owes
+		journal, err := postJournal(ctx, queries, []Posting{
+			{AccountID: params.AccountID, Amount: -amount, allowFrozen: true},
+			{AccountID: incomeID, Amount: amount},
+		})
+		if err != nil {
+			return err
+		}
+		result.Entry, result.Account = journal.Entries[0], journal.account(params.AccountID)
+
+		result.Charge, err = queries.CreateOverdraftInterestCharge(ctx, CreateOverdraftInterestChargeParams{
+			AccountID: params.AccountID,
+			Day:       day,
+			Balance:   balance,
+			RateBps:   params.RateBps,
+			Amount:    amount,
+			EntryID:   result.Entry.ID,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+//overdraftInterest returns the interest of a day spent at balance, rounded up to the next minor unit
+func overdraftInterest(balance int64, rateBps int64) int64 {
+	if balance >= 0 || rateBps <= 0 {
+		return 0
+	}
+
+	const divisor = 10_000 * daysPerYear
+	return (-balance*rateBps + divisor - 1) / divisor
+}
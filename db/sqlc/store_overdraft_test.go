@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestStore_SetOverdraftLimit(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	account, err := randomAccountWithBalance(ctx, 100)
+	require.NoError(t, err)
+
+	other, err := randomAccountWithBalance(ctx, 0)
+	require.NoError(t, err)
+
+	result, err := store.SetOverdraftLimit(ctx, SetOverdraftLimitParams{
+		AccountID: account.ID,
+		Limit:     500,
+		ChangedBy: account.Owner,
+		Reason:    "business plan",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(500), result.Account.OverdraftLimit)
+	require.Equal(t, int64(0), result.Change.OldLimit)
+	require.Equal(t, int64(500), result.Change.NewLimit)
+
+	//The balance may go negative down to the limit
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account.ID, ToAccountID: other.ID, Amount: 601})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	transfer, err := store.TransferTx(ctx, TransferTxParams{FromAccountID: account.ID, ToAccountID: other.ID, Amount: 400})
+	require.NoError(t, err)
+	require.Equal(t, int64(-300), transfer.FromAccount.Balance)
+
+	_, err = store.SetOverdraftLimit(ctx, SetOverdraftLimitParams{
+		AccountID: account.ID,
+		Limit:     200,
+		ChangedBy: account.Owner,
+		Reason:    "too low",
+	})
+	require.ErrorIs(t, err, ErrOverdraftLimitTooLow)
+
+	changes, err := store.ListOverdraftLimitChanges(ctx, account.ID)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, result.Change, changes[0])
+}
+
+func TestStore_ChargeOverdraftInterest(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	account, err := randomAccountWithBalance(ctx, 0)
+	require.NoError(t, err)
+
+	other, err := randomAccountWithBalance(ctx, 0)
+	require.NoError(t, err)
+
+	_, err = store.SetOverdraftLimit(ctx, SetOverdraftLimitParams{
+		AccountID: account.ID,
+		Limit:     1_000_000,
+		ChangedBy: account.Owner,
+		Reason:    "business plan",
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account.ID, ToAccountID: other.ID, Amount: 730_000})
+	require.NoError(t, err)
+
+	today := time.Now().UTC()
+	params := ChargeOverdraftInterestParams{AccountID: account.ID, Day: today, RateBps: 1000}
+
+	result, err := store.ChargeOverdraftInterest(ctx, params)
+	require.NoError(t, err)
+	//10% a year of 730000 over one day
+	require.Equal(t, int64(200), result.Charge.Amount)
+	require.Equal(t, int64(-730_000), result.Charge.Balance)
+	require.Equal(t, int64(-200), result.Entry.Amount)
+	require.Equal(t, int64(-730_200), result.Account.Balance)
+
+	//The interest is credited to the bank in the same journal
+	incomeID, err := testQueries.GetSystemAccountID(ctx, GetSystemAccountIDParams{Purpose: SystemAccountInterestIncome, Currency: account.Currency})
+	require.NoError(t, err)
+	entries, err := testQueries.ListEntriesByJournal(ctx, result.Entry.JournalID)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, incomeID, entries[1].AccountID)
+	require.Equal(t, int64(200), entries[1].Amount)
+
+	_, err = store.ChargeOverdraftInterest(ctx, params)
+	require.ErrorIs(t, err, ErrOverdraftInterestCharged)
+
+	//No interest is owed on a positive balance
+	result, err = store.ChargeOverdraftInterest(ctx, ChargeOverdraftInterestParams{AccountID: other.ID, Day: today, RateBps: 1000})
+	require.NoError(t, err)
+	require.Zero(t, result.Charge.Amount)
+}
+
+func Test_overdraftInterest(t *testing.T) {
+	require.Equal(t, int64(0), overdraftInterest(100, 1000))
+	require.Equal(t, int64(200), overdraftInterest(-730_000, 1000))
+	//Rounded up to the next minor unit
+	require.Equal(t, int64(1), overdraftInterest(-1, 1000))
+}
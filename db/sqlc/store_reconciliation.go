@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const (
+	//ReconciliationIssueAccountDrift is an account whose balance differs from the sum of its entries
+	ReconciliationIssueAccountDrift = "account_drift"
+	//ReconciliationIssueTransferEntries is a transfer without exactly one debit and one credit entry matching its amounts
+	ReconciliationIssueTransferEntries = "transfer_entries"
+
+	//transferEntriesCount is the number of entries every transfer books, its debit and its credit
+	transferEntriesCount = 2
+)
+
+//ReconcileResult is the result of the reconcile transaction
+type ReconcileResult struct {
+	Run    ReconciliationRun     `json:"run"`
+	Issues []ReconciliationIssue `json:"issues"`
+}
+
+//Reconcile proves the ledger is consistent: every account balance equals the sum of its entries
+//and every transfer booked exactly its debit and its credit. The checks read a single snapshot,
+//so transfers committing meanwhile don't show up as drift, and the run is stored with its issues.
+func (s SQLStore) Reconcile(ctx context.Context) (result ReconcileResult, err error) {
+	startedAt := time.Now()
+
+	var accountsChecked, transfersChecked int64
+	var drifts []ListAccountDriftsRow
+	var mismatches []ListTransferEntryMismatchesRow
+
+	err = s.execTxOptions(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}, func(queries *Queries) error {
+		if accountsChecked, err = queries.CountAccounts(ctx); err != nil {
+			return err
+		}
+
+		if transfersChecked, err = queries.CountTransfers(ctx); err != nil {
+			return err
+		}
+
+		if drifts, err = queries.ListAccountDrifts(ctx); err != nil {
+			return err
+		}
+
+		mismatches, err = queries.ListTransferEntryMismatches(ctx)
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+
+	err = s.execTx(ctx, func(queries *Queries) error {
+		if result.Run, err = queries.CreateReconciliationRun(ctx, CreateReconciliationRunParams{
+			AccountsChecked:  accountsChecked,
+			TransfersChecked: transfersChecked,
+			IssuesCount:      int64(len(drifts) + len(mismatches)),
+			StartedAt:        startedAt,
+		}); err != nil {
+			return err
+		}
+
+		result.Issues = make([]ReconciliationIssue, 0, result.Run.IssuesCount)
+		for _, drift := range drifts {
+			issue, err := queries.CreateReconciliationIssue(ctx, CreateReconciliationIssueParams{
+				RunID:     result.Run.ID,
+				Kind:      ReconciliationIssueAccountDrift,
+				AccountID: sql.NullInt64{Int64: drift.ID, Valid: true},
+				Expected:  drift.EntriesTotal,
+				Actual:    drift.Balance,
+				Detail:    fmt.Sprintf("balance is off by %d", drift.Balance-drift.EntriesTotal),
+			})
+			if err != nil {
+				return err
+			}
+			result.Issues = append(result.Issues, issue)
+		}
+
+		for _, mismatch := range mismatches {
+			issue, err := queries.CreateReconciliationIssue(ctx, CreateReconciliationIssueParams{
+				RunID:      result.Run.ID,
+				Kind:       ReconciliationIssueTransferEntries,
+				TransferID: sql.NullInt64{Int64: mismatch.ID, Valid: true},
+				Expected:   transferEntriesCount,
+				Actual:     mismatch.EntriesCount,
+				Detail: fmt.Sprintf("%d matching debits and %d matching credits",
+					mismatch.DebitsCount, mismatch.CreditsCount),
+			})
+			if err != nil {
+				return err
+			}
+			result.Issues = append(result.Issues, issue)
+		}
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStore_Reconcile(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	from, err := randomAccountWithBalance(ctx, 0)
+	require.NoError(t, err)
+
+	to, err := randomAccountWithBalance(ctx, 0)
+	require.NoError(t, err)
+
+	_, err = store.SetOverdraftLimit(ctx, SetOverdraftLimitParams{
+		AccountID: from.ID,
+		Limit:     100,
+		ChangedBy: from.Owner,
+		Reason:    "reconciliation test",
+	})
+	require.NoError(t, err)
+
+	booked, err := store.TransferTx(ctx, TransferTxParams{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 30})
+	require.NoError(t, err)
+
+	//A balance set without entry and a transfer without entries break the ledger
+	drifted, err := randomAccountWithBalance(ctx, 0)
+	require.NoError(t, err)
+
+	_, err = store.UpdateAccount(ctx, UpdateAccountParams{Balance: 50, ID: drifted.ID})
+	require.NoError(t, err)
+
+	orphan, err := store.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        10,
+		ToAmount:      10,
+		ExchangeRate:  sameCurrencyRate,
+	})
+	require.NoError(t, err)
+
+	result, err := store.Reconcile(ctx)
+	require.NoError(t, err)
+	require.NotZero(t, result.Run.ID)
+	require.Equal(t, int64(len(result.Issues)), result.Run.IssuesCount)
+	require.Positive(t, result.Run.AccountsChecked)
+	require.Positive(t, result.Run.TransfersChecked)
+
+	accountIssues := make(map[int64]ReconciliationIssue)
+	transferIssues := make(map[int64]ReconciliationIssue)
+	for _, issue := range result.Issues {
+		require.Equal(t, result.Run.ID, issue.RunID)
+		switch issue.Kind {
+		case ReconciliationIssueAccountDrift:
+			accountIssues[issue.AccountID.Int64] = issue
+		case ReconciliationIssueTransferEntries:
+			transferIssues[issue.TransferID.Int64] = issue
+		}
+	}
+
+	require.NotContains(t, accountIssues, from.ID)
+	require.NotContains(t, accountIssues, to.ID)
+	require.Contains(t, accountIssues, drifted.ID)
+	require.Equal(t, int64(0), accountIssues[drifted.ID].Expected)
+	require.Equal(t, int64(50), accountIssues[drifted.ID].Actual)
+
+	require.NotContains(t, transferIssues, booked.Transfer.ID)
+	require.Contains(t, transferIssues, orphan.ID)
+	require.Equal(t, int64(0), transferIssues[orphan.ID].Actual)
+
+	run, err := store.GetReconciliationRun(ctx, result.Run.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.Run.IssuesCount, run.IssuesCount)
+
+	issues, err := store.ListReconciliationIssues(ctx, result.Run.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.Issues, issues)
+}
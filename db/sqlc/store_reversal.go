@@ -0,0 +1,167 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"math/bits"
+)
+
+const (
+	//TransferNotReversed is the reversal status of a transfer nothing was returned from
+	TransferNotReversed = "not_reversed"
+	//TransferPartiallyReversed is the reversal status of a transfer reversed for less than its amount
+	TransferPartiallyReversed = "partially_reversed"
+	//TransferReversed is the reversal status of a transfer reversed for its whole amount
+	TransferReversed = "reversed"
+)
+
+var (
+	//ErrTransferAlreadyReversed is returned when the whole amount of the transfer was already reversed
+	ErrTransferAlreadyReversed = errors.New("transfer already reversed")
+	//ErrReversalExceedsTransfer is returned when a reversal would return more than the amount left to reverse
+	ErrReversalExceedsTransfer = errors.New("reversal exceeds the amount left to reverse")
+	//ErrTransferIsReversal is returned when reversing a compensating transfer, its original must be used instead
+	ErrTransferIsReversal = errors.New("transfer is a reversal and can't be reversed")
+)
+
+type (
+	//ReverseTransferParams contains the input parameters of the reverse transfer transaction.
+	//Amount is returned to the source account in the currency of the original amount, zero reverses what is left.
+	ReverseTransferParams struct {
+		TransferID int64  `json:"transfer_id"`
+		Amount     int64  `json:"amount"`
+		ReversedBy string `json:"reversed_by"`
+		Reason     string `json:"reason"`
+	}
+	//ReverseTransferResult is the result of the reverse transfer transaction, the embedded result is the compensating transfer
+	ReverseTransferResult struct {
+		TransferTxResult
+		Reversal       TransferReversal `json:"reversal"`
+		ReversedAmount int64            `json:"reversed_amount"`
+		ReversalStatus string           `json:"reversal_status"`
+	}
+)
+
+//ReverseTransfer books a compensating transfer from the destination back to the source of a transfer.
+//Cross-currency transfers are reversed at their original rate, so a full reversal takes back exactly what
+//was credited. Reversals are not checked against nor counted in the outbound limits of the destination,
+//and may debit a frozen destination so that funds can be clawed back from an account under investigation.
+func (s SQLStore) ReverseTransfer(ctx context.Context, params ReverseTransferParams) (result ReverseTransferResult, err error) {
+	if params.Amount < 0 {
+		return result, ErrInvalidAmount
+	}
+
+	err = s.execTx(ctx, func(queries *Queries) error {
+		original, err := queries.GetTransfer(ctx, params.TransferID)
+		if err != nil {
+			return err
+		}
+
+		source, destination, err := lockAccounts(ctx, queries, original.FromAccountID, original.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		//Locking the original serializes concurrent reversals of the same transfer
+		if original, err = queries.GetTransferForUpdate(ctx, params.TransferID); err != nil {
+			return err
+		}
+
+		status, err := queries.GetTransferReversalStatus(ctx, original.ID)
+		if err != nil {
+			return err
+		}
+		if status.ReversalOf != 0 {
+			return ErrTransferIsReversal
+		}
+
+		remaining := original.Amount - status.ReversedAmount
+		if remaining == 0 {
+			return ErrTransferAlreadyReversed
+		}
+
+		amount := params.Amount
+		if amount == 0 {
+			amount = remaining
+		}
+		if amount > remaining {
+			return ErrReversalExceedsTransfer
+		}
+
+		//Rounding on the cumulative amount makes the partial reversals add up to what was credited
+		debit := reversedToAmount(original, status.ReversedAmount+amount) - reversedToAmount(original, status.ReversedAmount)
+		if debit <= 0 {
+			return ErrInvalidAmount
+		}
+
+		if err := checkAccountActive(source); err != nil {
+			return err
+		}
+		if err := checkAccountActive(destination); err != nil && !errors.Is(err, ErrAccountFrozen) {
+			return err
+		}
+
+		if destination, err = queries.ExpireAccountHolds(ctx, destination.ID); err != nil {
+			return err
+		}
+		if availableBalance(destination) < debit {
+			return ErrInsufficientFunds
+		}
+
+		if result.TransferTxResult, err = bookTransfer(ctx, queries, TransferTxParams{
+			FromAccountID: destination.ID,
+			ToAccountID:   source.ID,
+			Amount:        debit,
+			ToAmount:      amount,
+			//The reversal converts from the destination currency back to the source one, at the inverse of the original rate
+			ExchangeRate:    inverseRate(original.ExchangeRate),
+			allowFrozenFrom: true,
+		}, destination.Currency, source.Currency); err != nil {
+			return err
+		}
+
+		if result.Reversal, err = queries.CreateTransferReversal(ctx, CreateTransferReversalParams{
+			TransferID:         original.ID,
+			ReversalTransferID: result.Transfer.ID,
+			Amount:             amount,
+			ReversedBy:         params.ReversedBy,
+			Reason:             params.Reason,
+		}); err != nil {
+			return err
+		}
+
+		result.ReversedAmount = status.ReversedAmount + amount
+		result.ReversalStatus = TransferReversalStatus(original, result.ReversedAmount)
+		return nil
+	})
+
+	return result, translateConstraintError(err)
+}
+
+//TransferReversalStatus tells whether the reversed amount returns none, part or all of the transfer
+func TransferReversalStatus(transfer Transfer, reversedAmount int64) string {
+	switch {
+	case reversedAmount <= 0:
+		return TransferNotReversed
+	case reversedAmount < transfer.Amount:
+		return TransferPartiallyReversed
+	default:
+		return TransferReversed
+	}
+}
+
+//reversedToAmount is the part of the amount credited by the transfer that matches reversing amount of its debit
+func reversedToAmount(transfer Transfer, amount int64) int64 {
+	if amount == transfer.Amount {
+		return transfer.ToAmount
+	}
+	//amount never exceeds transfer.Amount, so the quotient fits and only the product needs 128 bits
+	hi, lo := bits.Mul64(uint64(transfer.ToAmount), uint64(amount))
+	quo, _ := bits.Div64(hi, lo, uint64(transfer.Amount))
+	return int64(quo)
+}
+
+//inverseRate inverts a rate scaled by sameCurrencyRate, rounding to the nearest unit
+func inverseRate(rate int64) int64 {
+	return (sameCurrencyRate*sameCurrencyRate + rate/2) / rate
+}
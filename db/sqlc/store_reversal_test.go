@@ -0,0 +1,201 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func TestStore_ReverseTransfer(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	admin, err := randomUser(ctx)
+	require.NoError(t, err)
+
+	source, err := randomAccountInCurrency(ctx, 1000, "USD")
+	require.NoError(t, err)
+
+	destination, err := randomAccountInCurrency(ctx, 0, "USD")
+	require.NoError(t, err)
+
+	original, err := store.TransferTx(ctx, TransferTxParams{FromAccountID: source.ID, ToAccountID: destination.ID, Amount: 100})
+	require.NoError(t, err)
+
+	partial, err := store.ReverseTransfer(ctx, ReverseTransferParams{
+		TransferID: original.Transfer.ID,
+		Amount:     40,
+		ReversedBy: admin.Username,
+		Reason:     "charged twice",
+	})
+	require.NoError(t, err)
+	require.Equal(t, destination.ID, partial.Transfer.FromAccountID)
+	require.Equal(t, source.ID, partial.Transfer.ToAccountID)
+	require.Equal(t, int64(40), partial.Transfer.Amount)
+	require.Equal(t, int64(940), partial.ToAccount.Balance)
+	require.Equal(t, int64(60), partial.FromAccount.Balance)
+	require.Equal(t, original.Transfer.ID, partial.Reversal.TransferID)
+	require.Equal(t, partial.Transfer.ID, partial.Reversal.ReversalTransferID)
+	require.Equal(t, admin.Username, partial.Reversal.ReversedBy)
+	require.Equal(t, TransferPartiallyReversed, partial.ReversalStatus)
+
+	_, err = store.ReverseTransfer(ctx, ReverseTransferParams{
+		TransferID: original.Transfer.ID,
+		Amount:     61,
+		ReversedBy: admin.Username,
+		Reason:     "too much",
+	})
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+
+	_, err = store.ReverseTransfer(ctx, ReverseTransferParams{
+		TransferID: partial.Transfer.ID,
+		ReversedBy: admin.Username,
+		Reason:     "undo the undo",
+	})
+	require.ErrorIs(t, err, ErrTransferIsReversal)
+
+	rest, err := store.ReverseTransfer(ctx, ReverseTransferParams{
+		TransferID: original.Transfer.ID,
+		ReversedBy: admin.Username,
+		Reason:     "order cancelled",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(60), rest.Transfer.Amount)
+	require.Equal(t, int64(1000), rest.ToAccount.Balance)
+	require.Equal(t, int64(0), rest.FromAccount.Balance)
+	require.Equal(t, TransferReversed, rest.ReversalStatus)
+
+	_, err = store.ReverseTransfer(ctx, ReverseTransferParams{
+		TransferID: original.Transfer.ID,
+		ReversedBy: admin.Username,
+		Reason:     "again",
+	})
+	require.ErrorIs(t, err, ErrTransferAlreadyReversed)
+
+	status, err := store.GetTransferReversalStatus(ctx, original.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), status.ReversedAmount)
+	require.Zero(t, status.ReversalOf)
+
+	status, err = store.GetTransferReversalStatus(ctx, rest.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, original.Transfer.ID, status.ReversalOf)
+
+	reversals, err := store.ListTransferReversals(ctx, original.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, []TransferReversal{partial.Reversal, rest.Reversal}, reversals)
+}
+
+func TestStore_ReverseTransferCrossCurrency(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	admin, err := randomUser(ctx)
+	require.NoError(t, err)
+
+	source, err := randomAccountInCurrency(ctx, 1000, "USD")
+	require.NoError(t, err)
+
+	destination, err := randomAccountInCurrency(ctx, 0, "EUR")
+	require.NoError(t, err)
+
+	original, err := store.TransferTx(ctx, TransferTxParams{
+		FromAccountID: source.ID,
+		ToAccountID:   destination.ID,
+		Amount:        100,
+		ToAmount:      91,
+		ExchangeRate:  920_000,
+		SpreadBps:     50,
+	})
+	require.NoError(t, err)
+
+	//Partial reversals are converted at the original rate and add up to what was credited
+	var debited int64
+	for _, amount := range []int64{33, 33, 34} {
+		result, err := store.ReverseTransfer(ctx, ReverseTransferParams{
+			TransferID: original.Transfer.ID,
+			Amount:     amount,
+			ReversedBy: admin.Username,
+			Reason:     "refund",
+		})
+		require.NoError(t, err)
+		require.Equal(t, amount, result.Transfer.ToAmount)
+		require.Equal(t, int64(1_086_957), result.Transfer.ExchangeRate)
+		debited += result.Transfer.Amount
+	}
+	require.Equal(t, int64(91), debited)
+
+	got, err := store.GetAccount(ctx, destination.ID)
+	require.NoError(t, err)
+	require.Zero(t, got.Balance)
+
+	got, err = store.GetAccount(ctx, source.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), got.Balance)
+}
+
+func TestStore_ReverseTransferFrozenDestination(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	admin, err := randomUser(ctx)
+	require.NoError(t, err)
+
+	source, err := randomAccountInCurrency(ctx, 1000, "USD")
+	require.NoError(t, err)
+
+	destination, err := randomAccountInCurrency(ctx, 0, "USD")
+	require.NoError(t, err)
+
+	original, err := store.TransferTx(ctx, TransferTxParams{FromAccountID: source.ID, ToAccountID: destination.ID, Amount: 100})
+	require.NoError(t, err)
+
+	_, err = store.FreezeAccount(ctx, destination.ID)
+	require.NoError(t, err)
+
+	//A frozen destination can still be debited by a reversal
+	result, err := store.ReverseTransfer(ctx, ReverseTransferParams{
+		TransferID: original.Transfer.ID,
+		Amount:     40,
+		ReversedBy: admin.Username,
+		Reason:     "fraud",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(60), result.FromAccount.Balance)
+	require.Equal(t, AccountStatusFrozen, result.FromAccount.Status)
+
+	//A closed one can't
+	_, err = store.UnfreezeAccount(ctx, destination.ID)
+	require.NoError(t, err)
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: destination.ID, ToAccountID: source.ID, Amount: 60})
+	require.NoError(t, err)
+	_, err = store.CloseAccount(ctx, destination.ID)
+	require.NoError(t, err)
+
+	_, err = store.ReverseTransfer(ctx, ReverseTransferParams{
+		TransferID: original.Transfer.ID,
+		ReversedBy: admin.Username,
+		Reason:     "fraud",
+	})
+	require.ErrorIs(t, err, ErrAccountClosed)
+}
+
+func Test_inverseRate(t *testing.T) {
+	require.Equal(t, int64(sameCurrencyRate), inverseRate(sameCurrencyRate))
+	require.Equal(t, int64(1_086_957), inverseRate(920_000))
+	require.Equal(t, int64(920_000), inverseRate(1_086_957))
+}
+
+func Test_reversedToAmount(t *testing.T) {
+	transfer := Transfer{Amount: 100, ToAmount: 91}
+
+	require.Equal(t, int64(0), reversedToAmount(transfer, 0))
+	require.Equal(t, int64(30), reversedToAmount(transfer, 33))
+	require.Equal(t, int64(60), reversedToAmount(transfer, 66))
+	require.Equal(t, int64(91), reversedToAmount(transfer, 100))
+
+	//The product of large amounts doesn't overflow
+	large := Transfer{Amount: math.MaxInt64, ToAmount: math.MaxInt64 - 1}
+	require.Equal(t, int64(math.MaxInt64/2-1), reversedToAmount(large, math.MaxInt64/2))
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+//Scheduled transfer statuses, only active ones are executed
+const (
+	ScheduledTransferStatusActive    = "active"
+	ScheduledTransferStatusPaused    = "paused"
+	ScheduledTransferStatusCompleted = "completed"
+	ScheduledTransferStatusFailed    = "failed"
+	ScheduledTransferStatusCancelled = "cancelled"
+)
+
+type (
+	//RecordScheduledTransferRunParams contains the outcome of an attempt and the state the scheduled transfer moves to
+	RecordScheduledTransferRunParams struct {
+		Run          CreateScheduledTransferRunParams `json:"run"`
+		ScheduledFor time.Time                        `json:"scheduled_for"`
+		NextRunAt    time.Time                        `json:"next_run_at"`
+		Status       string                           `json:"status"`
+		Attempts     int32                            `json:"attempts"`
+		LastError    string                           `json:"last_error"`
+	}
+	//RecordScheduledTransferRunResult is the result of the record scheduled transfer run transaction
+	RecordScheduledTransferRunResult struct {
+		Run               ScheduledTransferRun `json:"run"`
+		ScheduledTransfer ScheduledTransfer    `json:"scheduled_transfer"`
+	}
+)
+
+//RecordScheduledTransferRun stores an attempt and releases the claim on the scheduled transfer within a single database transaction.
+//A scheduled transfer paused or cancelled while it was executing keeps its status.
+func (s SQLStore) RecordScheduledTransferRun(ctx context.Context, params RecordScheduledTransferRunParams) (result RecordScheduledTransferRunResult, err error) {
+	err = s.execTx(ctx, func(queries *Queries) error {
+		if result.Run, err = queries.CreateScheduledTransferRun(ctx, params.Run); err != nil {
+			return err
+		}
+
+		result.ScheduledTransfer, err = queries.UpdateScheduledTransferRunState(ctx, UpdateScheduledTransferRunStateParams{
+			ScheduledFor: params.ScheduledFor,
+			NextRunAt:    params.NextRunAt,
+			Status:       params.Status,
+			Attempts:     params.Attempts,
+			LastError:    params.LastError,
+			ID:           params.Run.ScheduledTransferID,
+		})
+		return err
+	})
+
+	return result, err
+}
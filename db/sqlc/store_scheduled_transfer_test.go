@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestStore_RecordScheduledTransferRun(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	account, err := randomAccountWithBalance(ctx, 100)
+	require.NoError(t, err)
+
+	landlord, err := randomAccount(ctx)
+	require.NoError(t, err)
+
+	scheduledFor := time.Date(2000, time.January, 31, 9, 0, 0, 0, time.UTC)
+	st, err := store.CreateScheduledTransfer(ctx, CreateScheduledTransferParams{
+		Owner:         account.Owner,
+		FromAccountID: account.ID,
+		ToAccountID:   landlord.ID,
+		Amount:        60,
+		Recurrence:    "FREQ=MONTHLY;BYMONTHDAY=31",
+		ScheduledFor:  scheduledFor,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferStatusActive, st.Status)
+	require.WithinDuration(t, scheduledFor, st.NextRunAt, time.Second)
+	require.False(t, st.EndAt.Valid)
+
+	claimParams := ClaimDueScheduledTransfersParams{
+		ClaimedUntil: sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true},
+		Limit:        1000,
+	}
+	claimed, err := store.ClaimDueScheduledTransfers(ctx, claimParams)
+	require.NoError(t, err)
+	require.Contains(t, scheduledTransferIDs(claimed), st.ID)
+
+	//A claimed scheduled transfer is hidden from other workers until its lease expires
+	claimed, err = store.ClaimDueScheduledTransfers(ctx, claimParams)
+	require.NoError(t, err)
+	require.NotContains(t, scheduledTransferIDs(claimed), st.ID)
+
+	next := time.Date(2000, time.February, 29, 9, 0, 0, 0, time.UTC)
+	result, err := store.RecordScheduledTransferRun(ctx, RecordScheduledTransferRunParams{
+		Run: CreateScheduledTransferRunParams{
+			ScheduledTransferID: st.ID,
+			ScheduledFor:        scheduledFor,
+			Attempt:             1,
+			Error:               ErrInsufficientFunds.Error(),
+		},
+		ScheduledFor: next,
+		NextRunAt:    next,
+		Status:       ScheduledTransferStatusActive,
+		LastError:    ErrInsufficientFunds.Error(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, st.ID, result.Run.ScheduledTransferID)
+	require.False(t, result.Run.TransferID.Valid)
+	require.WithinDuration(t, next, result.ScheduledTransfer.NextRunAt, time.Second)
+	require.False(t, result.ScheduledTransfer.ClaimedUntil.Valid)
+
+	runs, err := store.ListScheduledTransferRuns(ctx, st.ID)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, result.Run, runs[0])
+
+	//A scheduled transfer cancelled while executing keeps its status
+	_, err = store.UpdateScheduledTransfer(ctx, UpdateScheduledTransferParams{
+		Status: sql.NullString{String: ScheduledTransferStatusCancelled, Valid: true},
+		ID:     st.ID,
+	})
+	require.NoError(t, err)
+
+	result, err = store.RecordScheduledTransferRun(ctx, RecordScheduledTransferRunParams{
+		Run: CreateScheduledTransferRunParams{
+			ScheduledTransferID: st.ID,
+			ScheduledFor:        next,
+			Attempt:             1,
+		},
+		ScheduledFor: next,
+		NextRunAt:    next,
+		Status:       ScheduledTransferStatusActive,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferStatusCancelled, result.ScheduledTransfer.Status)
+}
+
+func scheduledTransferIDs(scheduledTransfers []ScheduledTransfer) []int64 {
+	ids := make([]int64, 0, len(scheduledTransfers))
+	for _, st := range scheduledTransfers {
+		ids = append(ids, st.ID)
+	}
+	return ids
+}
@@ -11,10 +11,10 @@ func TestStore_TransferTx(t *testing.T) {
 	store := NewStore(testDb)
 	ctx := context.Background()
 
-	fromAcc, err := randomAccount(ctx)
+	fromAcc, err := randomAccountWithBalance(ctx, 1000)
 	require.NoError(t, err)
 
-	toAcc, err := randomAccount(ctx)
+	toAcc, err := randomAccountWithBalance(ctx, 1000)
 	require.NoError(t, err)
 
 	//Run n concurrent transfer transactions
@@ -48,6 +48,8 @@ func TestStore_TransferTx(t *testing.T) {
 		require.Equal(t, fromAcc.ID, transfer.FromAccountID)
 		require.Equal(t, toAcc.ID, transfer.ToAccountID)
 		require.Equal(t, amount, transfer.Amount)
+		require.Equal(t, amount, transfer.ToAmount)
+		require.Equal(t, int64(sameCurrencyRate), transfer.ExchangeRate)
 		require.NotZero(t, transfer.ID)
 		require.NotZero(t, transfer.CreatedAt)
 
@@ -63,6 +65,10 @@ func TestStore_TransferTx(t *testing.T) {
 		require.NotZero(t, toEntry.CreatedAt)
 		require.Equal(t, amount, toEntry.Amount)
 		require.Equal(t, toAcc.ID, toEntry.AccountID)
+		require.NotZero(t, fromEntry.JournalID)
+		require.Equal(t, fromEntry.JournalID, toEntry.JournalID)
+		require.Equal(t, transfer.ID, fromEntry.TransferID.Int64)
+		require.Equal(t, transfer.ID, toEntry.TransferID.Int64)
 
 		//accounts assertions
 		fromAccResult := result.FromAccount
@@ -76,15 +82,119 @@ func TestStore_TransferTx(t *testing.T) {
 	}
 }
 
+func TestStore_TransferTxCrossCurrency(t *testing.T) {
+	t.Parallel()
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	fromAcc, err := randomAccountInCurrency(ctx, 1000, "USD")
+	require.NoError(t, err)
+
+	toAcc, err := randomAccountInCurrency(ctx, 1000, "EUR")
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(ctx, TransferTxParams{
+		FromAccountID: fromAcc.ID,
+		ToAccountID:   toAcc.ID,
+		Amount:        100,
+		ToAmount:      91,
+		ExchangeRate:  920_000,
+		SpreadBps:     50,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(100), result.Transfer.Amount)
+	require.Equal(t, int64(91), result.Transfer.ToAmount)
+	require.Equal(t, int64(920_000), result.Transfer.ExchangeRate)
+	require.Equal(t, int32(50), result.Transfer.SpreadBps)
+
+	require.Equal(t, int64(-100), result.FromEntry.Amount)
+	require.Equal(t, int64(91), result.ToEntry.Amount)
+	require.Equal(t, int64(900), result.FromAccount.Balance)
+	require.Equal(t, int64(1091), result.ToAccount.Balance)
+	require.Equal(t, result.FromEntry.JournalID, result.ToEntry.JournalID)
+
+	//The FX position accounts take the other side of both legs
+	usdPosition, err := testQueries.GetSystemAccountID(ctx, GetSystemAccountIDParams{Purpose: SystemAccountFXPosition, Currency: "USD"})
+	require.NoError(t, err)
+	eurPosition, err := testQueries.GetSystemAccountID(ctx, GetSystemAccountIDParams{Purpose: SystemAccountFXPosition, Currency: "EUR"})
+	require.NoError(t, err)
+
+	entries, err := testQueries.ListEntriesByJournal(ctx, result.FromEntry.JournalID)
+	require.NoError(t, err)
+	amounts := make(map[int64]int64, len(entries))
+	for _, entry := range entries {
+		amounts[entry.AccountID] += entry.Amount
+	}
+	require.Len(t, entries, 4)
+	require.Equal(t, int64(100), amounts[usdPosition])
+	require.Equal(t, int64(-91), amounts[eurPosition])
+}
+
+func TestStore_TransferTxCrossCurrencyBothWays(t *testing.T) {
+	t.Parallel()
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	usdAcc, err := randomAccountInCurrency(ctx, 1000, "USD")
+	require.NoError(t, err)
+
+	eurAcc, err := randomAccountInCurrency(ctx, 1000, "EUR")
+	require.NoError(t, err)
+
+	//Each direction leaves one FX position positive, which the overdraft checks of the accounts must accept
+	_, err = store.TransferTx(ctx, TransferTxParams{
+		FromAccountID: usdAcc.ID,
+		ToAccountID:   eurAcc.ID,
+		Amount:        100,
+		ToAmount:      91,
+		ExchangeRate:  920_000,
+	})
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(ctx, TransferTxParams{
+		FromAccountID: eurAcc.ID,
+		ToAccountID:   usdAcc.ID,
+		Amount:        200,
+		ToAmount:      216,
+		ExchangeRate:  1_086_956,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1116), result.ToAccount.Balance)
+	require.Equal(t, int64(891), result.FromAccount.Balance)
+}
+
+func TestStore_TransferTxUnbalanced(t *testing.T) {
+	t.Parallel()
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	fromAcc, err := randomAccountInCurrency(ctx, 1000, "USD")
+	require.NoError(t, err)
+
+	toAcc, err := randomAccountInCurrency(ctx, 1000, "USD")
+	require.NoError(t, err)
+
+	//Same currency legs can't be converted, so they must move the same amount
+	_, err = store.TransferTx(ctx, TransferTxParams{
+		FromAccountID: fromAcc.ID,
+		ToAccountID:   toAcc.ID,
+		Amount:        100,
+		ToAmount:      91,
+		ExchangeRate:  920_000,
+	})
+	require.ErrorIs(t, err, ErrUnbalancedJournal)
+}
+
 func TestStore_TransferTxDeadLock(t *testing.T) {
 	t.Parallel()
 	store := NewStore(testDb)
 	ctx := context.Background()
 
-	fromAcc, err := randomAccount(ctx)
+	fromAcc, err := randomAccountWithBalance(ctx, 1000)
 	require.NoError(t, err)
 
-	toAcc, err := randomAccount(ctx)
+	toAcc, err := randomAccountWithBalance(ctx, 1000)
 	require.NoError(t, err)
 
 	//Run n concurrent transfer transactions
@@ -128,3 +238,61 @@ func TestStore_TransferTxDeadLock(t *testing.T) {
 
 	require.Equal(t, toAcc.Balance, a.Balance)
 }
+
+func TestStore_TransferTxValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		fromBalance int64
+		amount      int64
+		wantErr     error
+	}{
+		{
+			name:        "When amount is zero",
+			fromBalance: 100,
+			amount:      0,
+			wantErr:     ErrInvalidAmount,
+		},
+		{
+			name:        "When amount is negative",
+			fromBalance: 100,
+			amount:      -10,
+			wantErr:     ErrInvalidAmount,
+		},
+		{
+			name:        "When source account has insufficient funds",
+			fromBalance: 5,
+			amount:      10,
+			wantErr:     ErrInsufficientFunds,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := NewStore(testDb)
+			ctx := context.Background()
+
+			fromAcc, err := randomAccountWithBalance(ctx, tt.fromBalance)
+			require.NoError(t, err)
+
+			toAcc, err := randomAccountWithBalance(ctx, 0)
+			require.NoError(t, err)
+
+			_, err = store.TransferTx(ctx, TransferTxParams{
+				FromAccountID: fromAcc.ID,
+				ToAccountID:   toAcc.ID,
+				Amount:        tt.amount,
+			})
+			require.ErrorIs(t, err, tt.wantErr)
+
+			//Balances must be untouched
+			a, err := store.GetAccount(ctx, fromAcc.ID)
+			require.NoError(t, err)
+			require.Equal(t, fromAcc.Balance, a.Balance)
+
+			a, err = store.GetAccount(ctx, toAcc.ID)
+			require.NoError(t, err)
+			require.Equal(t, toAcc.Balance, a.Balance)
+		})
+	}
+}
@@ -0,0 +1,432 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	//TransferBatchModeAllOrNothing books every transfer of the batch in a single transaction or none of them
+	TransferBatchModeAllOrNothing = "all_or_nothing"
+	//TransferBatchModeBestEffort books each transfer of the batch on its own, a rejected one doesn't stop the others
+	TransferBatchModeBestEffort = "best_effort"
+)
+
+//Transfer batch statuses, pending and processing batches are picked up by the batch worker
+const (
+	TransferBatchStatusPending            = "pending"
+	TransferBatchStatusProcessing         = "processing"
+	TransferBatchStatusCompleted          = "completed"
+	TransferBatchStatusPartiallyCompleted = "partially_completed"
+	TransferBatchStatusFailed             = "failed"
+)
+
+//Transfer batch item statuses
+const (
+	TransferBatchItemPending   = "pending"
+	TransferBatchItemSucceeded = "succeeded"
+	TransferBatchItemFailed    = "failed"
+)
+
+var (
+	//ErrEmptyTransferBatch is returned when submitting a batch without transfers
+	ErrEmptyTransferBatch = errors.New("transfer batch has no transfers")
+	//ErrInvalidTransferBatchMode is returned when submitting a batch with an unknown mode
+	ErrInvalidTransferBatchMode = errors.New("invalid transfer batch mode")
+	//ErrTransferBatchSameAccount is returned when a transfer of the batch goes back to its source account
+	ErrTransferBatchSameAccount = errors.New("transfer batch can't pay its source account")
+	//ErrTransferBatchCurrencyMismatch is returned for a transfer to an account in another currency, batches are not converted
+	ErrTransferBatchCurrencyMismatch = errors.New("destination account currency differs from the batch source account")
+)
+
+type (
+	//TransferBatchItemParams is one transfer of a batch
+	TransferBatchItemParams struct {
+		ToAccountID int64 `json:"to_account_id"`
+		Amount      int64 `json:"amount"`
+	}
+	//SubmitTransferBatchParams contains the input parameters of the submit transfer batch transaction
+	SubmitTransferBatchParams struct {
+		Owner         string                    `json:"owner"`
+		FromAccountID int64                     `json:"from_account_id"`
+		Mode          string                    `json:"mode"`
+		Items         []TransferBatchItemParams `json:"items"`
+	}
+	//TransferBatchResult is a batch with its transfers in submission order
+	TransferBatchResult struct {
+		Batch TransferBatch       `json:"batch"`
+		Items []TransferBatchItem `json:"items"`
+	}
+)
+
+//SubmitTransferBatch checks the batch total against the available balance of the source account and stores the
+//batch as pending within a single database transaction. Its transfers are booked later by ProcessTransferBatch.
+func (s SQLStore) SubmitTransferBatch(ctx context.Context, params SubmitTransferBatchParams) (result TransferBatchResult, err error) {
+	if len(params.Items) == 0 {
+		return result, ErrEmptyTransferBatch
+	}
+	if params.Mode != TransferBatchModeAllOrNothing && params.Mode != TransferBatchModeBestEffort {
+		return result, ErrInvalidTransferBatchMode
+	}
+
+	var total int64
+	for _, item := range params.Items {
+		if item.Amount <= 0 {
+			return result, ErrInvalidAmount
+		}
+		if item.ToAccountID == params.FromAccountID {
+			return result, ErrTransferBatchSameAccount
+		}
+		//No balance can cover a total past the largest amount, so it is rejected before it wraps around
+		if item.Amount > math.MaxInt64-total {
+			return result, ErrInsufficientFunds
+		}
+		total += item.Amount
+	}
+
+	err = s.execTx(ctx, func(queries *Queries) error {
+		//Overdue holds must not count against the batch total
+		from, err := queries.ExpireAccountHolds(ctx, params.FromAccountID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkAccountActive(from); err != nil {
+			return err
+		}
+		if availableBalance(from) < total {
+			return ErrInsufficientFunds
+		}
+
+		if result.Batch, err = queries.CreateTransferBatch(ctx, CreateTransferBatchParams{
+			Owner:         params.Owner,
+			FromAccountID: params.FromAccountID,
+			Mode:          params.Mode,
+			TotalAmount:   total,
+			ItemsCount:    int32(len(params.Items)),
+		}); err != nil {
+			return err
+		}
+
+		result.Items = make([]TransferBatchItem, 0, len(params.Items))
+		for i, item := range params.Items {
+			created, err := queries.CreateTransferBatchItem(ctx, CreateTransferBatchItemParams{
+				BatchID:     result.Batch.ID,
+				Position:    int32(i),
+				ToAccountID: item.ToAccountID,
+				Amount:      item.Amount,
+			})
+			if err != nil {
+				return err
+			}
+			result.Items = append(result.Items, created)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+//ProcessTransferBatch books the transfers of a pending or processing batch and records the outcome of each.
+//Finished batches are returned as they are, so a batch claimed again once its lease expired is never paid twice.
+//Errors other than a rejected transfer leave the batch unfinished to be processed again.
+func (s SQLStore) ProcessTransferBatch(ctx context.Context, id int64) (result TransferBatchResult, err error) {
+	batch, err := s.GetTransferBatch(ctx, id)
+	if err != nil {
+		return result, err
+	}
+
+	if !transferBatchFinished(batch) {
+		switch batch.Mode {
+		case TransferBatchModeAllOrNothing:
+			err = s.processAllOrNothing(ctx, batch.ID)
+		default:
+			err = s.processBestEffort(ctx, batch.ID)
+		}
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if result.Batch, err = s.GetTransferBatch(ctx, id); err != nil {
+		return result, err
+	}
+	result.Items, err = s.ListTransferBatchItems(ctx, id)
+	return result, err
+}
+
+//processAllOrNothing books every transfer of the batch in one transaction. When one is rejected the transaction
+//is rolled back and the batch fails with the rejection recorded on its transfers.
+func (s SQLStore) processAllOrNothing(ctx context.Context, id int64) error {
+	var rejected *TransferBatchItem
+	var rejection error
+
+	err := s.execTx(ctx, func(queries *Queries) error {
+		batch, err := queries.GetTransferBatchForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if transferBatchFinished(batch) {
+			return nil
+		}
+
+		items, err := queries.ListTransferBatchItems(ctx, batch.ID)
+		if err != nil {
+			return err
+		}
+
+		//Every account is locked up front in ascending id order, so batches sharing accounts can't deadlock
+		ids := []int64{batch.FromAccountID}
+		for _, item := range items {
+			ids = append(ids, item.ToAccountID)
+		}
+		if _, err := lockAccountIDs(ctx, queries, ids); err != nil {
+			return err
+		}
+
+		from, err := queries.ExpireAccountHolds(ctx, batch.FromAccountID)
+		if err != nil {
+			return err
+		}
+		if availableBalance(from) < batch.TotalAmount {
+			rejection = ErrInsufficientFunds
+			return rejection
+		}
+
+		for i, item := range items {
+			transferred, err := batchTransfer(ctx, queries, batch, item)
+			if err != nil {
+				if isTransferRejection(err) {
+					rejected, rejection = &items[i], err
+				}
+				return err
+			}
+
+			if _, err := queries.UpdateTransferBatchItem(ctx, UpdateTransferBatchItemParams{
+				ID:         item.ID,
+				Status:     TransferBatchItemSucceeded,
+				TransferID: sql.NullInt64{Int64: transferred.Transfer.ID, Valid: true},
+			}); err != nil {
+				return err
+			}
+		}
+
+		_, err = queries.FinishTransferBatch(ctx, FinishTransferBatchParams{
+			ID:             batch.ID,
+			Status:         TransferBatchStatusCompleted,
+			SucceededCount: batch.ItemsCount,
+		})
+		return err
+	})
+	if rejection == nil {
+		return err
+	}
+
+	return s.failTransferBatch(ctx, id, rejected, rejection)
+}
+
+//failTransferBatch records why an all or nothing batch was rejected, on the rejected transfer when there is one
+//and as the reason the other transfers were not booked
+func (s SQLStore) failTransferBatch(ctx context.Context, id int64, rejected *TransferBatchItem, rejection error) error {
+	return s.execTx(ctx, func(queries *Queries) error {
+		batch, err := queries.GetTransferBatchForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if transferBatchFinished(batch) {
+			return nil
+		}
+
+		items, err := queries.ListTransferBatchItems(ctx, batch.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			reason := rejection.Error()
+			if rejected != nil && item.ID != rejected.ID {
+				reason = fmt.Sprintf("not booked, transfer %d of the batch failed", rejected.Position)
+			}
+
+			if _, err := queries.UpdateTransferBatchItem(ctx, UpdateTransferBatchItemParams{
+				ID:     item.ID,
+				Status: TransferBatchItemFailed,
+				Error:  reason,
+			}); err != nil {
+				return err
+			}
+		}
+
+		_, err = queries.FinishTransferBatch(ctx, FinishTransferBatchParams{
+			ID:          batch.ID,
+			Status:      TransferBatchStatusFailed,
+			FailedCount: batch.ItemsCount,
+		})
+		return err
+	})
+}
+
+//processBestEffort books each pending transfer of the batch in its own transaction, in submission order,
+//then finishes the batch according to how many transfers succeeded
+func (s SQLStore) processBestEffort(ctx context.Context, id int64) error {
+	items, err := s.ListTransferBatchItems(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if item.Status != TransferBatchItemPending {
+			continue
+		}
+		if err := s.processBatchItem(ctx, item); err != nil {
+			return err
+		}
+	}
+
+	return s.execTx(ctx, func(queries *Queries) error {
+		batch, err := queries.GetTransferBatchForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if transferBatchFinished(batch) {
+			return nil
+		}
+
+		items, err := queries.ListTransferBatchItems(ctx, batch.ID)
+		if err != nil {
+			return err
+		}
+
+		var succeeded, failed int32
+		for _, item := range items {
+			switch item.Status {
+			case TransferBatchItemSucceeded:
+				succeeded++
+			case TransferBatchItemFailed:
+				failed++
+			}
+		}
+
+		status := TransferBatchStatusPartiallyCompleted
+		switch {
+		case succeeded == batch.ItemsCount:
+			status = TransferBatchStatusCompleted
+		case failed == batch.ItemsCount:
+			status = TransferBatchStatusFailed
+		}
+
+		_, err = queries.FinishTransferBatch(ctx, FinishTransferBatchParams{
+			ID:             batch.ID,
+			Status:         status,
+			SucceededCount: succeeded,
+			FailedCount:    failed,
+		})
+		return err
+	})
+}
+
+//processBatchItem books one transfer of a best effort batch, a rejected transfer is recorded as failed
+func (s SQLStore) processBatchItem(ctx context.Context, item TransferBatchItem) error {
+	var rejection error
+
+	err := s.execTx(ctx, func(queries *Queries) error {
+		//Locking the item makes a worker whose lease expired skip the transfers booked meanwhile by another
+		item, err := queries.GetTransferBatchItemForUpdate(ctx, item.ID)
+		if err != nil {
+			return err
+		}
+		if item.Status != TransferBatchItemPending {
+			return nil
+		}
+
+		batch, err := queries.GetTransferBatch(ctx, item.BatchID)
+		if err != nil {
+			return err
+		}
+
+		transferred, err := batchTransfer(ctx, queries, batch, item)
+		if err != nil {
+			if isTransferRejection(err) {
+				rejection = err
+			}
+			return err
+		}
+
+		_, err = queries.UpdateTransferBatchItem(ctx, UpdateTransferBatchItemParams{
+			ID:         item.ID,
+			Status:     TransferBatchItemSucceeded,
+			TransferID: sql.NullInt64{Int64: transferred.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+	if rejection == nil {
+		return err
+	}
+
+	return s.execTx(ctx, func(queries *Queries) error {
+		item, err := queries.GetTransferBatchItemForUpdate(ctx, item.ID)
+		if err != nil {
+			return err
+		}
+		if item.Status != TransferBatchItemPending {
+			return nil
+		}
+
+		_, err = queries.UpdateTransferBatchItem(ctx, UpdateTransferBatchItemParams{
+			ID:     item.ID,
+			Status: TransferBatchItemFailed,
+			Error:  rejection.Error(),
+		})
+		return err
+	})
+}
+
+//batchTransfer books one transfer of the batch with the checks of a single transfer.
+//Destinations in another currency are rejected since batches are not converted.
+func batchTransfer(ctx context.Context, queries *Queries, batch TransferBatch, item TransferBatchItem) (TransferTxResult, error) {
+	from, to, err := lockAccounts(ctx, queries, batch.FromAccountID, item.ToAccountID)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	if from.Currency != to.Currency {
+		return TransferTxResult{}, fmt.Errorf("%w: account %d holds %s", ErrTransferBatchCurrencyMismatch, to.ID, to.Currency)
+	}
+
+	result, err := transfer(ctx, queries, TransferTxParams{
+		FromAccountID: batch.FromAccountID,
+		ToAccountID:   item.ToAccountID,
+		Amount:        item.Amount,
+	})
+	return result, translateConstraintError(err)
+}
+
+//isTransferRejection tells whether err rejects the transfer itself, other errors leave it pending to be retried
+func isTransferRejection(err error) bool {
+	for _, rejection := range []error{
+		ErrInvalidAmount,
+		ErrInsufficientFunds,
+		ErrAccountFrozen,
+		ErrAccountClosed,
+		ErrTransferLimitExceeded,
+		ErrTransferBatchCurrencyMismatch,
+	} {
+		if errors.Is(err, rejection) {
+			return true
+		}
+	}
+	return false
+}
+
+func transferBatchFinished(batch TransferBatch) bool {
+	switch batch.Status {
+	case TransferBatchStatusCompleted, TransferBatchStatusPartiallyCompleted, TransferBatchStatusFailed:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,307 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func TestStore_TransferBatchAllOrNothing(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	source, err := randomAccountInCurrency(ctx, 1000, "USD")
+	require.NoError(t, err)
+	first, err := randomAccountInCurrency(ctx, 0, "USD")
+	require.NoError(t, err)
+	second, err := randomAccountInCurrency(ctx, 0, "USD")
+	require.NoError(t, err)
+
+	submitted, err := store.SubmitTransferBatch(ctx, SubmitTransferBatchParams{
+		Owner:         source.Owner,
+		FromAccountID: source.ID,
+		Mode:          TransferBatchModeAllOrNothing,
+		Items: []TransferBatchItemParams{
+			{ToAccountID: first.ID, Amount: 300},
+			{ToAccountID: second.ID, Amount: 200},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferBatchStatusPending, submitted.Batch.Status)
+	require.Equal(t, int64(500), submitted.Batch.TotalAmount)
+	require.Equal(t, int32(2), submitted.Batch.ItemsCount)
+	require.Len(t, submitted.Items, 2)
+	require.Equal(t, int32(1), submitted.Items[1].Position)
+	require.Equal(t, TransferBatchItemPending, submitted.Items[1].Status)
+
+	processed, err := store.ProcessTransferBatch(ctx, submitted.Batch.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferBatchStatusCompleted, processed.Batch.Status)
+	require.Equal(t, int32(2), processed.Batch.SucceededCount)
+	require.True(t, processed.Batch.FinishedAt.Valid)
+	for _, item := range processed.Items {
+		require.Equal(t, TransferBatchItemSucceeded, item.Status)
+		require.True(t, item.TransferID.Valid)
+
+		transfer, err := store.GetTransfer(ctx, item.TransferID.Int64)
+		require.NoError(t, err)
+		require.Equal(t, source.ID, transfer.FromAccountID)
+		require.Equal(t, item.ToAccountID, transfer.ToAccountID)
+		require.Equal(t, item.Amount, transfer.Amount)
+	}
+
+	//Processing a finished batch again must not pay twice
+	again, err := store.ProcessTransferBatch(ctx, submitted.Batch.ID)
+	require.NoError(t, err)
+	require.Equal(t, processed.Items, again.Items)
+
+	source, err = store.GetAccount(ctx, source.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(500), source.Balance)
+}
+
+func TestStore_TransferBatchAllOrNothingRejected(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	source, err := randomAccountInCurrency(ctx, 1000, "USD")
+	require.NoError(t, err)
+	open, err := randomAccountInCurrency(ctx, 0, "USD")
+	require.NoError(t, err)
+	frozen, err := randomAccountInCurrency(ctx, 0, "USD")
+	require.NoError(t, err)
+
+	submitted, err := store.SubmitTransferBatch(ctx, SubmitTransferBatchParams{
+		Owner:         source.Owner,
+		FromAccountID: source.ID,
+		Mode:          TransferBatchModeAllOrNothing,
+		Items: []TransferBatchItemParams{
+			{ToAccountID: open.ID, Amount: 300},
+			{ToAccountID: frozen.ID, Amount: 200},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = store.FreezeAccount(ctx, frozen.ID)
+	require.NoError(t, err)
+
+	processed, err := store.ProcessTransferBatch(ctx, submitted.Batch.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferBatchStatusFailed, processed.Batch.Status)
+	require.Equal(t, int32(2), processed.Batch.FailedCount)
+	require.Zero(t, processed.Batch.SucceededCount)
+
+	require.Equal(t, TransferBatchItemFailed, processed.Items[0].Status)
+	require.False(t, processed.Items[0].TransferID.Valid)
+	require.Equal(t, "not booked, transfer 1 of the batch failed", processed.Items[0].Error)
+	require.Equal(t, TransferBatchItemFailed, processed.Items[1].Status)
+	require.Equal(t, ErrAccountFrozen.Error(), processed.Items[1].Error)
+
+	source, err = store.GetAccount(ctx, source.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), source.Balance)
+
+	open, err = store.GetAccount(ctx, open.ID)
+	require.NoError(t, err)
+	require.Zero(t, open.Balance)
+}
+
+func TestStore_TransferBatchBestEffort(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	source, err := randomAccountInCurrency(ctx, 1000, "USD")
+	require.NoError(t, err)
+	open, err := randomAccountInCurrency(ctx, 0, "USD")
+	require.NoError(t, err)
+	frozen, err := randomAccountInCurrency(ctx, 0, "USD")
+	require.NoError(t, err)
+	euro, err := randomAccountInCurrency(ctx, 0, "EUR")
+	require.NoError(t, err)
+
+	submitted, err := store.SubmitTransferBatch(ctx, SubmitTransferBatchParams{
+		Owner:         source.Owner,
+		FromAccountID: source.ID,
+		Mode:          TransferBatchModeBestEffort,
+		Items: []TransferBatchItemParams{
+			{ToAccountID: frozen.ID, Amount: 200},
+			{ToAccountID: open.ID, Amount: 300},
+			{ToAccountID: euro.ID, Amount: 100},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = store.FreezeAccount(ctx, frozen.ID)
+	require.NoError(t, err)
+
+	processed, err := store.ProcessTransferBatch(ctx, submitted.Batch.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferBatchStatusPartiallyCompleted, processed.Batch.Status)
+	require.Equal(t, int32(1), processed.Batch.SucceededCount)
+	require.Equal(t, int32(2), processed.Batch.FailedCount)
+
+	require.Equal(t, TransferBatchItemFailed, processed.Items[0].Status)
+	require.Equal(t, ErrAccountFrozen.Error(), processed.Items[0].Error)
+	require.Equal(t, TransferBatchItemSucceeded, processed.Items[1].Status)
+	require.True(t, processed.Items[1].TransferID.Valid)
+	require.Equal(t, TransferBatchItemFailed, processed.Items[2].Status)
+	require.Contains(t, processed.Items[2].Error, ErrTransferBatchCurrencyMismatch.Error())
+
+	source, err = store.GetAccount(ctx, source.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(700), source.Balance)
+}
+
+func TestStore_SubmitTransferBatchValidation(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	source, err := randomAccountInCurrency(ctx, 100, "USD")
+	require.NoError(t, err)
+	destination, err := randomAccountInCurrency(ctx, 0, "USD")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		params  SubmitTransferBatchParams
+		wantErr error
+	}{
+		{
+			name: "TotalExceedsAvailableBalance",
+			params: SubmitTransferBatchParams{
+				Mode: TransferBatchModeBestEffort,
+				Items: []TransferBatchItemParams{
+					{ToAccountID: destination.ID, Amount: 60},
+					{ToAccountID: destination.ID, Amount: 60},
+				},
+			},
+			wantErr: ErrInsufficientFunds,
+		},
+		{
+			name: "TotalOverflows",
+			params: SubmitTransferBatchParams{
+				Mode: TransferBatchModeBestEffort,
+				Items: []TransferBatchItemParams{
+					{ToAccountID: destination.ID, Amount: math.MaxInt64},
+					{ToAccountID: destination.ID, Amount: math.MaxInt64},
+				},
+			},
+			wantErr: ErrInsufficientFunds,
+		},
+		{
+			name: "Empty",
+			params: SubmitTransferBatchParams{
+				Mode: TransferBatchModeBestEffort,
+			},
+			wantErr: ErrEmptyTransferBatch,
+		},
+		{
+			name: "InvalidMode",
+			params: SubmitTransferBatchParams{
+				Mode:  "whatever",
+				Items: []TransferBatchItemParams{{ToAccountID: destination.ID, Amount: 10}},
+			},
+			wantErr: ErrInvalidTransferBatchMode,
+		},
+		{
+			name: "SameAccount",
+			params: SubmitTransferBatchParams{
+				Mode:  TransferBatchModeAllOrNothing,
+				Items: []TransferBatchItemParams{{ToAccountID: source.ID, Amount: 10}},
+			},
+			wantErr: ErrTransferBatchSameAccount,
+		},
+		{
+			name: "InvalidAmount",
+			params: SubmitTransferBatchParams{
+				Mode:  TransferBatchModeAllOrNothing,
+				Items: []TransferBatchItemParams{{ToAccountID: destination.ID, Amount: 0}},
+			},
+			wantErr: ErrInvalidAmount,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.params.Owner = source.Owner
+			tt.params.FromAccountID = source.ID
+
+			_, err := store.SubmitTransferBatch(ctx, tt.params)
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestStore_TransferBatchConcurrentNoDeadlock(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	first, err := randomAccountInCurrency(ctx, 1000, "USD")
+	require.NoError(t, err)
+	second, err := randomAccountInCurrency(ctx, 1000, "USD")
+	require.NoError(t, err)
+	third, err := randomAccountInCurrency(ctx, 1000, "USD")
+	require.NoError(t, err)
+
+	//Batches paying each other's accounts in opposite orders would deadlock without a global lock order
+	n := 5
+	ids := make([]int64, 0, 2*n)
+	for i := 0; i < n; i++ {
+		forward, err := store.SubmitTransferBatch(ctx, SubmitTransferBatchParams{
+			Owner:         first.Owner,
+			FromAccountID: first.ID,
+			Mode:          TransferBatchModeAllOrNothing,
+			Items: []TransferBatchItemParams{
+				{ToAccountID: second.ID, Amount: 10},
+				{ToAccountID: third.ID, Amount: 10},
+			},
+		})
+		require.NoError(t, err)
+
+		backward, err := store.SubmitTransferBatch(ctx, SubmitTransferBatchParams{
+			Owner:         third.Owner,
+			FromAccountID: third.ID,
+			Mode:          TransferBatchModeAllOrNothing,
+			Items: []TransferBatchItemParams{
+				{ToAccountID: second.ID, Amount: 10},
+				{ToAccountID: first.ID, Amount: 10},
+			},
+		})
+		require.NoError(t, err)
+
+		ids = append(ids, forward.Batch.ID, backward.Batch.ID)
+	}
+
+	errs := make(chan error)
+	for _, id := range ids {
+		go func(id int64) {
+			_, err := store.ProcessTransferBatch(ctx, id)
+			errs <- err
+		}(id)
+	}
+	for range ids {
+		require.NoError(t, <-errs)
+	}
+
+	for _, id := range ids {
+		batch, err := store.GetTransferBatch(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, TransferBatchStatusCompleted, batch.Status)
+	}
+
+	first, err = store.GetAccount(ctx, first.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000-n*20+n*10), first.Balance)
+
+	second, err = store.GetAccount(ctx, second.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000+n*20), second.Balance)
+
+	third, err = store.GetAccount(ctx, third.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000-n*20+n*10), third.Balance)
+}
@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+//ErrTransferLimitExceeded is returned when a transfer would exceed a daily or monthly limit of its account or owner
+var ErrTransferLimitExceeded = errors.New("transfer limit exceeded")
+
+type (
+	//LimitUsage is how much of a limit is used in its current window, a nil Limit is unlimited
+	LimitUsage struct {
+		Limit *int64 `json:"limit"`
+		Used  int64  `json:"used"`
+	}
+	//TransferLimitsUsage is the usage of the limits of an account or of its owner in the account currency
+	TransferLimitsUsage struct {
+		DailyAmount   LimitUsage `json:"daily_amount"`
+		MonthlyAmount LimitUsage `json:"monthly_amount"`
+		DailyCount    LimitUsage `json:"daily_count"`
+	}
+	//AccountTransferLimitsUsage is the usage of every limit a transfer from the account is checked against
+	AccountTransferLimitsUsage struct {
+		Account TransferLimitsUsage `json:"account"`
+		User    TransferLimitsUsage `json:"user"`
+	}
+)
+
+//GetTransferLimitsUsage returns the limits applying to transfers from the account and how much of them is used today
+func (s SQLStore) GetTransferLimitsUsage(ctx context.Context, account Account) (AccountTransferLimitsUsage, error) {
+	return transferLimitsUsage(ctx, s.Queries, account, false)
+}
+
+//checkTransferLimits returns ErrTransferLimitExceeded when sending amount from the locked account exceeds a limit.
+//The user limit is locked as well, so concurrent transfers from other accounts of the owner wait for this one.
+func checkTransferLimits(ctx context.Context, queries *Queries, account Account, amount int64) error {
+	usage, err := transferLimitsUsage(ctx, queries, account, true)
+	if err != nil {
+		return err
+	}
+
+	if err := usage.Account.check("account", amount); err != nil {
+		return err
+	}
+	return usage.User.check("user", amount)
+}
+
+//transferLimitsUsage sums the transfers sent in the current UTC day and month against the limits of the account and its owner
+func transferLimitsUsage(ctx context.Context, queries *Queries, account Account, forUpdate bool) (usage AccountTransferLimitsUsage, err error) {
+	now := time.Now().UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	accountLimit, err := queries.GetAccountTransferLimit(ctx, sql.NullInt64{Int64: account.ID, Valid: true})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return usage, err
+	}
+
+	userLimitParams := GetUserTransferLimitParams{
+		Owner:    sql.NullString{String: account.Owner, Valid: true},
+		Currency: sql.NullString{String: account.Currency, Valid: true},
+	}
+	var userLimit TransferLimit
+	if forUpdate {
+		userLimit, err = queries.GetUserTransferLimitForUpdate(ctx, GetUserTransferLimitForUpdateParams(userLimitParams))
+	} else {
+		userLimit, err = queries.GetUserTransferLimit(ctx, userLimitParams)
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return usage, err
+	}
+
+	accountUsage, err := queries.GetAccountTransferUsage(ctx, GetAccountTransferUsageParams{
+		DayStart:   dayStart,
+		AccountID:  account.ID,
+		MonthStart: monthStart,
+	})
+	if err != nil {
+		return usage, err
+	}
+
+	userUsage, err := queries.GetUserTransferUsage(ctx, GetUserTransferUsageParams{
+		DayStart:   dayStart,
+		Owner:      account.Owner,
+		Currency:   account.Currency,
+		MonthStart: monthStart,
+	})
+	if err != nil {
+		return usage, err
+	}
+
+	usage.Account = newTransferLimitsUsage(accountLimit, GetUserTransferUsageRow(accountUsage))
+	usage.User = newTransferLimitsUsage(userLimit, userUsage)
+	return usage, nil
+}
+
+func newTransferLimitsUsage(limit TransferLimit, used GetUserTransferUsageRow) TransferLimitsUsage {
+	return TransferLimitsUsage{
+		DailyAmount:   LimitUsage{Limit: nullInt64Ptr(limit.DailyAmount), Used: used.DailyAmount},
+		MonthlyAmount: LimitUsage{Limit: nullInt64Ptr(limit.MonthlyAmount), Used: used.MonthlyAmount},
+		DailyCount:    LimitUsage{Limit: nullInt64Ptr(limit.DailyCount), Used: used.DailyCount},
+	}
+}
+
+//check returns ErrTransferLimitExceeded when one more transfer of amount exceeds a limit
+func (u TransferLimitsUsage) check(scope string, amount int64) error {
+	if u.DailyAmount.exceededBy(amount) {
+		return fmt.Errorf("%w: %s daily amount limit is %d, %d already sent", ErrTransferLimitExceeded, scope, *u.DailyAmount.Limit, u.DailyAmount.Used)
+	}
+	if u.MonthlyAmount.exceededBy(amount) {
+		return fmt.Errorf("%w: %s monthly amount limit is %d, %d already sent", ErrTransferLimitExceeded, scope, *u.MonthlyAmount.Limit, u.MonthlyAmount.Used)
+	}
+	if u.DailyCount.exceededBy(1) {
+		return fmt.Errorf("%w: %s daily count limit is %d transfers", ErrTransferLimitExceeded, scope, *u.DailyCount.Limit)
+	}
+	return nil
+}
+
+func (u LimitUsage) exceededBy(delta int64) bool {
+	return u.Limit != nil && u.Used+delta > *u.Limit
+}
+
+func nullInt64Ptr(n sql.NullInt64) *int64 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int64
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStore_TransferTxAccountLimits(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	account, err := randomAccountWithBalance(ctx, 1000)
+	require.NoError(t, err)
+
+	other, err := randomAccountWithBalance(ctx, 0)
+	require.NoError(t, err)
+
+	_, err = store.UpsertAccountTransferLimit(ctx, UpsertAccountTransferLimitParams{
+		AccountID:   sql.NullInt64{Int64: account.ID, Valid: true},
+		DailyAmount: sql.NullInt64{Int64: 300, Valid: true},
+		DailyCount:  sql.NullInt64{Int64: 2, Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account.ID, ToAccountID: other.ID, Amount: 200})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account.ID, ToAccountID: other.ID, Amount: 101})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account.ID, ToAccountID: other.ID, Amount: 100})
+	require.NoError(t, err)
+
+	//Incoming transfers don't count
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: other.ID, ToAccountID: account.ID, Amount: 100})
+	require.NoError(t, err)
+
+	usage, err := store.GetTransferLimitsUsage(ctx, account)
+	require.NoError(t, err)
+	require.Equal(t, int64(300), usage.Account.DailyAmount.Used)
+	require.Equal(t, int64(300), *usage.Account.DailyAmount.Limit)
+	require.Equal(t, int64(2), usage.Account.DailyCount.Used)
+	require.Nil(t, usage.Account.MonthlyAmount.Limit)
+	require.Nil(t, usage.User.DailyAmount.Limit)
+}
+
+func TestStore_TransferTxUserLimits(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	account, err := randomAccountWithBalance(ctx, 1000)
+	require.NoError(t, err)
+
+	//A second account of the same owner and currency shares the user limit
+	sibling, err := testQueries.CreateAccount(ctx, CreateAccountParams{Owner: account.Owner, Balance: 1000, Currency: account.Currency})
+	require.NoError(t, err)
+
+	other, err := randomAccountWithBalance(ctx, 0)
+	require.NoError(t, err)
+
+	_, err = store.UpsertUserTransferLimit(ctx, UpsertUserTransferLimitParams{
+		Owner:         sql.NullString{String: account.Owner, Valid: true},
+		Currency:      sql.NullString{String: account.Currency, Valid: true},
+		MonthlyAmount: sql.NullInt64{Int64: 500, Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account.ID, ToAccountID: other.ID, Amount: 400})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: sibling.ID, ToAccountID: other.ID, Amount: 101})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	usage, err := store.GetTransferLimitsUsage(ctx, sibling)
+	require.NoError(t, err)
+	require.Equal(t, int64(400), usage.User.MonthlyAmount.Used)
+	require.Zero(t, usage.Account.MonthlyAmount.Used)
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+//Webhook subscription statuses, only active subscriptions receive deliveries
+const (
+	WebhookSubscriptionStatusActive   = "active"
+	WebhookSubscriptionStatusDisabled = "disabled"
+)
+
+//Webhook delivery statuses, pending deliveries are posted until they succeed or run out of attempts
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+type (
+	//RecordWebhookAttemptParams contains the outcome of an attempt and the state the delivery moves to.
+	//NextAttemptAt is only used when the delivery stays pending, DisableAfter is the number of consecutive
+	//failed attempts that disables the subscription.
+	RecordWebhookAttemptParams struct {
+		Attempt       CreateWebhookDeliveryAttemptParams `json:"attempt"`
+		Status        string                             `json:"status"`
+		NextAttemptAt time.Time                          `json:"next_attempt_at"`
+		DisableAfter  int32                              `json:"disable_after"`
+	}
+	//RecordWebhookAttemptResult is the result of the record webhook attempt transaction
+	RecordWebhookAttemptResult struct {
+		Attempt      WebhookDeliveryAttempt `json:"attempt"`
+		Delivery     WebhookDelivery        `json:"delivery"`
+		Subscription WebhookSubscription    `json:"subscription"`
+	}
+)
+
+//RecordWebhookAttempt logs an attempt, releases the claim on the delivery and keeps count of the consecutive
+//failures of its subscription within a single database transaction.
+//A successful attempt resets the count, a failed one disables the subscription once the count reaches DisableAfter.
+func (s SQLStore) RecordWebhookAttempt(ctx context.Context, params RecordWebhookAttemptParams) (result RecordWebhookAttemptResult, err error) {
+	err = s.execTx(ctx, func(queries *Queries) error {
+		if result.Attempt, err = queries.CreateWebhookDeliveryAttempt(ctx, params.Attempt); err != nil {
+			return err
+		}
+
+		var deliveredAt sql.NullTime
+		if params.Status == WebhookDeliverySucceeded {
+			deliveredAt = sql.NullTime{Time: result.Attempt.CreatedAt, Valid: true}
+		}
+
+		result.Delivery, err = queries.UpdateWebhookDeliveryAttempt(ctx, UpdateWebhookDeliveryAttemptParams{
+			Status:             params.Status,
+			Attempts:           params.Attempt.Attempt,
+			NextAttemptAt:      params.NextAttemptAt,
+			LastResponseStatus: params.Attempt.ResponseStatus,
+			LastError:          params.Attempt.Error,
+			DeliveredAt:        deliveredAt,
+			ID:                 params.Attempt.DeliveryID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if params.Status == WebhookDeliverySucceeded {
+			result.Subscription, err = queries.ResetWebhookSubscriptionFailures(ctx, result.Delivery.SubscriptionID)
+			return err
+		}
+
+		result.Subscription, err = queries.RecordWebhookSubscriptionFailure(ctx, RecordWebhookSubscriptionFailureParams{
+			DisableAfter: params.DisableAfter,
+			ID:           result.Delivery.SubscriptionID,
+		})
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"simplebank/util"
+	"testing"
+	"time"
+)
+
+func TestStore_RecordWebhookAttempt(t *testing.T) {
+	store := NewStore(testDb)
+	ctx := context.Background()
+
+	subscription, err := randomWebhookSubscription(ctx, EventBalanceChanged)
+	require.NoError(t, err)
+
+	var deliveryIDs []int64
+	for i := 0; i < 3; i++ {
+		eventID := util.RandomInt(1, 1_000_000_000)
+		_, err := store.EnqueueWebhookDeliveries(ctx, EnqueueWebhookDeliveriesParams{
+			EventID:   eventID,
+			EventType: EventBalanceChanged,
+			Payload:   json.RawMessage(`{}`),
+			Owners:    []string{subscription.Owner},
+		})
+		require.NoError(t, err)
+
+		deliveries, err := store.ListWebhookDeliveries(ctx, ListWebhookDeliveriesParams{SubscriptionID: subscription.ID, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		require.Equal(t, eventID, deliveries[0].EventID)
+		deliveryIDs = append(deliveryIDs, deliveries[0].ID)
+	}
+
+	fail := func(deliveryID int64, status string) RecordWebhookAttemptResult {
+		result, err := store.RecordWebhookAttempt(ctx, RecordWebhookAttemptParams{
+			Attempt: CreateWebhookDeliveryAttemptParams{
+				DeliveryID:     deliveryID,
+				Attempt:        1,
+				ResponseStatus: sql.NullInt32{Int32: 503, Valid: true},
+				Error:          "receiver answered 503",
+				DurationMs:     12,
+			},
+			Status:        status,
+			NextAttemptAt: time.Now().Add(time.Minute),
+			DisableAfter:  2,
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	result := fail(deliveryIDs[0], WebhookDeliveryPending)
+	require.Equal(t, WebhookDeliveryPending, result.Delivery.Status)
+	require.Equal(t, int32(1), result.Delivery.Attempts)
+	require.Equal(t, "receiver answered 503", result.Delivery.LastError)
+	require.False(t, result.Delivery.ClaimedUntil.Valid)
+	require.False(t, result.Delivery.DeliveredAt.Valid)
+	require.Equal(t, int32(1), result.Subscription.ConsecutiveFailures)
+	require.Equal(t, WebhookSubscriptionStatusActive, result.Subscription.Status)
+
+	//A success resets the consecutive failures
+	result, err = store.RecordWebhookAttempt(ctx, RecordWebhookAttemptParams{
+		Attempt: CreateWebhookDeliveryAttemptParams{
+			DeliveryID:     deliveryIDs[1],
+			Attempt:        1,
+			ResponseStatus: sql.NullInt32{Int32: 200, Valid: true},
+			DurationMs:     8,
+		},
+		Status:       WebhookDeliverySucceeded,
+		DisableAfter: 2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, WebhookDeliverySucceeded, result.Delivery.Status)
+	require.True(t, result.Delivery.DeliveredAt.Valid)
+	require.Zero(t, result.Subscription.ConsecutiveFailures)
+
+	//DisableAfter failures in a row disable the subscription
+	fail(deliveryIDs[2], WebhookDeliveryPending)
+	result = fail(deliveryIDs[0], WebhookDeliveryFailed)
+	require.Equal(t, WebhookDeliveryFailed, result.Delivery.Status)
+	require.Equal(t, WebhookSubscriptionStatusDisabled, result.Subscription.Status)
+	require.True(t, result.Subscription.DisabledAt.Valid)
+
+	attempts, err := store.ListWebhookDeliveryAttempts(ctx, deliveryIDs[0])
+	require.NoError(t, err)
+	require.Len(t, attempts, 2)
+	require.Equal(t, int32(503), attempts[0].ResponseStatus.Int32)
+}
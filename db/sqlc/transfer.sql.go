@@ -5,22 +5,34 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createTransfer = `-- name: CreateTransfer :one
-INSERT INTO transfers(from_account_id, to_account_id, amount)
-VALUES ($1, $2, $3)
-RETURNING id, from_account_id, to_account_id, amount, created_at
+INSERT INTO transfers(from_account_id, to_account_id, amount, to_amount, exchange_rate, spread_bps)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, spread_bps
 `
 
 type CreateTransferParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	ToAmount      int64 `json:"to_amount"`
+	ExchangeRate  int64 `json:"exchange_rate"`
+	SpreadBps     int32 `json:"spread_bps"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ToAmount,
+		arg.ExchangeRate,
+		arg.SpreadBps,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -28,12 +40,15 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.SpreadBps,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, spread_bps
 FROM transfers
 WHERE id = $1
 LIMIT 1
@@ -48,12 +63,39 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.SpreadBps,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, spread_bps
+FROM transfers
+WHERE id = $1
+LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.SpreadBps,
 	)
 	return i, err
 }
 
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, spread_bps
 FROM transfers
 ORDER BY id
 LIMIT $1 OFFSET $2
@@ -79,6 +121,455 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.SpreadBps,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchAccountTransfers = `-- name: SearchAccountTransfers :many
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.to_amount, t.exchange_rate, t.spread_bps
+FROM transfers t
+WHERE (t.from_account_id IN (SELECT id FROM accounts WHERE owner = $1)
+    OR t.to_account_id IN (SELECT id FROM accounts WHERE owner = $1))
+  AND (($2::bool AND t.from_account_id = $3
+    AND ($4::bigint IS NULL OR t.to_account_id = $4))
+    OR ($5::bool AND t.to_account_id = $3
+        AND ($4::bigint IS NULL OR t.from_account_id = $4)))
+  AND ($6::bigint IS NULL OR t.from_account_id = $6)
+  AND ($7::bigint IS NULL OR t.to_account_id = $7)
+  AND ($8::bigint IS NULL OR t.amount >= $8)
+  AND ($9::bigint IS NULL OR t.amount <= $9)
+  AND ($10::timestamp IS NULL OR t.created_at >= $10)
+  AND ($11::timestamp IS NULL OR t.created_at < $11)
+ORDER BY t.id
+LIMIT $12 OFFSET $13
+`
+
+type SearchAccountTransfersParams struct {
+	Owner          string        `json:"owner"`
+	Outgoing       bool          `json:"outgoing"`
+	AccountID      int64         `json:"account_id"`
+	CounterpartyID sql.NullInt64 `json:"counterparty_id"`
+	Incoming       bool          `json:"incoming"`
+	FromAccountID  sql.NullInt64 `json:"from_account_id"`
+	ToAccountID    sql.NullInt64 `json:"to_account_id"`
+	MinAmount      sql.NullInt64 `json:"min_amount"`
+	MaxAmount      sql.NullInt64 `json:"max_amount"`
+	FromTime       sql.NullTime  `json:"from_time"`
+	ToTime         sql.NullTime  `json:"to_time"`
+	Limit          int32         `json:"limit"`
+	Offset         int32         `json:"offset"`
+}
+
+func (q *Queries) SearchAccountTransfers(ctx context.Context, arg SearchAccountTransfersParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, searchAccountTransfers,
+		arg.Owner,
+		arg.Outgoing,
+		arg.AccountID,
+		arg.CounterpartyID,
+		arg.Incoming,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.SpreadBps,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchAccountTransfersAfter = `-- name: SearchAccountTransfersAfter :many
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.to_amount, t.exchange_rate, t.spread_bps
+FROM transfers t
+WHERE (t.from_account_id IN (SELECT id FROM accounts WHERE owner = $1)
+    OR t.to_account_id IN (SELECT id FROM accounts WHERE owner = $1))
+  AND (($2::bool AND t.from_account_id = $3
+    AND ($4::bigint IS NULL OR t.to_account_id = $4))
+    OR ($5::bool AND t.to_account_id = $3
+        AND ($4::bigint IS NULL OR t.from_account_id = $4)))
+  AND ($6::bigint IS NULL OR t.from_account_id = $6)
+  AND ($7::bigint IS NULL OR t.to_account_id = $7)
+  AND ($8::bigint IS NULL OR t.amount >= $8)
+  AND ($9::bigint IS NULL OR t.amount <= $9)
+  AND ($10::timestamp IS NULL OR t.created_at >= $10)
+  AND ($11::timestamp IS NULL OR t.created_at < $11)
+  AND ($12::timestamp IS NULL
+    OR (t.created_at, t.id) > ($12, $13::bigint))
+ORDER BY t.created_at, t.id
+LIMIT $14
+`
+
+type SearchAccountTransfersAfterParams struct {
+	Owner           string        `json:"owner"`
+	Outgoing        bool          `json:"outgoing"`
+	AccountID       int64         `json:"account_id"`
+	CounterpartyID  sql.NullInt64 `json:"counterparty_id"`
+	Incoming        bool          `json:"incoming"`
+	FromAccountID   sql.NullInt64 `json:"from_account_id"`
+	ToAccountID     sql.NullInt64 `json:"to_account_id"`
+	MinAmount       sql.NullInt64 `json:"min_amount"`
+	MaxAmount       sql.NullInt64 `json:"max_amount"`
+	FromTime        sql.NullTime  `json:"from_time"`
+	ToTime          sql.NullTime  `json:"to_time"`
+	CursorCreatedAt sql.NullTime  `json:"cursor_created_at"`
+	CursorID        sql.NullInt64 `json:"cursor_id"`
+	Limit           int32         `json:"limit"`
+}
+
+func (q *Queries) SearchAccountTransfersAfter(ctx context.Context, arg SearchAccountTransfersAfterParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, searchAccountTransfersAfter,
+		arg.Owner,
+		arg.Outgoing,
+		arg.AccountID,
+		arg.CounterpartyID,
+		arg.Incoming,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.FromTime,
+		arg.ToTime,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.SpreadBps,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchAccountTransfersBefore = `-- name: SearchAccountTransfersBefore :many
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.to_amount, t.exchange_rate, t.spread_bps
+FROM transfers t
+WHERE (t.from_account_id IN (SELECT id FROM accounts WHERE owner = $1)
+    OR t.to_account_id IN (SELECT id FROM accounts WHERE owner = $1))
+  AND (($2::bool AND t.from_account_id = $3
+    AND ($4::bigint IS NULL OR t.to_account_id = $4))
+    OR ($5::bool AND t.to_account_id = $3
+        AND ($4::bigint IS NULL OR t.from_account_id = $4)))
+  AND ($6::bigint IS NULL OR t.from_account_id = $6)
+  AND ($7::bigint IS NULL OR t.to_account_id = $7)
+  AND ($8::bigint IS NULL OR t.amount >= $8)
+  AND ($9::bigint IS NULL OR t.amount <= $9)
+  AND ($10::timestamp IS NULL OR t.created_at >= $10)
+  AND ($11::timestamp IS NULL OR t.created_at < $11)
+  AND (t.created_at, t.id) < ($12::timestamp, $13::bigint)
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT $14
+`
+
+type SearchAccountTransfersBeforeParams struct {
+	Owner           string        `json:"owner"`
+	Outgoing        bool          `json:"outgoing"`
+	AccountID       int64         `json:"account_id"`
+	CounterpartyID  sql.NullInt64 `json:"counterparty_id"`
+	Incoming        bool          `json:"incoming"`
+	FromAccountID   sql.NullInt64 `json:"from_account_id"`
+	ToAccountID     sql.NullInt64 `json:"to_account_id"`
+	MinAmount       sql.NullInt64 `json:"min_amount"`
+	MaxAmount       sql.NullInt64 `json:"max_amount"`
+	FromTime        sql.NullTime  `json:"from_time"`
+	ToTime          sql.NullTime  `json:"to_time"`
+	CursorCreatedAt time.Time     `json:"cursor_created_at"`
+	CursorID        int64         `json:"cursor_id"`
+	Limit           int32         `json:"limit"`
+}
+
+func (q *Queries) SearchAccountTransfersBefore(ctx context.Context, arg SearchAccountTransfersBeforeParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, searchAccountTransfersBefore,
+		arg.Owner,
+		arg.Outgoing,
+		arg.AccountID,
+		arg.CounterpartyID,
+		arg.Incoming,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.FromTime,
+		arg.ToTime,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.SpreadBps,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTransfers = `-- name: SearchTransfers :many
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.to_amount, t.exchange_rate, t.spread_bps
+FROM transfers t
+WHERE (t.from_account_id IN (SELECT id FROM accounts WHERE owner = $1)
+    OR t.to_account_id IN (SELECT id FROM accounts WHERE owner = $1))
+  AND ($2::bigint IS NULL OR t.amount >= $2)
+  AND ($3::bigint IS NULL OR t.amount <= $3)
+  AND ($4::timestamp IS NULL OR t.created_at >= $4)
+  AND ($5::timestamp IS NULL OR t.created_at < $5)
+ORDER BY t.id
+LIMIT $6 OFFSET $7
+`
+
+type SearchTransfersParams struct {
+	Owner     string        `json:"owner"`
+	MinAmount sql.NullInt64 `json:"min_amount"`
+	MaxAmount sql.NullInt64 `json:"max_amount"`
+	FromTime  sql.NullTime  `json:"from_time"`
+	ToTime    sql.NullTime  `json:"to_time"`
+	Limit     int32         `json:"limit"`
+	Offset    int32         `json:"offset"`
+}
+
+func (q *Queries) SearchTransfers(ctx context.Context, arg SearchTransfersParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, searchTransfers,
+		arg.Owner,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.SpreadBps,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTransfersAfter = `-- name: SearchTransfersAfter :many
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.to_amount, t.exchange_rate, t.spread_bps
+FROM transfers t
+WHERE (t.from_account_id IN (SELECT id FROM accounts WHERE owner = $1)
+    OR t.to_account_id IN (SELECT id FROM accounts WHERE owner = $1))
+  AND ($2::bigint IS NULL OR t.amount >= $2)
+  AND ($3::bigint IS NULL OR t.amount <= $3)
+  AND ($4::timestamp IS NULL OR t.created_at >= $4)
+  AND ($5::timestamp IS NULL OR t.created_at < $5)
+  AND ($6::timestamp IS NULL
+    OR (t.created_at, t.id) > ($6, $7::bigint))
+ORDER BY t.created_at, t.id
+LIMIT $8
+`
+
+type SearchTransfersAfterParams struct {
+	Owner           string        `json:"owner"`
+	MinAmount       sql.NullInt64 `json:"min_amount"`
+	MaxAmount       sql.NullInt64 `json:"max_amount"`
+	FromTime        sql.NullTime  `json:"from_time"`
+	ToTime          sql.NullTime  `json:"to_time"`
+	CursorCreatedAt sql.NullTime  `json:"cursor_created_at"`
+	CursorID        sql.NullInt64 `json:"cursor_id"`
+	Limit           int32         `json:"limit"`
+}
+
+func (q *Queries) SearchTransfersAfter(ctx context.Context, arg SearchTransfersAfterParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, searchTransfersAfter,
+		arg.Owner,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.FromTime,
+		arg.ToTime,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.SpreadBps,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTransfersBefore = `-- name: SearchTransfersBefore :many
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.to_amount, t.exchange_rate, t.spread_bps
+FROM transfers t
+WHERE (t.from_account_id IN (SELECT id FROM accounts WHERE owner = $1)
+    OR t.to_account_id IN (SELECT id FROM accounts WHERE owner = $1))
+  AND ($2::bigint IS NULL OR t.amount >= $2)
+  AND ($3::bigint IS NULL OR t.amount <= $3)
+  AND ($4::timestamp IS NULL OR t.created_at >= $4)
+  AND ($5::timestamp IS NULL OR t.created_at < $5)
+  AND (t.created_at, t.id) < ($6::timestamp, $7::bigint)
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT $8
+`
+
+type SearchTransfersBeforeParams struct {
+	Owner           string        `json:"owner"`
+	MinAmount       sql.NullInt64 `json:"min_amount"`
+	MaxAmount       sql.NullInt64 `json:"max_amount"`
+	FromTime        sql.NullTime  `json:"from_time"`
+	ToTime          sql.NullTime  `json:"to_time"`
+	CursorCreatedAt time.Time     `json:"cursor_created_at"`
+	CursorID        int64         `json:"cursor_id"`
+	Limit           int32         `json:"limit"`
+}
+
+func (q *Queries) SearchTransfersBefore(ctx context.Context, arg SearchTransfersBeforeParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, searchTransfersBefore,
+		arg.Owner,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.FromTime,
+		arg.ToTime,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.SpreadBps,
 		); err != nil {
 			return nil, err
 		}
@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"simplebank/util"
 	"testing"
+	"time"
 )
 
 func randomTransfer(ctx context.Context) (Transfer, error) {
@@ -19,10 +20,13 @@ func randomTransfer(ctx context.Context) (Transfer, error) {
 		return Transfer{}, err
 	}
 
+	amount := util.RandomInt(1, 1000)
 	return testQueries.CreateTransfer(ctx, CreateTransferParams{
 		FromAccountID: fromAcc.ID,
 		ToAccountID:   toAcc.ID,
-		Amount:        util.RandomMoney(),
+		Amount:        amount,
+		ToAmount:      amount,
+		ExchangeRate:  1_000_000,
 	})
 }
 
@@ -44,11 +48,13 @@ func TestQueries_CreateTransfer(t *testing.T) {
 				require.NoError(t, err)
 				require.NotZero(t, toAcc.ID)
 
-				amount := util.RandomMoney()
+				amount := util.RandomInt(1, 1000)
 				transfer, err := testQueries.CreateTransfer(ctx, CreateTransferParams{
 					FromAccountID: fromAcc.ID,
 					ToAccountID:   toAcc.ID,
 					Amount:        amount,
+					ToAmount:      amount,
+					ExchangeRate:  1_000_000,
 				})
 				require.NoError(t, err)
 				require.NotZero(t, transfer.ID)
@@ -140,3 +146,198 @@ func TestQueries_ListTransfers(t *testing.T) {
 		})
 	}
 }
+
+func TestQueries_SearchTransfers(t *testing.T) {
+	ctx := context.Background()
+
+	account, err := randomAccount(ctx)
+	require.NoError(t, err)
+
+	counterparty, err := randomAccount(ctx)
+	require.NoError(t, err)
+
+	stranger, err := randomAccount(ctx)
+	require.NoError(t, err)
+
+	outgoing, err := testQueries.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: account.ID,
+		ToAccountID:   counterparty.ID,
+		Amount:        10,
+		ToAmount:      10,
+		ExchangeRate:  1_000_000,
+	})
+	require.NoError(t, err)
+
+	incoming, err := testQueries.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: counterparty.ID,
+		ToAccountID:   account.ID,
+		Amount:        30,
+		ToAmount:      30,
+		ExchangeRate:  1_000_000,
+	})
+	require.NoError(t, err)
+
+	//Transfers between other users' accounts must never be visible
+	_, err = testQueries.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: stranger.ID,
+		ToAccountID:   counterparty.ID,
+		Amount:        50,
+		ToAmount:      50,
+		ExchangeRate:  1_000_000,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		params SearchTransfersParams
+		want   []Transfer
+	}{
+		{
+			name:   "When no filter is given",
+			params: SearchTransfersParams{},
+			want:   []Transfer{outgoing, incoming},
+		},
+		{
+			name: "When filtering by amount range",
+			params: SearchTransfersParams{
+				MinAmount: sql.NullInt64{Int64: 20, Valid: true},
+				MaxAmount: sql.NullInt64{Int64: 40, Valid: true},
+			},
+			want: []Transfer{incoming},
+		},
+		{
+			name: "When filtering by date window",
+			params: SearchTransfersParams{
+				FromTime: sql.NullTime{Time: outgoing.CreatedAt, Valid: true},
+				ToTime:   sql.NullTime{Time: outgoing.CreatedAt.Add(-time.Nanosecond), Valid: true},
+			},
+			want: []Transfer{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.params.Owner = account.Owner
+			tt.params.Limit = 5
+			transfers, err := testQueries.SearchTransfers(ctx, tt.params)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, transfers)
+		})
+	}
+}
+
+func TestQueries_SearchAccountTransfers(t *testing.T) {
+	ctx := context.Background()
+
+	account, err := randomAccount(ctx)
+	require.NoError(t, err)
+
+	counterparty, err := randomAccount(ctx)
+	require.NoError(t, err)
+
+	stranger, err := randomAccount(ctx)
+	require.NoError(t, err)
+
+	outgoing, err := testQueries.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: account.ID,
+		ToAccountID:   counterparty.ID,
+		Amount:        10,
+		ToAmount:      10,
+		ExchangeRate:  1_000_000,
+	})
+	require.NoError(t, err)
+
+	incoming, err := testQueries.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: counterparty.ID,
+		ToAccountID:   account.ID,
+		Amount:        30,
+		ToAmount:      30,
+		ExchangeRate:  1_000_000,
+	})
+	require.NoError(t, err)
+
+	//Transfers between other users' accounts must never be visible
+	_, err = testQueries.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: stranger.ID,
+		ToAccountID:   counterparty.ID,
+		Amount:        50,
+		ToAmount:      50,
+		ExchangeRate:  1_000_000,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		params SearchAccountTransfersParams
+		want   []Transfer
+	}{
+		{
+			name: "When searching both directions of an account",
+			params: SearchAccountTransfersParams{
+				AccountID: account.ID,
+				Outgoing:  true,
+				Incoming:  true,
+			},
+			want: []Transfer{outgoing, incoming},
+		},
+		{
+			name: "When filtering incoming transfers of an account",
+			params: SearchAccountTransfersParams{
+				AccountID: account.ID,
+				Incoming:  true,
+			},
+			want: []Transfer{incoming},
+		},
+		{
+			name: "When filtering outgoing transfers of an account",
+			params: SearchAccountTransfersParams{
+				AccountID: account.ID,
+				Outgoing:  true,
+			},
+			want: []Transfer{outgoing},
+		},
+		{
+			name: "When filtering by counterparty",
+			params: SearchAccountTransfersParams{
+				AccountID:      account.ID,
+				Outgoing:       true,
+				Incoming:       true,
+				CounterpartyID: sql.NullInt64{Int64: stranger.ID, Valid: true},
+			},
+			want: []Transfer{},
+		},
+		{
+			name: "When filtering by source and destination",
+			params: SearchAccountTransfersParams{
+				AccountID:     counterparty.ID,
+				Outgoing:      true,
+				FromAccountID: sql.NullInt64{Int64: counterparty.ID, Valid: true},
+				ToAccountID:   sql.NullInt64{Int64: account.ID, Valid: true},
+			},
+			want: []Transfer{incoming},
+		},
+		{
+			name: "When the account belongs to someone else",
+			params: SearchAccountTransfersParams{
+				AccountID: stranger.ID,
+				Outgoing:  true,
+				Incoming:  true,
+			},
+			want: []Transfer{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.params.Owner = account.Owner
+			tt.params.Limit = 5
+			transfers, err := testQueries.SearchAccountTransfers(ctx, tt.params)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, transfers)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/require"
+	"simplebank/util"
+	"testing"
+)
+
+func randomUser(ctx context.Context) (User, error) {
+	hashedPassword, err := util.HashPassword(util.RandomString(6))
+	if err != nil {
+		return User{}, err
+	}
+
+	return testQueries.CreateUser(ctx, CreateUserParams{
+		Username:       util.RandomOwner(),
+		HashedPassword: hashedPassword,
+		FullName:       util.RandomOwner(),
+		Email:          util.RandomEmail(),
+	})
+}
+
+func TestQueries_CreateUser(t *testing.T) {
+	tests := []struct {
+		name        string
+		testingFunc func(t *testing.T)
+	}{
+		{
+			name: "When successfully creates it",
+			testingFunc: func(t *testing.T) {
+				ctx := context.Background()
+				params := CreateUserParams{
+					Username:       util.RandomOwner(),
+					HashedPassword: "secret",
+					FullName:       util.RandomOwner(),
+					Email:          util.RandomEmail(),
+				}
+
+				user, err := testQueries.CreateUser(ctx, params)
+				require.NoError(t, err)
+
+				require.Equal(t, params.Username, user.Username)
+				require.Equal(t, params.HashedPassword, user.HashedPassword)
+				require.Equal(t, params.FullName, user.FullName)
+				require.Equal(t, params.Email, user.Email)
+
+				require.Equal(t, util.DepositorRole, user.Role)
+				require.True(t, user.PasswordChangedAt.IsZero())
+				require.NotZero(t, user.CreatedAt)
+			},
+		},
+		{
+			name: "When username is already taken",
+			testingFunc: func(t *testing.T) {
+				ctx := context.Background()
+
+				user, err := randomUser(ctx)
+				require.NoError(t, err)
+
+				_, err = testQueries.CreateUser(ctx, CreateUserParams{
+					Username:       user.Username,
+					HashedPassword: "secret",
+					FullName:       util.RandomOwner(),
+					Email:          util.RandomEmail(),
+				})
+				require.Error(t, err)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.testingFunc(t)
+		})
+	}
+}
+
+func TestQueries_GetUser(t *testing.T) {
+	tests := []struct {
+		name        string
+		testingFunc func(t *testing.T)
+	}{
+		{
+			name: "When user exists",
+			testingFunc: func(t *testing.T) {
+				ctx := context.Background()
+
+				user, err := randomUser(ctx)
+				require.NoError(t, err)
+
+				u, err := testQueries.GetUser(ctx, user.Username)
+				require.NoError(t, err)
+				require.Equal(t, user.Username, u.Username)
+				require.Equal(t, user.HashedPassword, u.HashedPassword)
+				require.Equal(t, user.Email, u.Email)
+			},
+		},
+		{
+			name: "When user does not exist",
+			testingFunc: func(t *testing.T) {
+				_, err := testQueries.GetUser(context.Background(), "")
+				require.Error(t, err)
+				require.Equal(t, sql.ErrNoRows, err)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.testingFunc(t)
+		})
+	}
+}
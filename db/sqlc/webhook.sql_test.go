@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"simplebank/util"
+	"testing"
+	"time"
+)
+
+func randomWebhookSubscription(ctx context.Context, eventTypes ...string) (WebhookSubscription, error) {
+	user, err := randomUser(ctx)
+	if err != nil {
+		return WebhookSubscription{}, err
+	}
+
+	return testQueries.CreateWebhookSubscription(ctx, CreateWebhookSubscriptionParams{
+		Owner:      user.Username,
+		Url:        "https://hooks.example.com/" + util.RandomString(8),
+		EventTypes: eventTypes,
+		Secret:     util.RandomString(32),
+	})
+}
+
+func TestQueries_EnqueueWebhookDeliveries(t *testing.T) {
+	ctx := context.Background()
+
+	transfers, err := randomWebhookSubscription(ctx, EventTransferCompleted)
+	require.NoError(t, err)
+	balances, err := randomWebhookSubscription(ctx, EventBalanceChanged)
+	require.NoError(t, err)
+
+	params := EnqueueWebhookDeliveriesParams{
+		EventID:   util.RandomInt(1, 1_000_000_000),
+		EventType: EventTransferCompleted,
+		Payload:   json.RawMessage(`{"id":1}`),
+		Owners:    []string{transfers.Owner, balances.Owner},
+	}
+
+	//Only the subscription to the event type receives it
+	enqueued, err := testQueries.EnqueueWebhookDeliveries(ctx, params)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), enqueued)
+
+	//An event relayed again is not delivered twice
+	enqueued, err = testQueries.EnqueueWebhookDeliveries(ctx, params)
+	require.NoError(t, err)
+	require.Zero(t, enqueued)
+
+	deliveries, err := testQueries.ListWebhookDeliveries(ctx, ListWebhookDeliveriesParams{
+		SubscriptionID: transfers.ID,
+		Status:         sql.NullString{String: WebhookDeliveryPending, Valid: true},
+		Limit:          5,
+	})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, params.EventID, deliveries[0].EventID)
+	require.Zero(t, deliveries[0].Attempts)
+
+	//Disabled subscriptions receive nothing
+	_, err = testQueries.UpdateWebhookSubscription(ctx, UpdateWebhookSubscriptionParams{
+		Status: sql.NullString{String: WebhookSubscriptionStatusDisabled, Valid: true},
+		ID:     transfers.ID,
+	})
+	require.NoError(t, err)
+
+	params.EventID++
+	enqueued, err = testQueries.EnqueueWebhookDeliveries(ctx, params)
+	require.NoError(t, err)
+	require.Zero(t, enqueued)
+}
+
+func TestQueries_UpdateWebhookSubscription(t *testing.T) {
+	ctx := context.Background()
+
+	subscription, err := randomWebhookSubscription(ctx, EventAccountCreated)
+	require.NoError(t, err)
+
+	disabled, err := testQueries.UpdateWebhookSubscription(ctx, UpdateWebhookSubscriptionParams{
+		Status: sql.NullString{String: WebhookSubscriptionStatusDisabled, Valid: true},
+		ID:     subscription.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, WebhookSubscriptionStatusDisabled, disabled.Status)
+	require.True(t, disabled.DisabledAt.Valid)
+	require.Equal(t, subscription.Url, disabled.Url)
+	require.Equal(t, subscription.EventTypes, disabled.EventTypes)
+
+	_, err = testQueries.RecordWebhookSubscriptionFailure(ctx, RecordWebhookSubscriptionFailureParams{DisableAfter: 20, ID: subscription.ID})
+	require.NoError(t, err)
+
+	//Reactivating clears the failures
+	active, err := testQueries.UpdateWebhookSubscription(ctx, UpdateWebhookSubscriptionParams{
+		EventTypes: []string{EventAccountCreated, EventBalanceChanged},
+		Status:     sql.NullString{String: WebhookSubscriptionStatusActive, Valid: true},
+		ID:         subscription.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, WebhookSubscriptionStatusActive, active.Status)
+	require.False(t, active.DisabledAt.Valid)
+	require.Zero(t, active.ConsecutiveFailures)
+	require.Equal(t, []string{EventAccountCreated, EventBalanceChanged}, active.EventTypes)
+}
+
+func TestQueries_ClaimWebhookDeliveries(t *testing.T) {
+	ctx := context.Background()
+
+	subscription, err := randomWebhookSubscription(ctx, EventAccountCreated)
+	require.NoError(t, err)
+
+	_, err = testQueries.EnqueueWebhookDeliveries(ctx, EnqueueWebhookDeliveriesParams{
+		EventID:   util.RandomInt(1, 1_000_000_000),
+		EventType: EventAccountCreated,
+		Payload:   json.RawMessage(`{}`),
+		Owners:    []string{subscription.Owner},
+	})
+	require.NoError(t, err)
+
+	claimedUntil := sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true}
+	claimed, err := testQueries.ClaimWebhookDeliveries(ctx, ClaimWebhookDeliveriesParams{ClaimedUntil: claimedUntil, Limit: 1000})
+	require.NoError(t, err)
+
+	found := false
+	for _, delivery := range claimed {
+		found = found || delivery.SubscriptionID == subscription.ID
+	}
+	require.True(t, found)
+
+	//A claimed delivery is hidden from other dispatchers until the lease expires
+	claimed, err = testQueries.ClaimWebhookDeliveries(ctx, ClaimWebhookDeliveriesParams{ClaimedUntil: claimedUntil, Limit: 1000})
+	require.NoError(t, err)
+	for _, delivery := range claimed {
+		require.NotEqual(t, subscription.ID, delivery.SubscriptionID)
+	}
+}
@@ -0,0 +1,123 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"simplebank/currency"
+	"strconv"
+	"strings"
+)
+
+//RateScale is the fixed-point scale of rates: a rate of 1.5 is stored as 1_500_000
+const RateScale = 1_000_000
+
+//maxSpreadBps is 100%, a spread can't take the whole converted amount
+const maxSpreadBps = 10_000
+
+var (
+	//ErrRateNotFound is returned when a provider has no rate for a currency pair
+	ErrRateNotFound = errors.New("exchange rate not found")
+	//ErrInvalidRate is returned when a rate can't be parsed or is not positive
+	ErrInvalidRate = errors.New("invalid exchange rate")
+	//ErrAmountTooSmall is returned when the converted amount rounds down to zero
+	ErrAmountTooSmall = errors.New("amount too small to be converted")
+)
+
+type (
+	//Rate is the mid-market price of one unit of From in To, scaled by RateScale
+	Rate struct {
+		From  string `json:"from"`
+		To    string `json:"to"`
+		Value int64  `json:"value"`
+	}
+	//Quote is the result of converting an amount with a rate and a spread charged by the bank
+	Quote struct {
+		Rate            Rate  `json:"rate"`
+		SpreadBps       int64 `json:"spread_bps"`
+		Amount          int64 `json:"amount"`
+		ConvertedAmount int64 `json:"converted_amount"`
+	}
+	//RateProvider provides exchange rates between currencies
+	RateProvider interface {
+		//Rate returns the rate to convert from one currency to another
+		Rate(ctx context.Context, from string, to string) (Rate, error)
+	}
+)
+
+//ParseRate parses a decimal rate such as "0.92" into its fixed-point value without going through floats
+func ParseRate(s string) (int64, error) {
+	whole, fraction, _ := strings.Cut(strings.TrimSpace(s), ".")
+	if len(fraction) > 6 {
+		return 0, fmt.Errorf("%w: %q has more than 6 decimals", ErrInvalidRate, s)
+	}
+	fraction += strings.Repeat("0", 6-len(fraction))
+
+	value, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil || value <= 0 || strings.HasPrefix(whole, "-") || strings.HasPrefix(whole, "+") {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidRate, s)
+	}
+	return value, nil
+}
+
+//Inverse returns the rate converting in the opposite direction
+func (r Rate) Inverse() Rate {
+	return Rate{
+		From:  r.To,
+		To:    r.From,
+		Value: RateScale * RateScale / r.Value,
+	}
+}
+
+//ValidateSpread checks the spread is a valid amount of basis points to charge over a conversion
+func ValidateSpread(spreadBps int64) error {
+	if spreadBps < 0 || spreadBps >= maxSpreadBps {
+		return fmt.Errorf("spread must be between 0 and %d basis points", maxSpreadBps-1)
+	}
+	return nil
+}
+
+//NewQuote converts amount with rate, charging spreadBps basis points over the converted amount.
+//Both amounts are in the minor units of their currency, e.g. 100 USD cents at 150 JPY per USD quote 150 yen.
+//The converted amount is rounded down so the bank never credits more than it debits.
+func NewQuote(currencies *currency.Registry, rate Rate, spreadBps int64, amount int64) (Quote, error) {
+	if err := ValidateSpread(spreadBps); err != nil {
+		return Quote{}, err
+	}
+
+	from, err := currencies.Get(rate.From)
+	if err != nil {
+		return Quote{}, err
+	}
+	to, err := currencies.Get(rate.To)
+	if err != nil {
+		return Quote{}, err
+	}
+
+	converted := new(big.Int).Mul(big.NewInt(amount), big.NewInt(rate.Value))
+	converted.Mul(converted, big.NewInt(maxSpreadBps-spreadBps))
+	converted.Mul(converted, pow10(to.MinorUnits))
+
+	divisor := new(big.Int).Mul(big.NewInt(RateScale*maxSpreadBps), pow10(from.MinorUnits))
+	converted.Quo(converted, divisor)
+
+	if converted.Sign() <= 0 {
+		return Quote{}, ErrAmountTooSmall
+	}
+	if !converted.IsInt64() {
+		return Quote{}, fmt.Errorf("converted amount overflows: %s", converted)
+	}
+
+	return Quote{
+		Rate:            rate,
+		SpreadBps:       spreadBps,
+		Amount:          amount,
+		ConvertedAmount: converted.Int64(),
+	}, nil
+}
+
+//pow10 returns 10^n
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
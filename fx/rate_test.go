@@ -0,0 +1,146 @@
+package fx
+
+import (
+	"github.com/stretchr/testify/require"
+	"simplebank/currency"
+	"testing"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    string
+		want    int64
+		wantErr error
+	}{
+		{
+			name: "When rate has decimals",
+			rate: "0.92",
+			want: 920_000,
+		},
+		{
+			name: "When rate is a whole number",
+			rate: "150",
+			want: 150_000_000,
+		},
+		{
+			name:    "When rate has more than 6 decimals",
+			rate:    "1.0000001",
+			wantErr: ErrInvalidRate,
+		},
+		{
+			name:    "When rate is negative",
+			rate:    "-1.5",
+			wantErr: ErrInvalidRate,
+		},
+		{
+			name:    "When rate is zero",
+			rate:    "0",
+			wantErr: ErrInvalidRate,
+		},
+		{
+			name:    "When rate is not a number",
+			rate:    "abc",
+			wantErr: ErrInvalidRate,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseRate(tt.rate)
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+var testCurrencies = currency.MustNewRegistry([]currency.Currency{
+	{Code: "EUR", NumericCode: 978, MinorUnits: 2, Enabled: true},
+	{Code: "JPY", NumericCode: 392, MinorUnits: 0, Enabled: true},
+	{Code: "USD", NumericCode: 840, MinorUnits: 2, Enabled: true},
+})
+
+func TestNewQuote(t *testing.T) {
+	usdEur := Rate{From: "USD", To: "EUR", Value: 920_000}
+	usdJpy := Rate{From: "USD", To: "JPY", Value: 150_000_000}
+
+	tests := []struct {
+		name      string
+		rate      Rate
+		spreadBps int64
+		amount    int64
+		want      int64
+		wantErr   error
+	}{
+		{
+			name:   "When there is no spread",
+			rate:   usdEur,
+			amount: 100,
+			want:   92,
+		},
+		{
+			name:      "When spread is charged",
+			rate:      usdEur,
+			spreadBps: 50,
+			amount:    1000,
+			want:      915,
+		},
+		{
+			name:   "When converted amount has to be rounded down",
+			rate:   usdEur,
+			amount: 3,
+			want:   2,
+		},
+		{
+			name:    "When converted amount rounds down to zero",
+			rate:    usdEur,
+			amount:  1,
+			wantErr: ErrAmountTooSmall,
+		},
+		{
+			name:   "When the destination has fewer minor units",
+			rate:   usdJpy,
+			amount: 100,
+			want:   150,
+		},
+		{
+			name:   "When the source has fewer minor units",
+			rate:   Rate{From: "JPY", To: "USD", Value: 6_667},
+			amount: 150,
+			want:   100,
+		},
+		{
+			name:    "When a currency is unknown",
+			rate:    Rate{From: "USD", To: "GBP", Value: 790_000},
+			amount:  100,
+			wantErr: currency.ErrUnknownCurrency,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			quote, err := NewQuote(testCurrencies, tt.rate, tt.spreadBps, tt.amount)
+			require.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				require.Equal(t, tt.want, quote.ConvertedAmount)
+				require.Equal(t, tt.amount, quote.Amount)
+				require.Equal(t, tt.rate, quote.Rate)
+				require.Equal(t, tt.spreadBps, quote.SpreadBps)
+			}
+		})
+	}
+}
+
+func TestNewQuoteInvalidSpread(t *testing.T) {
+	usdEur := Rate{From: "USD", To: "EUR", Value: 920_000}
+
+	_, err := NewQuote(testCurrencies, usdEur, maxSpreadBps, 100)
+	require.Error(t, err)
+
+	_, err = NewQuote(testCurrencies, usdEur, -1, 100)
+	require.Error(t, err)
+}
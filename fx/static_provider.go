@@ -0,0 +1,68 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+//StaticRateProvider serves fixed rates, the inverse of a configured pair is derived when missing
+type StaticRateProvider struct {
+	rates map[string]int64
+}
+
+//NewStaticRateProvider creates a StaticRateProvider from decimal rates keyed by "FROM/TO", e.g. "USD/EUR": "0.92"
+func NewStaticRateProvider(rates map[string]string) (*StaticRateProvider, error) {
+	provider := &StaticRateProvider{rates: make(map[string]int64, len(rates))}
+	for pair, rate := range rates {
+		from, to, ok := strings.Cut(pair, "/")
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("invalid currency pair %q: must be FROM/TO", pair)
+		}
+
+		value, err := ParseRate(rate)
+		if err != nil {
+			return nil, fmt.Errorf("pair %s: %w", pair, err)
+		}
+		provider.rates[pairKey(from, to)] = value
+	}
+	return provider, nil
+}
+
+//LoadStaticRateProvider creates a StaticRateProvider from a JSON file holding the rates given to NewStaticRateProvider
+func LoadStaticRateProvider(path string) (*StaticRateProvider, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rates map[string]string
+	if err := json.Unmarshal(content, &rates); err != nil {
+		return nil, fmt.Errorf("cannot parse rates file %s: %w", path, err)
+	}
+
+	return NewStaticRateProvider(rates)
+}
+
+//Rate returns the configured rate for the pair, its derived inverse, or 1 for the same currency
+func (p *StaticRateProvider) Rate(_ context.Context, from string, to string) (Rate, error) {
+	if from == to {
+		return Rate{From: from, To: to, Value: RateScale}, nil
+	}
+
+	if value, ok := p.rates[pairKey(from, to)]; ok {
+		return Rate{From: from, To: to, Value: value}, nil
+	}
+
+	if value, ok := p.rates[pairKey(to, from)]; ok {
+		return Rate{From: to, To: from, Value: value}.Inverse(), nil
+	}
+
+	return Rate{}, fmt.Errorf("%w: %s/%s", ErrRateNotFound, from, to)
+}
+
+func pairKey(from string, to string) string {
+	return from + "/" + to
+}
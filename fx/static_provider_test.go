@@ -0,0 +1,66 @@
+package fx
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStaticRateProvider_Rate(t *testing.T) {
+	provider, err := LoadStaticRateProvider("testdata/rates.json")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		want    Rate
+		wantErr error
+	}{
+		{
+			name: "When pair is configured",
+			from: "USD",
+			to:   "EUR",
+			want: Rate{From: "USD", To: "EUR", Value: 920_000},
+		},
+		{
+			name: "When only the inverse pair is configured",
+			from: "EUR",
+			to:   "USD",
+			want: Rate{From: "EUR", To: "USD", Value: 1_086_956},
+		},
+		{
+			name: "When currencies are the same",
+			from: "EUR",
+			to:   "EUR",
+			want: Rate{From: "EUR", To: "EUR", Value: RateScale},
+		},
+		{
+			name:    "When pair is unknown",
+			from:    "USD",
+			to:      "BRL",
+			wantErr: ErrRateNotFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := provider.Rate(context.Background(), tt.from, tt.to)
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewStaticRateProvider(t *testing.T) {
+	_, err := NewStaticRateProvider(map[string]string{"USDEUR": "0.92"})
+	require.Error(t, err)
+
+	_, err = NewStaticRateProvider(map[string]string{"USD/EUR": "-0.92"})
+	require.ErrorIs(t, err, ErrInvalidRate)
+
+	_, err = LoadStaticRateProvider("testdata/missing.json")
+	require.Error(t, err)
+}
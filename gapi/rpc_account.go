@@ -0,0 +1,148 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"simplebank/cursor"
+	db "simplebank/db/sqlc"
+	"simplebank/pb"
+)
+
+//CreateAccount creates an account owned by the authenticated user
+func (s *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	payload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateCurrency(req.GetCurrency()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	account, err := s.store.CreateAccountTx(ctx, db.CreateAccountParams{
+		Owner:    payload.Username,
+		Balance:  0,
+		Currency: req.GetCurrency(),
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, "unknown error")
+	}
+
+	return &pb.CreateAccountResponse{Account: convertAccount(account)}, nil
+}
+
+//GetAccount returns an account owned by the authenticated user
+func (s *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	payload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateID("id", req.GetId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	account, err := s.store.GetAccount(ctx, req.GetId())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, "account not found")
+		}
+		return nil, status.Error(codes.Internal, "unknown error")
+	}
+
+	if account.Owner != payload.Username {
+		return nil, status.Error(codes.PermissionDenied, "account doesn't belong to the authenticated user")
+	}
+
+	return &pb.GetAccountResponse{Account: convertAccount(account)}, nil
+}
+
+//ListAccounts lists the accounts owned by the authenticated user.
+//It pages by cursor unless the deprecated page_id is given.
+func (s *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	payload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetPageSize() < 5 || req.GetPageSize() > 20 {
+		return nil, status.Error(codes.InvalidArgument, "page_size must be between 5 and 20")
+	}
+
+	if req.GetPageId() != 0 {
+		return s.listAccountsByOffset(ctx, payload.Username, req)
+	}
+
+	var current *cursor.Cursor
+	if req.GetCursor() != "" {
+		c, err := s.cursors.Decode(req.GetCursor())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		current = &c
+	}
+
+	var accounts []db.Account
+	if current != nil && current.Backward {
+		accounts, err = s.store.ListAccountsByOwnerBefore(ctx, db.ListAccountsByOwnerBeforeParams{
+			Owner:           payload.Username,
+			CursorCreatedAt: current.CreatedAt,
+			CursorID:        current.ID,
+			Limit:           req.GetPageSize() + 1,
+		})
+	} else {
+		params := db.ListAccountsByOwnerAfterParams{
+			Owner: payload.Username,
+			Limit: req.GetPageSize() + 1,
+		}
+		if current != nil {
+			params.CursorCreatedAt = sql.NullTime{Time: current.CreatedAt, Valid: true}
+			params.CursorID = sql.NullInt64{Int64: current.ID, Valid: true}
+		}
+		accounts, err = s.store.ListAccountsByOwnerAfter(ctx, params)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "unknown error")
+	}
+
+	page, next, prev := cursor.Paginate(s.cursors, accounts, req.GetPageSize(), current, func(account db.Account) cursor.Cursor {
+		return cursor.Cursor{CreatedAt: account.CreatedAt, ID: account.ID}
+	})
+
+	rsp := &pb.ListAccountsResponse{
+		Accounts:   make([]*pb.Account, 0, len(page)),
+		NextCursor: next,
+		PrevCursor: prev,
+	}
+	for _, account := range page {
+		rsp.Accounts = append(rsp.Accounts, convertAccount(account))
+	}
+
+	return rsp, nil
+}
+
+//listAccountsByOffset serves the deprecated page_id paging, kept for one release
+func (s *Server) listAccountsByOffset(ctx context.Context, owner string, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	if req.GetPageId() < 1 || req.GetCursor() != "" {
+		return nil, status.Error(codes.InvalidArgument, "page_id must be at least 1 and can't be combined with cursor")
+	}
+
+	accounts, err := s.store.ListAccountsByOwner(ctx, db.ListAccountsByOwnerParams{
+		Owner:  owner,
+		Limit:  req.GetPageSize(),
+		Offset: (req.GetPageId() - 1) * req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, "unknown error")
+	}
+
+	rsp := &pb.ListAccountsResponse{Accounts: make([]*pb.Account, 0, len(accounts))}
+	for _, account := range accounts {
+		rsp.Accounts = append(rsp.Accounts, convertAccount(account))
+	}
+
+	return rsp, nil
+}
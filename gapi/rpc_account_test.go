@@ -0,0 +1,281 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	db "simplebank/db/sqlc"
+	mockdb "simplebank/db/sqlc/mock"
+	"simplebank/pb"
+	"simplebank/token"
+	"testing"
+	"time"
+)
+
+var (
+	defaultCreatedAt = time.Date(2022, time.April, 24, 21, 18, 0, 0, time.UTC)
+)
+
+func TestServer_GetAccount(t *testing.T) {
+	account := db.Account{
+		ID:        10,
+		Owner:     "perotto",
+		Balance:   100,
+		Currency:  "USD",
+		CreatedAt: defaultCreatedAt,
+	}
+
+	tests := []struct {
+		name          string
+		accountID     int64
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		buildStubs    func(store *mockdb.MockStore)
+		runAssertions func(t *testing.T, rsp *pb.GetAccountResponse, err error)
+	}{
+		{
+			name:      "When it successfully finds the account",
+			accountID: account.ID,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, account.Owner, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+			},
+			runAssertions: func(t *testing.T, rsp *pb.GetAccountResponse, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, account.ID, rsp.GetAccount().GetId())
+				assert.Equal(t, account.Owner, rsp.GetAccount().GetOwner())
+				assert.Equal(t, account.Balance, rsp.GetAccount().GetBalance())
+				assert.Equal(t, account.CreatedAt, rsp.GetAccount().GetCreatedAt().AsTime())
+			},
+		},
+		{
+			name:      "When account is not found",
+			accountID: account.ID,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, account.Owner, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			runAssertions: func(t *testing.T, rsp *pb.GetAccountResponse, err error) {
+				assert.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name:      "When account belongs to another user",
+			accountID: account.ID,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, "emmanuel", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+			},
+			runAssertions: func(t *testing.T, rsp *pb.GetAccountResponse, err error) {
+				assert.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name:      "When sending id less than 1",
+			accountID: 0,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, account.Owner, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			runAssertions: func(t *testing.T, rsp *pb.GetAccountResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name:      "When token is not provided",
+			accountID: account.ID,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			runAssertions: func(t *testing.T, rsp *pb.GetAccountResponse, err error) {
+				assert.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:      "When there is a generic error fetching account",
+			accountID: account.ID,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, account.Owner, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(db.Account{}, errors.New("run, it's all broken"))
+			},
+			runAssertions: func(t *testing.T, rsp *pb.GetAccountResponse, err error) {
+				assert.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tt.buildStubs(store)
+
+			client, tokenMaker := newTestClient(t, store)
+			rsp, err := client.GetAccount(tt.buildContext(t, tokenMaker), &pb.GetAccountRequest{Id: tt.accountID})
+
+			tt.runAssertions(t, rsp, err)
+		})
+	}
+}
+
+func TestServer_CreateAccount(t *testing.T) {
+	tests := []struct {
+		name          string
+		currency      string
+		buildStubs    func(store *mockdb.MockStore)
+		runAssertions func(t *testing.T, rsp *pb.CreateAccountResponse, err error)
+	}{
+		{
+			name:     "When it succeeds",
+			currency: "USD",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccountTx(gomock.Any(), db.CreateAccountParams{
+					Owner:    "perotto",
+					Balance:  0,
+					Currency: "USD",
+				}).
+					Times(1).
+					Return(db.Account{ID: 1, Owner: "perotto", Currency: "USD", CreatedAt: defaultCreatedAt}, nil)
+			},
+			runAssertions: func(t *testing.T, rsp *pb.CreateAccountResponse, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, int64(1), rsp.GetAccount().GetId())
+				assert.Equal(t, "perotto", rsp.GetAccount().GetOwner())
+			},
+		},
+		{
+			name:     "When currency is not supported",
+			currency: "XYZ",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			runAssertions: func(t *testing.T, rsp *pb.CreateAccountResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tt.buildStubs(store)
+
+			client, tokenMaker := newTestClient(t, store)
+			ctx := newContextWithBearerToken(t, tokenMaker, "perotto", time.Minute)
+			rsp, err := client.CreateAccount(ctx, &pb.CreateAccountRequest{Currency: tt.currency})
+
+			tt.runAssertions(t, rsp, err)
+		})
+	}
+}
+
+func TestServer_ListAccounts(t *testing.T) {
+	accounts := make([]db.Account, 6)
+	for i := range accounts {
+		accounts[i] = db.Account{
+			ID:        int64(i + 1),
+			Owner:     "perotto",
+			Currency:  "USD",
+			CreatedAt: defaultCreatedAt,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		req           *pb.ListAccountsRequest
+		buildStubs    func(store *mockdb.MockStore)
+		runAssertions func(t *testing.T, rsp *pb.ListAccountsResponse, err error)
+	}{
+		{
+			name: "When it lists the first page by cursor",
+			req:  &pb.ListAccountsRequest{PageSize: 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsByOwnerAfter(gomock.Any(), db.ListAccountsByOwnerAfterParams{
+					Owner: "perotto",
+					Limit: 6,
+				}).
+					Times(1).
+					Return(accounts, nil)
+			},
+			runAssertions: func(t *testing.T, rsp *pb.ListAccountsResponse, err error) {
+				require.NoError(t, err)
+				assert.Len(t, rsp.GetAccounts(), 5)
+				assert.NotEmpty(t, rsp.GetNextCursor())
+				assert.Empty(t, rsp.GetPrevCursor())
+			},
+		},
+		{
+			name: "When it lists by the deprecated page_id",
+			req:  &pb.ListAccountsRequest{PageId: 2, PageSize: 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsByOwner(gomock.Any(), db.ListAccountsByOwnerParams{
+					Owner:  "perotto",
+					Limit:  5,
+					Offset: 5,
+				}).
+					Times(1).
+					Return(accounts[5:], nil)
+			},
+			runAssertions: func(t *testing.T, rsp *pb.ListAccountsResponse, err error) {
+				require.NoError(t, err)
+				assert.Len(t, rsp.GetAccounts(), 1)
+				assert.Empty(t, rsp.GetNextCursor())
+			},
+		},
+		{
+			name: "When cursor is invalid",
+			req:  &pb.ListAccountsRequest{PageSize: 5, Cursor: "forged.cursor"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsByOwnerAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			runAssertions: func(t *testing.T, rsp *pb.ListAccountsResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "When page size is out of range",
+			req:  &pb.ListAccountsRequest{PageSize: 50},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsByOwnerAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			runAssertions: func(t *testing.T, rsp *pb.ListAccountsResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tt.buildStubs(store)
+
+			client, tokenMaker := newTestClient(t, store)
+			ctx := newContextWithBearerToken(t, tokenMaker, "perotto", time.Minute)
+			rsp, err := client.ListAccounts(ctx, tt.req)
+
+			tt.runAssertions(t, rsp, err)
+		})
+	}
+}
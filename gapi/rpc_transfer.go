@@ -0,0 +1,98 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	db "simplebank/db/sqlc"
+	"simplebank/pb"
+)
+
+//CreateTransfer moves money from an account owned by the authenticated user to another account
+func (s *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	payload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateCreateTransferRequest(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	fromAccount, err := s.validAccount(ctx, req.GetFromAccountId(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	if fromAccount.Owner != payload.Username {
+		return nil, status.Error(codes.PermissionDenied, "account doesn't belong to the authenticated user")
+	}
+
+	if _, err := s.validAccount(ctx, req.GetToAccountId(), req.GetCurrency()); err != nil {
+		return nil, err
+	}
+
+	result, err := s.store.TransferTx(ctx, db.TransferTxParams{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        req.GetAmount(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrInsufficientFunds), errors.Is(err, db.ErrAccountFrozen), errors.Is(err, db.ErrAccountClosed),
+			errors.Is(err, db.ErrTransferLimitExceeded):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		case errors.Is(err, db.ErrInvalidAmount):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, "unknown error")
+		}
+	}
+
+	return &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+		ToEntry:     convertEntry(result.ToEntry),
+	}, nil
+}
+
+func validateCreateTransferRequest(req *pb.CreateTransferRequest) error {
+	if err := validateID("from_account_id", req.GetFromAccountId()); err != nil {
+		return err
+	}
+
+	if err := validateID("to_account_id", req.GetToAccountId()); err != nil {
+		return err
+	}
+
+	if req.GetFromAccountId() == req.GetToAccountId() {
+		return errors.New("from_account_id and to_account_id must be different")
+	}
+
+	if req.GetAmount() <= 0 {
+		return db.ErrInvalidAmount
+	}
+
+	return validateCurrency(req.GetCurrency())
+}
+
+//validAccount checks that the account exists and holds the given currency
+func (s *Server) validAccount(ctx context.Context, accountID int64, currency string) (db.Account, error) {
+	account, err := s.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return account, status.Errorf(codes.NotFound, "account %d not found", accountID)
+		}
+		return account, status.Error(codes.Internal, "unknown error")
+	}
+
+	if account.Currency != currency {
+		return account, status.Errorf(codes.InvalidArgument, "account %d currency mismatch: %s vs %s", accountID, account.Currency, currency)
+	}
+
+	return account, nil
+}
@@ -0,0 +1,145 @@
+package gapi
+
+import (
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	db "simplebank/db/sqlc"
+	mockdb "simplebank/db/sqlc/mock"
+	"simplebank/pb"
+	"testing"
+	"time"
+)
+
+func TestServer_CreateTransfer(t *testing.T) {
+	fromAccount := db.Account{ID: 1, Owner: "perotto", Balance: 100, Currency: "USD"}
+	toAccount := db.Account{ID: 2, Owner: "emmanuel", Balance: 50, Currency: "USD"}
+
+	tests := []struct {
+		name          string
+		username      string
+		request       *pb.CreateTransferRequest
+		buildStubs    func(store *mockdb.MockStore)
+		runAssertions func(t *testing.T, rsp *pb.CreateTransferResponse, err error)
+	}{
+		{
+			name:     "When it succeeds",
+			username: fromAccount.Owner,
+			request:  &pb.CreateTransferRequest{FromAccountId: 1, ToAccountId: 2, Amount: 10, Currency: "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAccount.ID).Times(1).Return(toAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), db.TransferTxParams{
+					FromAccountID: fromAccount.ID,
+					ToAccountID:   toAccount.ID,
+					Amount:        10,
+				}).
+					Times(1).
+					Return(db.TransferTxResult{
+						Transfer:  db.Transfer{ID: 7, FromAccountID: 1, ToAccountID: 2, Amount: 10},
+						FromEntry: db.Entry{ID: 1, AccountID: 1, Amount: -10},
+						ToEntry:   db.Entry{ID: 2, AccountID: 2, Amount: 10},
+					}, nil)
+			},
+			runAssertions: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, int64(7), rsp.GetTransfer().GetId())
+				assert.Equal(t, int64(-10), rsp.GetFromEntry().GetAmount())
+				assert.Equal(t, int64(10), rsp.GetToEntry().GetAmount())
+			},
+		},
+		{
+			name:     "When source has insufficient funds",
+			username: fromAccount.Owner,
+			request:  &pb.CreateTransferRequest{FromAccountId: 1, ToAccountId: 2, Amount: 1000, Currency: "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAccount.ID).Times(1).Return(toAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			runAssertions: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name:     "When a transfer limit is exceeded",
+			username: fromAccount.Owner,
+			request:  &pb.CreateTransferRequest{FromAccountId: 1, ToAccountId: 2, Amount: 10, Currency: "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAccount.ID).Times(1).Return(toAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrTransferLimitExceeded)
+			},
+			runAssertions: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name:     "When destination account is not found",
+			username: fromAccount.Owner,
+			request:  &pb.CreateTransferRequest{FromAccountId: 1, ToAccountId: 2, Amount: 10, Currency: "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAccount.ID).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			runAssertions: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				assert.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name:     "When source account belongs to another user",
+			username: toAccount.Owner,
+			request:  &pb.CreateTransferRequest{FromAccountId: 1, ToAccountId: 2, Amount: 10, Currency: "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			runAssertions: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				assert.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name:     "When source and destination are the same account",
+			username: fromAccount.Owner,
+			request:  &pb.CreateTransferRequest{FromAccountId: 1, ToAccountId: 1, Amount: 10, Currency: "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			runAssertions: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name:     "When amount is not positive",
+			username: fromAccount.Owner,
+			request:  &pb.CreateTransferRequest{FromAccountId: 1, ToAccountId: 2, Amount: 0, Currency: "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			runAssertions: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tt.buildStubs(store)
+
+			client, tokenMaker := newTestClient(t, store)
+			ctx := newContextWithBearerToken(t, tokenMaker, tt.username, time.Minute)
+			rsp, err := client.CreateTransfer(ctx, tt.request)
+
+			tt.runAssertions(t, rsp, err)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package gapi
+
+import (
+	"fmt"
+	"simplebank/cursor"
+	db "simplebank/db/sqlc"
+	"simplebank/pb"
+	"simplebank/token"
+	"simplebank/util"
+)
+
+//Server serves gRPC requests for our banking service.
+type Server struct {
+	pb.UnimplementedSimpleBankServer
+	config     util.Config
+	store      db.Store
+	tokenMaker token.Maker
+	cursors    cursor.Codec
+}
+
+//NewServer builds a Server struct
+func NewServer(config util.Config, store db.Store) (*Server, error) {
+	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	cursors, err := cursor.NewCodec(config.CursorSigningKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create cursor codec: %w", err)
+	}
+
+	return &Server{
+		config:     config,
+		store:      store,
+		tokenMaker: tokenMaker,
+		cursors:    cursors,
+	}, nil
+}
@@ -0,0 +1,21 @@
+package gapi
+
+import (
+	"fmt"
+	"simplebank/currency"
+)
+
+//validateCurrency accepts the enabled currencies of the registry, like the currency binding of the HTTP API
+func validateCurrency(code string) error {
+	if !currency.Default.IsEnabled(code) {
+		return fmt.Errorf("unsupported currency %q", code)
+	}
+	return nil
+}
+
+func validateID(field string, id int64) error {
+	if id < 1 {
+		return fmt.Errorf("%s must be greater than or equal to 1", field)
+	}
+	return nil
+}
@@ -1,12 +1,30 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	_ "github.com/lib/pq"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 	"log"
+	"net"
+	"os"
 	"simplebank/api"
+	"simplebank/currency"
 	db "simplebank/db/sqlc"
+	"simplebank/gapi"
+	"simplebank/outbox"
+	"simplebank/pb"
 	"simplebank/util"
+	"simplebank/webhook"
+	"simplebank/worker"
+)
+
+const (
+	//reconcileIssuesExitCode is the exit code of the reconcile command when the ledger doesn't balance
+	reconcileIssuesExitCode = 1
+	//reconcileFailedExitCode is the exit code of the reconcile command when the checks couldn't run
+	reconcileFailedExitCode = 2
 )
 
 func main() {
@@ -29,9 +47,97 @@ func main() {
 	}
 
 	store := db.NewStore(sqlDB)
-	server := api.NewServer(store)
+
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		code := reconcile(store)
+		if err := sqlDB.Close(); err != nil {
+			log.Print("Error closing DB: ", err)
+		}
+		os.Exit(code)
+	}
+
+	if err := loadCurrencies(store); err != nil {
+		log.Fatal("Cannot load currencies: ", err)
+	}
+
+	go worker.NewHoldExpirer(store).Run(context.Background(), config.HoldExpiryInterval)
+	go worker.NewIdempotencyKeySweeper(store).Run(context.Background(), config.IdempotencyKeySweepInterval)
+	go worker.NewScheduledTransferExecutor(store, worker.LogNotifier{}, config).Run(context.Background(), config.ScheduledTransferInterval)
+	go worker.NewOverdraftInterestCharger(store, config).Run(context.Background())
+	go worker.NewReconciler(store).Run(context.Background(), config.ReconciliationInterval)
+	go worker.NewTransferBatchProcessor(store).Run(context.Background(), config.TransferBatchInterval)
+
+	filePublisher, err := outbox.NewFilePublisher(config.OutboxFile)
+	if err != nil {
+		log.Fatal("Cannot open outbox file: ", err)
+	}
+	publisher := outbox.MultiPublisher{filePublisher, webhook.NewPublisher(store)}
+	go worker.NewOutboxRelay(store, publisher, config).Run(context.Background(), config.OutboxRelayInterval)
+	go worker.NewWebhookDispatcher(store, webhook.NewSender(config.WebhookTimeout), config).Run(context.Background(), config.WebhookDispatchInterval)
+
+	go runGrpcServer(config, store)
+	runGinServer(config, store)
+}
+
+//loadCurrencies replaces the built-in currency registry with the currencies table
+func loadCurrencies(store db.Store) error {
+	rows, err := store.ListCurrencies(context.Background())
+	if err != nil {
+		return err
+	}
+
+	currencies := make([]currency.Currency, 0, len(rows))
+	for _, row := range rows {
+		currencies = append(currencies, currency.Currency(row))
+	}
+	return currency.Default.Load(currencies)
+}
+
+//reconcile runs the ledger reconciliation once for CI and cron, the returned exit code is non-zero
+//when the ledger doesn't balance or the checks couldn't run
+func reconcile(store db.Store) int {
+	result, err := worker.NewReconciler(store).RunOnce(context.Background())
+	if err != nil {
+		log.Print("Can't reconcile the ledger: ", err)
+		return reconcileFailedExitCode
+	}
+
+	if result.Run.IssuesCount > 0 {
+		return reconcileIssuesExitCode
+	}
+	return 0
+}
+
+//runGinServer starts the HTTP API and blocks until it stops
+func runGinServer(config util.Config, store db.Store) {
+	server, err := api.NewServer(config, store)
+	if err != nil {
+		log.Fatal("Can't create server: ", err)
+	}
 
 	if err := server.Start(config.ServerAddress); err != nil {
 		log.Fatal("Can't start server: ", err)
 	}
 }
+
+//runGrpcServer starts the gRPC API and blocks until it stops
+func runGrpcServer(config util.Config, store db.Store) {
+	server, err := gapi.NewServer(config, store)
+	if err != nil {
+		log.Fatal("Can't create gRPC server: ", err)
+	}
+
+	grpcServer := grpc.NewServer()
+	pb.RegisterSimpleBankServer(grpcServer, server)
+	reflection.Register(grpcServer)
+
+	listener, err := net.Listen("tcp", config.GRPCServerAddress)
+	if err != nil {
+		log.Fatal("Can't create gRPC listener: ", err)
+	}
+
+	log.Printf("Starting gRPC server at %s", listener.Addr().String())
+	if err := grpcServer.Serve(listener); err != nil {
+		log.Fatal("Can't start gRPC server: ", err)
+	}
+}
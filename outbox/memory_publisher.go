@@ -0,0 +1,34 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+)
+
+//MemoryPublisher keeps the published events in memory, it is meant for tests
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+//NewMemoryPublisher builds an empty MemoryPublisher
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+//Publish records the event
+func (p *MemoryPublisher) Publish(_ context.Context, event Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = append(p.events, event)
+	return nil
+}
+
+//Events returns the published events in publication order
+func (p *MemoryPublisher) Events() []Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Event(nil), p.events...)
+}
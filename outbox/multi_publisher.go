@@ -0,0 +1,20 @@
+package outbox
+
+import (
+	"context"
+)
+
+//MultiPublisher hands every event to several publishers in order.
+//It fails as soon as one of them fails, so the relay retries the event on all of them and each must tolerate
+//receiving an event again.
+type MultiPublisher []Publisher
+
+//Publish hands the event to every publisher
+func (p MultiPublisher) Publish(ctx context.Context, event Event) error {
+	for _, publisher := range p {
+		if err := publisher.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+type (
+	//Event is a domain event relayed from the outbox. Delivery is at least once, so consumers dedupe by ID.
+	Event struct {
+		ID            int64           `json:"id"`
+		AggregateType string          `json:"aggregate_type"`
+		AggregateID   int64           `json:"aggregate_id"`
+		Type          string          `json:"type"`
+		Payload       json.RawMessage `json:"payload"`
+		CreatedAt     time.Time       `json:"created_at"`
+	}
+	//Publisher delivers events to downstream consumers
+	Publisher interface {
+		//Publish returns once the event is durably delivered, an error makes the relay retry it later
+		Publish(ctx context.Context, event Event) error
+	}
+)
@@ -0,0 +1,105 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testEvents() []Event {
+	createdAt := time.Date(2022, time.May, 1, 9, 0, 0, 0, time.UTC)
+
+	return []Event{
+		{
+			ID:            1,
+			AggregateType: "account",
+			AggregateID:   3,
+			Type:          "AccountCreated",
+			Payload:       json.RawMessage(`{"account_id":3,"owner":"perotto"}`),
+			CreatedAt:     createdAt,
+		},
+		{
+			ID:            2,
+			AggregateType: "account",
+			AggregateID:   3,
+			Type:          "BalanceChanged",
+			Payload:       json.RawMessage(`{"account_id":3,"amount":50}`),
+			CreatedAt:     createdAt.Add(time.Minute),
+		},
+	}
+}
+
+func TestFilePublisher_Publish(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	ctx := context.Background()
+
+	publisher, err := NewFilePublisher(path)
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(ctx, testEvents()[0]))
+	require.NoError(t, publisher.Close())
+
+	//Reopening appends instead of truncating what was published before
+	publisher, err = NewFilePublisher(path)
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(ctx, testEvents()[1]))
+	require.NoError(t, publisher.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var published []Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		published = append(published, event)
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, testEvents(), published)
+}
+
+func TestMemoryPublisher_Publish(t *testing.T) {
+	publisher := NewMemoryPublisher()
+	ctx := context.Background()
+
+	for _, event := range testEvents() {
+		require.NoError(t, publisher.Publish(ctx, event))
+	}
+
+	events := publisher.Events()
+	require.Equal(t, testEvents(), events)
+
+	//The returned slice is a copy
+	events[0].ID = 99
+	require.Equal(t, int64(1), publisher.Events()[0].ID)
+}
+
+func TestMultiPublisher_Publish(t *testing.T) {
+	first := NewMemoryPublisher()
+	second := NewMemoryPublisher()
+	ctx := context.Background()
+
+	require.NoError(t, MultiPublisher{first, second}.Publish(ctx, testEvents()[0]))
+	require.Equal(t, testEvents()[:1], first.Events())
+	require.Equal(t, testEvents()[:1], second.Events())
+
+	//A failing publisher fails the event, so the relay retries it
+	err := MultiPublisher{first, failing{}, second}.Publish(ctx, testEvents()[1])
+	require.Error(t, err)
+	require.Len(t, first.Events(), 2)
+	require.Len(t, second.Events(), 1)
+}
+
+//failing refuses every event
+type failing struct{}
+
+func (failing) Publish(context.Context, Event) error {
+	return errors.New("broker unavailable")
+}
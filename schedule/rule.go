@@ -0,0 +1,117 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Supported recurrence frequencies, a subset of RFC 5545 RRULE
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+)
+
+//lastMonthDay is the BYMONTHDAY value of the last day of the month
+const lastMonthDay = -1
+
+//ErrInvalidRule is returned when a recurrence rule can't be parsed or uses unsupported parts
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+//Rule is a recurrence rule such as "FREQ=MONTHLY;BYMONTHDAY=1".
+//MonthDay is only used by monthly rules, -1 stands for the last day of the month.
+type Rule struct {
+	Freq     string
+	Interval int
+	MonthDay int
+}
+
+//ParseRule parses the FREQ, INTERVAL and BYMONTHDAY parts of an RRULE, with or without the "RRULE:" prefix
+func ParseRule(s string) (Rule, error) {
+	rule := Rule{Interval: 1}
+
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(s), "RRULE:"), ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Rule{}, fmt.Errorf("%w: %q is not NAME=VALUE", ErrInvalidRule, part)
+		}
+
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			rule.Freq = strings.ToUpper(value)
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return Rule{}, fmt.Errorf("%w: INTERVAL must be a positive integer", ErrInvalidRule)
+			}
+			rule.Interval = interval
+		case "BYMONTHDAY":
+			day, err := strconv.Atoi(value)
+			if err != nil || day == 0 || day < lastMonthDay || day > 31 {
+				return Rule{}, fmt.Errorf("%w: BYMONTHDAY must be between 1 and 31, or -1", ErrInvalidRule)
+			}
+			rule.MonthDay = day
+		default:
+			return Rule{}, fmt.Errorf("%w: %s is not supported", ErrInvalidRule, name)
+		}
+	}
+
+	switch rule.Freq {
+	case FreqDaily, FreqWeekly:
+		if rule.MonthDay != 0 {
+			return Rule{}, fmt.Errorf("%w: BYMONTHDAY requires FREQ=MONTHLY", ErrInvalidRule)
+		}
+	case FreqMonthly:
+	default:
+		return Rule{}, fmt.Errorf("%w: FREQ must be one of %s, %s or %s", ErrInvalidRule, FreqDaily, FreqWeekly, FreqMonthly)
+	}
+
+	return rule, nil
+}
+
+//Anchor pins a monthly rule without BYMONTHDAY to the day of its first occurrence,
+//so a run clamped to a short month doesn't move the following ones
+func (r Rule) Anchor(start time.Time) Rule {
+	if r.Freq == FreqMonthly && r.MonthDay == 0 {
+		r.MonthDay = start.Day()
+	}
+	return r
+}
+
+//Next returns the occurrence following prev, keeping its time of day
+func (r Rule) Next(prev time.Time) time.Time {
+	switch r.Freq {
+	case FreqDaily:
+		return prev.AddDate(0, 0, r.Interval)
+	case FreqWeekly:
+		return prev.AddDate(0, 0, 7*r.Interval)
+	default:
+		day := r.MonthDay
+		if day == 0 {
+			day = prev.Day()
+		}
+
+		//Day 1 never overflows, so the year and month can be normalized by time.Date
+		first := time.Date(prev.Year(), prev.Month()+time.Month(r.Interval), 1, prev.Hour(), prev.Minute(), prev.Second(), prev.Nanosecond(), prev.Location())
+		last := first.AddDate(0, 1, -1).Day()
+		if day == lastMonthDay || day > last {
+			day = last
+		}
+		return first.AddDate(0, 0, day-1)
+	}
+}
+
+//String returns the rule in RRULE form, without the "RRULE:" prefix
+func (r Rule) String() string {
+	s := "FREQ=" + r.Freq
+	if r.Interval > 1 {
+		s += ";INTERVAL=" + strconv.Itoa(r.Interval)
+	}
+	if r.MonthDay != 0 {
+		s += ";BYMONTHDAY=" + strconv.Itoa(r.MonthDay)
+	}
+	return s
+}
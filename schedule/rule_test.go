@@ -0,0 +1,84 @@
+package schedule
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		want    Rule
+		wantErr bool
+	}{
+		{name: "When rule is daily", rule: "FREQ=DAILY", want: Rule{Freq: FreqDaily, Interval: 1}},
+		{name: "When rule has the RRULE prefix", rule: "RRULE:FREQ=WEEKLY;INTERVAL=2", want: Rule{Freq: FreqWeekly, Interval: 2}},
+		{name: "When rule is monthly on a day", rule: "FREQ=MONTHLY;BYMONTHDAY=1", want: Rule{Freq: FreqMonthly, Interval: 1, MonthDay: 1}},
+		{name: "When rule is monthly on the last day", rule: "freq=monthly;bymonthday=-1", want: Rule{Freq: FreqMonthly, Interval: 1, MonthDay: -1}},
+		{name: "When frequency is missing", rule: "INTERVAL=2", wantErr: true},
+		{name: "When frequency is not supported", rule: "FREQ=HOURLY", wantErr: true},
+		{name: "When interval is not positive", rule: "FREQ=DAILY;INTERVAL=0", wantErr: true},
+		{name: "When month day is out of range", rule: "FREQ=MONTHLY;BYMONTHDAY=32", wantErr: true},
+		{name: "When month day is used by a weekly rule", rule: "FREQ=WEEKLY;BYMONTHDAY=3", wantErr: true},
+		{name: "When part is not supported", rule: "FREQ=DAILY;COUNT=3", wantErr: true},
+		{name: "When part is malformed", rule: "FREQ", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rule, err := ParseRule(tt.rule)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidRule)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, rule)
+
+			reparsed, err := ParseRule(rule.String())
+			require.NoError(t, err)
+			require.Equal(t, rule, reparsed)
+		})
+	}
+}
+
+func TestRule_Next(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 30, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		rule Rule
+		prev time.Time
+		want time.Time
+	}{
+		{name: "When rule is daily", rule: Rule{Freq: FreqDaily, Interval: 1}, prev: date(2022, time.February, 28), want: date(2022, time.March, 1)},
+		{name: "When rule is every other week", rule: Rule{Freq: FreqWeekly, Interval: 2}, prev: date(2022, time.December, 26), want: date(2023, time.January, 9)},
+		{name: "When rule is monthly on the 1st", rule: Rule{Freq: FreqMonthly, Interval: 1, MonthDay: 1}, prev: date(2022, time.December, 1), want: date(2023, time.January, 1)},
+		{name: "When month is shorter than the day", rule: Rule{Freq: FreqMonthly, Interval: 1, MonthDay: 31}, prev: date(2022, time.January, 31), want: date(2022, time.February, 28)},
+		{name: "When clamped run is followed by a longer month", rule: Rule{Freq: FreqMonthly, Interval: 1, MonthDay: 31}, prev: date(2022, time.February, 28), want: date(2022, time.March, 31)},
+		{name: "When rule is on the last day", rule: Rule{Freq: FreqMonthly, Interval: 1, MonthDay: -1}, prev: date(2024, time.January, 31), want: date(2024, time.February, 29)},
+		{name: "When rule is quarterly", rule: Rule{Freq: FreqMonthly, Interval: 3, MonthDay: 15}, prev: date(2022, time.November, 15), want: date(2023, time.February, 15)},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, tt.rule.Next(tt.prev))
+		})
+	}
+}
+
+func TestRule_Anchor(t *testing.T) {
+	start := time.Date(2022, time.January, 31, 0, 0, 0, 0, time.UTC)
+
+	rule := Rule{Freq: FreqMonthly, Interval: 1}.Anchor(start)
+	require.Equal(t, 31, rule.MonthDay)
+
+	weekly := Rule{Freq: FreqWeekly, Interval: 1}
+	require.Equal(t, weekly, weekly.Anchor(start))
+}
@@ -0,0 +1,195 @@
+package statement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"simplebank/currency"
+	"time"
+)
+
+const camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+
+//camt053Formatter writes an ISO 20022 camt.053.001.02 bank to customer statement, amounts are in major units
+type camt053Formatter struct{}
+
+type (
+	camtDocument struct {
+		XMLName   xml.Name      `xml:"Document"`
+		Namespace string        `xml:"xmlns,attr"`
+		Statement camtBkToCstmr `xml:"BkToCstmrStmt"`
+	}
+	camtBkToCstmr struct {
+		GroupHeader camtGroupHeader `xml:"GrpHdr"`
+		Stmt        camtStmt        `xml:"Stmt"`
+	}
+	camtGroupHeader struct {
+		MsgID   string `xml:"MsgId"`
+		CreDtTm string `xml:"CreDtTm"`
+	}
+	camtStmt struct {
+		ID       string        `xml:"Id"`
+		CreDtTm  string        `xml:"CreDtTm"`
+		FrToDt   camtFromTo    `xml:"FrToDt"`
+		Acct     camtAccount   `xml:"Acct"`
+		Balances []camtBalance `xml:"Bal"`
+		Entries  []camtEntry   `xml:"Ntry"`
+	}
+	camtFromTo struct {
+		FrDtTm string `xml:"FrDtTm"`
+		ToDtTm string `xml:"ToDtTm"`
+	}
+	camtAccount struct {
+		ID    camtAccountID `xml:"Id"`
+		Ccy   string        `xml:"Ccy,omitempty"`
+		Owner *camtParty    `xml:"Ownr,omitempty"`
+	}
+	camtAccountID struct {
+		Other string `xml:"Othr>Id"`
+	}
+	camtParty struct {
+		Name string `xml:"Nm"`
+	}
+	camtAmount struct {
+		Currency string `xml:"Ccy,attr"`
+		Value    string `xml:",chardata"`
+	}
+	camtBalance struct {
+		Code      string     `xml:"Tp>CdOrPrtry>Cd"`
+		Amount    camtAmount `xml:"Amt"`
+		CdtDbtInd string     `xml:"CdtDbtInd"`
+		DtTm      string     `xml:"Dt>DtTm"`
+	}
+	camtEntry struct {
+		NtryRef   string         `xml:"NtryRef"`
+		Amount    camtAmount     `xml:"Amt"`
+		CdtDbtInd string         `xml:"CdtDbtInd"`
+		Status    string         `xml:"Sts"`
+		BookgDt   string         `xml:"BookgDt>DtTm"`
+		ValDt     string         `xml:"ValDt>DtTm"`
+		BkTxCd    string         `xml:"BkTxCd>Prtry>Cd"`
+		Details   *camtTxDetails `xml:"NtryDtls>TxDtls,omitempty"`
+	}
+	camtTxDetails struct {
+		EndToEndID string         `xml:"Refs>EndToEndId,omitempty"`
+		Parties    *camtRltdPties `xml:"RltdPties,omitempty"`
+	}
+	camtRltdPties struct {
+		DebtorAccount   *camtAccount `xml:"DbtrAcct,omitempty"`
+		CreditorAccount *camtAccount `xml:"CdtrAcct,omitempty"`
+	}
+)
+
+func (camt053Formatter) ContentType() string {
+	return "application/xml"
+}
+
+func (camt053Formatter) FileExtension() string {
+	return "xml"
+}
+
+func (camt053Formatter) Write(w io.Writer, s Statement) error {
+	id := fmt.Sprintf("STMT-%d-%s", s.AccountID, s.From.UTC().Format("20060102"))
+
+	doc := camtDocument{
+		Namespace: camt053Namespace,
+		Statement: camtBkToCstmr{
+			GroupHeader: camtGroupHeader{
+				MsgID:   id,
+				CreDtTm: formatCamtTime(s.GeneratedAt),
+			},
+			Stmt: camtStmt{
+				ID:      id,
+				CreDtTm: formatCamtTime(s.GeneratedAt),
+				FrToDt: camtFromTo{
+					FrDtTm: formatCamtTime(s.From),
+					ToDtTm: formatCamtTime(s.To),
+				},
+				Acct: camtAccount{
+					ID:    camtAccountID{Other: fmt.Sprint(s.AccountID)},
+					Ccy:   s.Currency,
+					Owner: &camtParty{Name: s.Owner},
+				},
+				Balances: []camtBalance{
+					newCamtBalance("OPBD", s.OpeningBalance, s.Currency, s.From),
+					newCamtBalance("CLBD", s.ClosingBalance(), s.Currency, s.To),
+				},
+				Entries: make([]camtEntry, 0, len(s.Lines)),
+			},
+		},
+	}
+
+	for _, line := range s.Lines {
+		doc.Statement.Stmt.Entries = append(doc.Statement.Stmt.Entries, newCamtEntry(line, s.Currency))
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+//newCamtBalance builds a balance, camt.053 amounts are unsigned and the sign goes into CdtDbtInd
+func newCamtBalance(code string, balance int64, currencyCode string, at time.Time) camtBalance {
+	amount, indicator := camtSign(balance, currencyCode)
+	return camtBalance{
+		Code:      code,
+		Amount:    camtAmount{Currency: currencyCode, Value: amount},
+		CdtDbtInd: indicator,
+		DtTm:      formatCamtTime(at),
+	}
+}
+
+func newCamtEntry(line Line, currencyCode string) camtEntry {
+	amount, indicator := camtSign(line.Amount, currencyCode)
+	entry := camtEntry{
+		NtryRef:   fmt.Sprint(line.EntryID),
+		Amount:    camtAmount{Currency: currencyCode, Value: amount},
+		CdtDbtInd: indicator,
+		Status:    "BOOK",
+		BookgDt:   formatCamtTime(line.BookedAt),
+		ValDt:     formatCamtTime(line.BookedAt),
+		BkTxCd:    "TRANSFER",
+	}
+
+	if line.TransferID == 0 && line.CounterpartyAccountID == 0 {
+		return entry
+	}
+
+	details := &camtTxDetails{}
+	if line.TransferID != 0 {
+		details.EndToEndID = fmt.Sprint(line.TransferID)
+	}
+	if line.CounterpartyAccountID != 0 {
+		counterparty := &camtAccount{ID: camtAccountID{Other: fmt.Sprint(line.CounterpartyAccountID)}}
+		//Money leaving the account goes to a creditor, money arriving comes from a debtor
+		if line.Amount < 0 {
+			details.Parties = &camtRltdPties{CreditorAccount: counterparty}
+		} else {
+			details.Parties = &camtRltdPties{DebtorAccount: counterparty}
+		}
+	}
+	entry.Details = details
+
+	return entry
+}
+
+//camtSign formats the unsigned amount in major units and returns its credit or debit indicator
+func camtSign(amount int64, currencyCode string) (string, string) {
+	if amount < 0 {
+		return currency.Default.FormatAmount(currencyCode, -amount), "DBIT"
+	}
+	return currency.Default.FormatAmount(currencyCode, amount), "CRDT"
+}
+
+func formatCamtTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05")
+}
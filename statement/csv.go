@@ -0,0 +1,62 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+//csvFormatter writes one row per entry between the opening and closing balance rows, each with the running balance
+type csvFormatter struct{}
+
+func (csvFormatter) ContentType() string {
+	return "text/csv"
+}
+
+func (csvFormatter) FileExtension() string {
+	return "csv"
+}
+
+func (csvFormatter) Write(w io.Writer, s Statement) error {
+	writer := csv.NewWriter(w)
+
+	rows := [][]string{
+		{"type", "date", "entry_id", "transfer_id", "counterparty_account_id", "amount", "balance", "currency"},
+		{"opening_balance", s.From.UTC().Format(time.RFC3339), "", "", "", "", formatInt(s.OpeningBalance), s.Currency},
+	}
+
+	balance := s.OpeningBalance
+	for _, line := range s.Lines {
+		balance += line.Amount
+		rows = append(rows, []string{
+			"entry",
+			line.BookedAt.UTC().Format(time.RFC3339),
+			formatInt(line.EntryID),
+			formatOptionalInt(line.TransferID),
+			formatOptionalInt(line.CounterpartyAccountID),
+			formatInt(line.Amount),
+			formatInt(balance),
+			s.Currency,
+		})
+	}
+
+	rows = append(rows, []string{"closing_balance", s.To.UTC().Format(time.RFC3339), "", "", "", "", formatInt(balance), s.Currency})
+
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+func formatInt(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
+
+//formatOptionalInt leaves unknown references blank instead of writing a zero
+func formatOptionalInt(v int64) string {
+	if v == 0 {
+		return ""
+	}
+	return formatInt(v)
+}
@@ -0,0 +1,160 @@
+package statement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"simplebank/currency"
+	"time"
+)
+
+const (
+	ofxHeader     = `<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>`
+	ofxTimeLayout = "20060102150405"
+	ofxBankID     = "SIMPLEBANK"
+)
+
+//ofxFormatter writes an OFX 2.2 bank statement response, amounts are in major units with the decimals of the currency
+type ofxFormatter struct{}
+
+type (
+	ofxDocument struct {
+		XMLName xml.Name   `xml:"OFX"`
+		SignOn  ofxSignOn  `xml:"SIGNONMSGSRSV1>SONRS"`
+		Bank    ofxStmtTrn `xml:"BANKMSGSRSV1>STMTTRNRS"`
+	}
+	ofxSignOn struct {
+		Status   ofxStatus `xml:"STATUS"`
+		DTServer string    `xml:"DTSERVER"`
+		Language string    `xml:"LANGUAGE"`
+	}
+	ofxStatus struct {
+		Code     int    `xml:"CODE"`
+		Severity string `xml:"SEVERITY"`
+	}
+	ofxStmtTrn struct {
+		TrnUID string    `xml:"TRNUID"`
+		Status ofxStatus `xml:"STATUS"`
+		Stmt   ofxStmt   `xml:"STMTRS"`
+	}
+	ofxStmt struct {
+		CurDef      string          `xml:"CURDEF"`
+		BankAcct    ofxBankAcct     `xml:"BANKACCTFROM"`
+		TranList    ofxTranList     `xml:"BANKTRANLIST"`
+		LedgerBal   ofxLedgerBal    `xml:"LEDGERBAL"`
+		BalanceList []ofxBalanceRef `xml:"BALLIST>BAL"`
+	}
+	ofxBankAcct struct {
+		BankID   string `xml:"BANKID"`
+		AcctID   int64  `xml:"ACCTID"`
+		AcctType string `xml:"ACCTTYPE"`
+	}
+	ofxTranList struct {
+		DTStart      string           `xml:"DTSTART"`
+		DTEnd        string           `xml:"DTEND"`
+		Transactions []ofxTransaction `xml:"STMTTRN"`
+	}
+	ofxTransaction struct {
+		TrnType  string `xml:"TRNTYPE"`
+		DTPosted string `xml:"DTPOSTED"`
+		TrnAmt   string `xml:"TRNAMT"`
+		FitID    int64  `xml:"FITID"`
+		Name     string `xml:"NAME,omitempty"`
+		Memo     string `xml:"MEMO,omitempty"`
+	}
+	ofxLedgerBal struct {
+		BalAmt string `xml:"BALAMT"`
+		DTAsOf string `xml:"DTASOF"`
+	}
+	ofxBalanceRef struct {
+		Name    string `xml:"NAME"`
+		Desc    string `xml:"DESC"`
+		BalType string `xml:"BALTYPE"`
+		Value   string `xml:"VALUE"`
+		DTAsOf  string `xml:"DTASOF"`
+	}
+)
+
+func (ofxFormatter) ContentType() string {
+	return "application/x-ofx"
+}
+
+func (ofxFormatter) FileExtension() string {
+	return "ofx"
+}
+
+func (ofxFormatter) Write(w io.Writer, s Statement) error {
+	doc := ofxDocument{
+		SignOn: ofxSignOn{
+			Status:   ofxStatus{Code: 0, Severity: "INFO"},
+			DTServer: formatOFXTime(s.GeneratedAt),
+			Language: "ENG",
+		},
+		Bank: ofxStmtTrn{
+			TrnUID: fmt.Sprintf("%d-%s", s.AccountID, formatOFXTime(s.From)),
+			Status: ofxStatus{Code: 0, Severity: "INFO"},
+			Stmt: ofxStmt{
+				CurDef: s.Currency,
+				BankAcct: ofxBankAcct{
+					BankID:   ofxBankID,
+					AcctID:   s.AccountID,
+					AcctType: "CHECKING",
+				},
+				TranList: ofxTranList{
+					DTStart:      formatOFXTime(s.From),
+					DTEnd:        formatOFXTime(s.To),
+					Transactions: make([]ofxTransaction, 0, len(s.Lines)),
+				},
+				LedgerBal: ofxLedgerBal{
+					BalAmt: currency.Default.FormatAmount(s.Currency, s.ClosingBalance()),
+					DTAsOf: formatOFXTime(s.To),
+				},
+				BalanceList: []ofxBalanceRef{
+					{
+						Name:    "Opening balance",
+						Desc:    "Balance at the start of the statement period",
+						BalType: "DOLLAR",
+						Value:   currency.Default.FormatAmount(s.Currency, s.OpeningBalance),
+						DTAsOf:  formatOFXTime(s.From),
+					},
+				},
+			},
+		},
+	}
+
+	for _, line := range s.Lines {
+		trn := ofxTransaction{
+			TrnType:  "CREDIT",
+			DTPosted: formatOFXTime(line.BookedAt),
+			TrnAmt:   currency.Default.FormatAmount(s.Currency, line.Amount),
+			FitID:    line.EntryID,
+		}
+		if line.Amount < 0 {
+			trn.TrnType = "DEBIT"
+		}
+		if line.CounterpartyAccountID != 0 {
+			trn.Name = fmt.Sprintf("Account %d", line.CounterpartyAccountID)
+		}
+		if line.TransferID != 0 {
+			trn.Memo = fmt.Sprintf("Transfer %d", line.TransferID)
+		}
+		doc.Bank.Stmt.TranList.Transactions = append(doc.Bank.Stmt.TranList.Transactions, trn)
+	}
+
+	if _, err := io.WriteString(w, xml.Header+ofxHeader+"\n"); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func formatOFXTime(t time.Time) string {
+	return t.UTC().Format(ofxTimeLayout)
+}
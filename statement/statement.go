@@ -0,0 +1,72 @@
+package statement
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+//Supported statement formats
+const (
+	FormatCSV     = "csv"
+	FormatOFX     = "ofx"
+	FormatCamt053 = "camt053"
+)
+
+//ErrUnsupportedFormat is returned when there is no Formatter for the requested format
+var ErrUnsupportedFormat = errors.New("unsupported statement format")
+
+type (
+	//Statement lists the entries booked on an account within [From, To)
+	Statement struct {
+		AccountID      int64
+		Owner          string
+		Currency       string
+		From           time.Time
+		To             time.Time
+		GeneratedAt    time.Time
+		OpeningBalance int64
+		Lines          []Line
+	}
+	//Line is an entry of the statement. TransferID and CounterpartyAccountID are zero when unknown.
+	Line struct {
+		EntryID               int64
+		TransferID            int64
+		CounterpartyAccountID int64
+		Amount                int64
+		BookedAt              time.Time
+	}
+	//Formatter writes a Statement in a specific file format
+	Formatter interface {
+		//ContentType returns the MIME type of the written statement
+		ContentType() string
+		//FileExtension returns the extension used to name the downloaded file
+		FileExtension() string
+		//Write encodes the statement into w
+		Write(w io.Writer, s Statement) error
+	}
+)
+
+//NewFormatter returns the Formatter of a supported format
+func NewFormatter(format string) (Formatter, error) {
+	switch format {
+	case FormatCSV:
+		return csvFormatter{}, nil
+	case FormatOFX:
+		return ofxFormatter{}, nil
+	case FormatCamt053:
+		return camt053Formatter{}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+//ClosingBalance returns the balance of the account after the last line of the statement
+func (s Statement) ClosingBalance() int64 {
+	balance := s.OpeningBalance
+	for _, line := range s.Lines {
+		balance += line.Amount
+	}
+	return balance
+}
@@ -0,0 +1,102 @@
+package statement
+
+import (
+	"bytes"
+	"flag"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+//update rewrites the golden files with the current output: go test ./statement -update
+var update = flag.Bool("update", false, "update golden files")
+
+func testStatement() Statement {
+	from := time.Date(2022, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	return Statement{
+		AccountID:      1,
+		Owner:          "perotto",
+		Currency:       "USD",
+		From:           from,
+		To:             from.AddDate(0, 1, 0),
+		GeneratedAt:    time.Date(2022, time.May, 2, 9, 30, 0, 0, time.UTC),
+		OpeningBalance: 100,
+		Lines: []Line{
+			{
+				EntryID:               10,
+				TransferID:            5,
+				CounterpartyAccountID: 2,
+				Amount:                -30,
+				BookedAt:              time.Date(2022, time.April, 3, 12, 0, 0, 0, time.UTC),
+			},
+			{
+				EntryID:               13,
+				TransferID:            7,
+				CounterpartyAccountID: 3,
+				Amount:                50,
+				BookedAt:              time.Date(2022, time.April, 15, 8, 45, 0, 0, time.UTC),
+			},
+			{
+				EntryID:  20,
+				Amount:   -200,
+				BookedAt: time.Date(2022, time.April, 28, 18, 10, 0, 0, time.UTC),
+			},
+		},
+	}
+}
+
+func TestFormatters(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		golden string
+	}{
+		{
+			name:   "When format is csv",
+			format: FormatCSV,
+			golden: "statement.csv.golden",
+		},
+		{
+			name:   "When format is ofx",
+			format: FormatOFX,
+			golden: "statement.ofx.golden",
+		},
+		{
+			name:   "When format is camt053",
+			format: FormatCamt053,
+			golden: "statement.camt053.golden",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			formatter, err := NewFormatter(tt.format)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			require.NoError(t, formatter.Write(&buf, testStatement()))
+
+			golden := filepath.Join("testdata", tt.golden)
+			if *update {
+				require.NoError(t, os.WriteFile(golden, buf.Bytes(), 0644))
+			}
+
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			require.Equal(t, string(want), buf.String())
+		})
+	}
+}
+
+func TestNewFormatter(t *testing.T) {
+	_, err := NewFormatter("pdf")
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestStatement_ClosingBalance(t *testing.T) {
+	require.Equal(t, int64(-80), testStatement().ClosingBalance())
+	require.Equal(t, int64(100), Statement{OpeningBalance: 100}.ClosingBalance())
+}
@@ -0,0 +1,109 @@
+package token
+
+import (
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+	"simplebank/util"
+	"testing"
+	"time"
+)
+
+func TestJWTMaker(t *testing.T) {
+	secretKey := util.RandomString(32)
+	maker, err := NewJWTMaker(secretKey)
+	require.NoError(t, err)
+
+	username := util.RandomOwner()
+
+	validToken, _, err := maker.CreateToken(username, TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	refreshToken, _, err := maker.CreateToken(username, TokenTypeRefresh, time.Minute)
+	require.NoError(t, err)
+
+	expiredToken, _, err := maker.CreateToken(username, TokenTypeAccess, -time.Minute)
+	require.NoError(t, err)
+
+	payload, err := NewPayload(username, TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	noneToken, err := jwt.NewWithClaims(jwt.SigningMethodNone, payload).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	hs512Token, err := jwt.NewWithClaims(jwt.SigningMethodHS512, payload).SignedString([]byte(secretKey))
+	require.NoError(t, err)
+
+	otherKeyToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, payload).SignedString([]byte(util.RandomString(32)))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{
+			name:  "When token is valid",
+			token: validToken,
+		},
+		{
+			name:    "When a refresh token is used as an access token",
+			token:   refreshToken,
+			wantErr: ErrWrongTokenType,
+		},
+		{
+			name:    "When token has expired",
+			token:   expiredToken,
+			wantErr: ErrExpiredToken,
+		},
+		{
+			name:    "When token was tampered with",
+			token:   tamper(validToken),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "When token was signed with another key",
+			token:   otherKeyToken,
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "When token uses none algorithm",
+			token:   noneToken,
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "When token uses another HMAC algorithm",
+			token:   hs512Token,
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "When token is empty",
+			token:   "",
+			wantErr: ErrInvalidToken,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			payload, err := maker.VerifyToken(tt.token, TokenTypeAccess)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				require.Nil(t, payload)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotZero(t, payload.ID)
+			require.Equal(t, username, payload.Username)
+			require.Equal(t, TokenTypeAccess, payload.Type)
+			require.WithinDuration(t, time.Now(), payload.IssuedAt, time.Second)
+			require.WithinDuration(t, time.Now().Add(time.Minute), payload.ExpiredAt, time.Second)
+		})
+	}
+}
+
+func TestNewJWTMaker(t *testing.T) {
+	_, err := NewJWTMaker(util.RandomString(31))
+	require.Error(t, err)
+}
@@ -0,0 +1,11 @@
+package token
+
+import "time"
+
+//Maker is an interface for managing tokens
+type Maker interface {
+	//CreateToken creates a new token of a specific type for a username and duration and returns it with its payload
+	CreateToken(username string, tokenType TokenType, duration time.Duration) (string, *Payload, error)
+	//VerifyToken checks if the token is valid and of the expected type and returns its payload
+	VerifyToken(token string, tokenType TokenType) (*Payload, error)
+}
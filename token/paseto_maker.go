@@ -0,0 +1,204 @@
+package token
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/chacha20"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	pasetoV4LocalHeader = "v4.local."
+	pasetoNonceSize     = 32
+	pasetoTagSize       = 32
+
+	pasetoEncryptionKeyInfo = "paseto-encryption-key"
+	pasetoAuthKeyInfo       = "paseto-auth-key-for-aead"
+)
+
+//PasetoMaker is a PASETO v4.local token maker.
+//Tokens are encrypted with XChaCha20 and authenticated with keyed BLAKE2b as described in the PASETO v4 specification.
+type PasetoMaker struct {
+	symmetricKey []byte
+}
+
+//NewPasetoMaker creates a new PasetoMaker
+func NewPasetoMaker(symmetricKey string) (Maker, error) {
+	if len(symmetricKey) != chacha20.KeySize {
+		return nil, fmt.Errorf("invalid key size: must be exactly %d characters", chacha20.KeySize)
+	}
+	return &PasetoMaker{symmetricKey: []byte(symmetricKey)}, nil
+}
+
+//CreateToken creates a new token of a specific type for a username and duration
+func (m *PasetoMaker) CreateToken(username string, tokenType TokenType, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, tokenType, duration)
+	if err != nil {
+		return "", nil, err
+	}
+
+	message, err := json.Marshal(payload)
+	if err != nil {
+		return "", nil, err
+	}
+
+	nonce := make([]byte, pasetoNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, err
+	}
+
+	token, err := pasetoEncrypt(m.symmetricKey, nonce, message)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, payload, nil
+}
+
+//VerifyToken checks if the token is valid and of the expected type and returns its payload
+func (m *PasetoMaker) VerifyToken(token string, tokenType TokenType) (*Payload, error) {
+	message, err := pasetoDecrypt(m.symmetricKey, token)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &Payload{}
+	if err := json.Unmarshal(message, payload); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if err := payload.Valid(); err != nil {
+		return nil, err
+	}
+
+	if err := payload.verifyType(tokenType); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+//pasetoEncrypt encrypts message into a v4.local token without footer nor implicit assertion
+func pasetoEncrypt(key []byte, nonce []byte, message []byte) (string, error) {
+	encryptionKey, counterNonce, authKey, err := splitKeys(key, nonce)
+	if err != nil {
+		return "", err
+	}
+
+	cipher, err := chacha20.NewUnauthenticatedCipher(encryptionKey, counterNonce)
+	if err != nil {
+		return "", err
+	}
+	cipherText := make([]byte, len(message))
+	cipher.XORKeyStream(cipherText, message)
+
+	tag, err := pasetoTag(authKey, nonce, cipherText)
+	if err != nil {
+		return "", err
+	}
+
+	body := bytes.Join([][]byte{nonce, cipherText, tag}, nil)
+	return pasetoV4LocalHeader + base64.RawURLEncoding.EncodeToString(body), nil
+}
+
+//pasetoDecrypt checks the tag of a v4.local token and returns its decrypted message
+func pasetoDecrypt(key []byte, token string) ([]byte, error) {
+	if !strings.HasPrefix(token, pasetoV4LocalHeader) {
+		return nil, ErrInvalidToken
+	}
+
+	//Footers are not supported, so the token must have exactly three parts
+	encodedBody := strings.TrimPrefix(token, pasetoV4LocalHeader)
+	if strings.Contains(encodedBody, ".") {
+		return nil, ErrInvalidToken
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(encodedBody)
+	if err != nil || len(body) < pasetoNonceSize+pasetoTagSize {
+		return nil, ErrInvalidToken
+	}
+
+	nonce := body[:pasetoNonceSize]
+	cipherText := body[pasetoNonceSize : len(body)-pasetoTagSize]
+	tag := body[len(body)-pasetoTagSize:]
+
+	encryptionKey, counterNonce, authKey, err := splitKeys(key, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	expectedTag, err := pasetoTag(authKey, nonce, cipherText)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(tag, expectedTag) != 1 {
+		return nil, ErrInvalidToken
+	}
+
+	cipher, err := chacha20.NewUnauthenticatedCipher(encryptionKey, counterNonce)
+	if err != nil {
+		return nil, err
+	}
+	message := make([]byte, len(cipherText))
+	cipher.XORKeyStream(message, cipherText)
+	return message, nil
+}
+
+//splitKeys derives the encryption key, the XChaCha20 nonce and the authentication key from the key and the token nonce
+func splitKeys(key []byte, nonce []byte) (encryptionKey []byte, counterNonce []byte, authKey []byte, err error) {
+	tmp, err := keyedHash(key, chacha20.KeySize+chacha20.NonceSizeX, []byte(pasetoEncryptionKeyInfo), nonce)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	authKey, err = keyedHash(key, pasetoTagSize, []byte(pasetoAuthKeyInfo), nonce)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return tmp[:chacha20.KeySize], tmp[chacha20.KeySize:], authKey, nil
+}
+
+//pasetoTag computes the authentication tag over the pre-authentication encoding of the token pieces
+func pasetoTag(authKey []byte, nonce []byte, cipherText []byte) ([]byte, error) {
+	preAuth := preAuthEncode([]byte(pasetoV4LocalHeader), nonce, cipherText, nil, nil)
+	return keyedHash(authKey, pasetoTagSize, preAuth)
+}
+
+func keyedHash(key []byte, size int, pieces ...[]byte) ([]byte, error) {
+	hash, err := blake2b.New(size, key)
+	if err != nil {
+		return nil, err
+	}
+	for _, piece := range pieces {
+		hash.Write(piece)
+	}
+	return hash.Sum(nil), nil
+}
+
+//preAuthEncode implements PASETO's PAE, which encodes every piece prefixed by its length
+func preAuthEncode(pieces ...[]byte) []byte {
+	var buf bytes.Buffer
+
+	buf.Write(le64(len(pieces)))
+	for _, piece := range pieces {
+		buf.Write(le64(len(piece)))
+		buf.Write(piece)
+	}
+
+	return buf.Bytes()
+}
+
+//le64 encodes n as 8 little-endian bytes with the most significant bit cleared, as PAE requires
+func le64(n int) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, uint64(n)&math.MaxInt64)
+	return b
+}
@@ -0,0 +1,173 @@
+package token
+
+import (
+	"encoding/hex"
+	"github.com/stretchr/testify/require"
+	"simplebank/util"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPasetoMaker(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	username := util.RandomOwner()
+
+	validToken, _, err := maker.CreateToken(username, TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	refreshToken, _, err := maker.CreateToken(username, TokenTypeRefresh, time.Minute)
+	require.NoError(t, err)
+
+	expiredToken, _, err := maker.CreateToken(username, TokenTypeAccess, -time.Minute)
+	require.NoError(t, err)
+
+	otherMaker, err := NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+	otherKeyToken, _, err := otherMaker.CreateToken(username, TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{
+			name:  "When token is valid",
+			token: validToken,
+		},
+		{
+			name:    "When a refresh token is used as an access token",
+			token:   refreshToken,
+			wantErr: ErrWrongTokenType,
+		},
+		{
+			name:    "When token has expired",
+			token:   expiredToken,
+			wantErr: ErrExpiredToken,
+		},
+		{
+			name:    "When token body was tampered with",
+			token:   tamper(validToken),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "When token was encrypted with another key",
+			token:   otherKeyToken,
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "When token has another version",
+			token:   strings.Replace(validToken, "v4.", "v2.", 1),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "When token has another purpose",
+			token:   strings.Replace(validToken, ".local.", ".public.", 1),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "When token has a footer",
+			token:   validToken + ".Zm9vdGVy",
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "When token is empty",
+			token:   "",
+			wantErr: ErrInvalidToken,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			payload, err := maker.VerifyToken(tt.token, TokenTypeAccess)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				require.Nil(t, payload)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotZero(t, payload.ID)
+			require.Equal(t, username, payload.Username)
+			require.Equal(t, TokenTypeAccess, payload.Type)
+			require.WithinDuration(t, time.Now(), payload.IssuedAt, time.Second)
+			require.WithinDuration(t, time.Now().Add(time.Minute), payload.ExpiredAt, time.Second)
+		})
+	}
+}
+
+func TestNewPasetoMaker(t *testing.T) {
+	_, err := NewPasetoMaker(util.RandomString(31))
+	require.Error(t, err)
+
+	_, err = NewPasetoMaker(util.RandomString(33))
+	require.Error(t, err)
+}
+
+//tamper flips one character in the middle of the token body
+func tamper(token string) string {
+	b := []byte(token)
+	i := len(b) / 2
+	if b[i] == 'A' {
+		b[i] = 'B'
+	} else {
+		b[i] = 'A'
+	}
+	return string(b)
+}
+
+//TestPasetoV4LocalVectors checks the v4.local vectors without footer nor implicit assertion of the official
+//PASETO test suite, https://github.com/paseto-standard/test-vectors/blob/master/v4.json
+func TestPasetoV4LocalVectors(t *testing.T) {
+	key, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		nonce   string
+		payload string
+		token   string
+	}{
+		{
+			name:    "4-E-1",
+			nonce:   "0000000000000000000000000000000000000000000000000000000000000000",
+			payload: `{"data":"this is a secret message","exp":"2022-01-01T00:00:00+00:00"}`,
+			token:   "v4.local.AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQAr68PS4AXe7If_ZgesdkUMvSwscFlAl1pk5HC0e8kApeaqMfGo_7OpBnwJOAbY9V7WU6abu74MmcUE8YWAiaArVI8XJ5hOb_4v9RmDkneN0S92dx0OW4pgy7omxgf3S8c3LlQg",
+		},
+		{
+			name:    "4-E-2",
+			nonce:   "0000000000000000000000000000000000000000000000000000000000000000",
+			payload: `{"data":"this is a hidden message","exp":"2022-01-01T00:00:00+00:00"}`,
+			token:   "v4.local.AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQAr68PS4AXe7If_ZgesdkUMvS2csCgglvpk5HC0e8kApeaqMfGo_7OpBnwJOAbY9V7WU6abu74MmcUE8YWAiaArVI8XIemu9chy3WVKvRBfg6t8wwYHK0ArLxxfZP73W_vfwt5A",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			nonce, err := hex.DecodeString(tt.nonce)
+			require.NoError(t, err)
+
+			token, err := pasetoEncrypt(key, nonce, []byte(tt.payload))
+			require.NoError(t, err)
+			require.Equal(t, tt.token, token)
+
+			message, err := pasetoDecrypt(key, tt.token)
+			require.NoError(t, err)
+			require.Equal(t, tt.payload, string(message))
+		})
+	}
+}
+
+func Test_preAuthEncode(t *testing.T) {
+	//Examples of the PAE section of the PASETO specification
+	require.Equal(t, "0000000000000000", hex.EncodeToString(preAuthEncode()))
+	require.Equal(t, "01000000000000000000000000000000", hex.EncodeToString(preAuthEncode([]byte{})))
+	require.Equal(t, "020000000000000000000000000000000000000000000000", hex.EncodeToString(preAuthEncode([]byte{}, []byte{})))
+	require.Equal(t, "0100000000000000070000000000000050617261676f6e", hex.EncodeToString(preAuthEncode([]byte("Paragon"))))
+}
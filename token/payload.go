@@ -0,0 +1,85 @@
+package token
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/google/uuid"
+	"time"
+)
+
+//TokenType tells access tokens, sent on every authenticated request, from refresh tokens, only used to renew access tokens
+type TokenType string
+
+const (
+	TokenTypeAccess  TokenType = "access"
+	TokenTypeRefresh TokenType = "refresh"
+)
+
+var (
+	//ErrInvalidToken is returned when the token can't be decoded, was tampered with or has invalid claims
+	ErrInvalidToken = errors.New("token is invalid")
+	//ErrExpiredToken is returned when the token is past its expiration time
+	ErrExpiredToken = errors.New("token has expired")
+	//ErrWrongTokenType is returned when a token is used for another purpose than the one it was issued for
+	ErrWrongTokenType = errors.New("token has the wrong type")
+)
+
+//Payload contains the data carried by a token
+type Payload struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	Type      TokenType `json:"type"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+//NewPayload creates a new token payload of a specific type for a username and duration
+func NewPayload(username string, tokenType TokenType, duration time.Duration) (*Payload, error) {
+	tokenID, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &Payload{
+		ID:        tokenID,
+		Username:  username,
+		Type:      tokenType,
+		IssuedAt:  now,
+		ExpiredAt: now.Add(duration),
+	}, nil
+}
+
+//Valid checks if the payload claims are valid
+func (p *Payload) Valid() error {
+	now := time.Now()
+
+	if p.ID == uuid.Nil || p.Username == "" {
+		return ErrInvalidToken
+	}
+
+	if p.IssuedAt.After(now) {
+		return ErrInvalidToken
+	}
+
+	if now.After(p.ExpiredAt) {
+		return ErrExpiredToken
+	}
+
+	return nil
+}
+
+//verifyType checks the token was issued for tokenType, so a refresh token can't be used as an access token
+func (p *Payload) verifyType(tokenType TokenType) error {
+	if p.Type != tokenType {
+		return ErrWrongTokenType
+	}
+	return nil
+}
+
+//Hash returns the hex SHA-256 of a token, it is what is stored in place of tokens that must be checked later
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,78 @@
+package token
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestPayload_Valid(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		payload Payload
+		wantErr error
+	}{
+		{
+			name: "When payload is valid",
+			payload: Payload{
+				ID:        uuid.New(),
+				Username:  "perotto",
+				IssuedAt:  now,
+				ExpiredAt: now.Add(time.Minute),
+			},
+		},
+		{
+			name: "When payload has expired",
+			payload: Payload{
+				ID:        uuid.New(),
+				Username:  "perotto",
+				IssuedAt:  now.Add(-time.Hour),
+				ExpiredAt: now.Add(-time.Minute),
+			},
+			wantErr: ErrExpiredToken,
+		},
+		{
+			name: "When payload was issued in the future",
+			payload: Payload{
+				ID:        uuid.New(),
+				Username:  "perotto",
+				IssuedAt:  now.Add(time.Hour),
+				ExpiredAt: now.Add(2 * time.Hour),
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "When payload has no token ID",
+			payload: Payload{
+				Username:  "perotto",
+				IssuedAt:  now,
+				ExpiredAt: now.Add(time.Minute),
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "When payload has no username",
+			payload: Payload{
+				ID:        uuid.New(),
+				IssuedAt:  now,
+				ExpiredAt: now.Add(time.Minute),
+			},
+			wantErr: ErrInvalidToken,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.payload.Valid()
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
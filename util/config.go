@@ -1,13 +1,48 @@
 package util
 
-import "github.com/spf13/viper"
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"time"
+)
 
 //Config stores all configuration for application.
 //The values are read by Viper from a config file or environment variables.
 type Config struct {
-	DBDriver      string `mapstructure:"DB_DRIVER"`
-	DBSource      string `mapstructure:"DB_SOURCE"`
-	ServerAddress string `mapstructure:"SERVER_ADDRESS"`
+	DBDriver             string        `mapstructure:"DB_DRIVER"`
+	DBSource             string        `mapstructure:"DB_SOURCE"`
+	ServerAddress        string        `mapstructure:"SERVER_ADDRESS"`
+	GRPCServerAddress    string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	IdempotencyKeyTTL    time.Duration `mapstructure:"IDEMPOTENCY_KEY_TTL"`
+	CursorSigningKey     string        `mapstructure:"CURSOR_SIGNING_KEY"`
+	FXRatesFile          string        `mapstructure:"FX_RATES_FILE"`
+	FXSpreadBps          int64         `mapstructure:"FX_SPREAD_BPS"`
+	HoldTTL              time.Duration `mapstructure:"HOLD_TTL"`
+	HoldExpiryInterval   time.Duration `mapstructure:"HOLD_EXPIRY_INTERVAL"`
+
+	IdempotencyKeySweepInterval time.Duration `mapstructure:"IDEMPOTENCY_KEY_SWEEP_INTERVAL"`
+
+	ScheduledTransferInterval    time.Duration `mapstructure:"SCHEDULED_TRANSFER_INTERVAL"`
+	ScheduledTransferMaxAttempts int32         `mapstructure:"SCHEDULED_TRANSFER_MAX_ATTEMPTS"`
+	ScheduledTransferRetryDelay  time.Duration `mapstructure:"SCHEDULED_TRANSFER_RETRY_DELAY"`
+
+	OverdraftInterestRateBps int64 `mapstructure:"OVERDRAFT_INTEREST_RATE_BPS"`
+
+	ReconciliationInterval time.Duration `mapstructure:"RECONCILIATION_INTERVAL"`
+
+	TransferBatchInterval time.Duration `mapstructure:"TRANSFER_BATCH_INTERVAL"`
+
+	OutboxRelayInterval time.Duration `mapstructure:"OUTBOX_RELAY_INTERVAL"`
+	OutboxMaxAttempts   int32         `mapstructure:"OUTBOX_MAX_ATTEMPTS"`
+	OutboxFile          string        `mapstructure:"OUTBOX_FILE"`
+
+	WebhookDispatchInterval     time.Duration `mapstructure:"WEBHOOK_DISPATCH_INTERVAL"`
+	WebhookMaxAttempts          int32         `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookDisableAfterFailures int32         `mapstructure:"WEBHOOK_DISABLE_AFTER_FAILURES"`
+	WebhookTimeout              time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`
 }
 
 //LoadConfig reads configuration from file or environment variables.
@@ -22,6 +57,52 @@ func LoadConfig(path string) (config Config, err error) {
 		return Config{}, err
 	}
 
-	err = viper.Unmarshal(&config)
-	return
+	if err := viper.Unmarshal(&config); err != nil {
+		return Config{}, err
+	}
+
+	if err := config.validate(); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+//validate checks the durations and attempt counts of the config. A ticker can't run with a missing or zero interval,
+//a missing TTL would expire what it keeps right away and a zero count would give up on the first try.
+func (c Config) validate() error {
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"IDEMPOTENCY_KEY_TTL", c.IdempotencyKeyTTL},
+		{"IDEMPOTENCY_KEY_SWEEP_INTERVAL", c.IdempotencyKeySweepInterval},
+		{"HOLD_EXPIRY_INTERVAL", c.HoldExpiryInterval},
+		{"SCHEDULED_TRANSFER_INTERVAL", c.ScheduledTransferInterval},
+		{"RECONCILIATION_INTERVAL", c.ReconciliationInterval},
+		{"TRANSFER_BATCH_INTERVAL", c.TransferBatchInterval},
+		{"OUTBOX_RELAY_INTERVAL", c.OutboxRelayInterval},
+		{"WEBHOOK_DISPATCH_INTERVAL", c.WebhookDispatchInterval},
+	}
+
+	for _, duration := range durations {
+		if duration.value <= 0 {
+			return fmt.Errorf("%s must be a positive duration, got %s", duration.name, duration.value)
+		}
+	}
+
+	counts := []struct {
+		name  string
+		value int32
+	}{
+		{"OUTBOX_MAX_ATTEMPTS", c.OutboxMaxAttempts},
+		{"WEBHOOK_MAX_ATTEMPTS", c.WebhookMaxAttempts},
+		{"WEBHOOK_DISABLE_AFTER_FAILURES", c.WebhookDisableAfterFailures},
+	}
+
+	for _, count := range counts {
+		if count.value <= 0 {
+			return fmt.Errorf("%s must be positive, got %d", count.name, count.value)
+		}
+	}
+	return nil
 }
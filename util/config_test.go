@@ -0,0 +1,48 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestConfig_validate(t *testing.T) {
+	valid := Config{
+		IdempotencyKeyTTL:           24 * time.Hour,
+		IdempotencyKeySweepInterval: time.Hour,
+		HoldExpiryInterval:          time.Minute,
+		ScheduledTransferInterval:   time.Minute,
+		ReconciliationInterval:      time.Hour,
+		TransferBatchInterval:       10 * time.Second,
+		OutboxRelayInterval:         time.Second,
+		OutboxMaxAttempts:           20,
+		WebhookDispatchInterval:     5 * time.Second,
+		WebhookMaxAttempts:          8,
+		WebhookDisableAfterFailures: 20,
+	}
+	require.NoError(t, valid.validate())
+
+	missing := valid
+	missing.OutboxRelayInterval = 0
+	require.EqualError(t, missing.validate(), "OUTBOX_RELAY_INTERVAL must be a positive duration, got 0s")
+
+	negative := valid
+	negative.HoldExpiryInterval = -time.Minute
+	require.EqualError(t, negative.validate(), "HOLD_EXPIRY_INTERVAL must be a positive duration, got -1m0s")
+
+	noTTL := valid
+	noTTL.IdempotencyKeyTTL = 0
+	require.EqualError(t, noTTL.validate(), "IDEMPOTENCY_KEY_TTL must be a positive duration, got 0s")
+
+	noOutboxAttempts := valid
+	noOutboxAttempts.OutboxMaxAttempts = 0
+	require.EqualError(t, noOutboxAttempts.validate(), "OUTBOX_MAX_ATTEMPTS must be positive, got 0")
+
+	noWebhookAttempts := valid
+	noWebhookAttempts.WebhookMaxAttempts = 0
+	require.EqualError(t, noWebhookAttempts.validate(), "WEBHOOK_MAX_ATTEMPTS must be positive, got 0")
+
+	disableRightAway := valid
+	disableRightAway.WebhookDisableAfterFailures = -1
+	require.EqualError(t, disableRightAway.validate(), "WEBHOOK_DISABLE_AFTER_FAILURES must be positive, got -1")
+}
@@ -0,0 +1,26 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"testing"
+)
+
+func TestPassword(t *testing.T) {
+	password := RandomString(8)
+
+	hashedPassword, err := HashPassword(password)
+	require.NoError(t, err)
+	require.NotEmpty(t, hashedPassword)
+	require.NotEqual(t, password, hashedPassword)
+
+	require.NoError(t, CheckPassword(password, hashedPassword))
+
+	err = CheckPassword(RandomString(8), hashedPassword)
+	require.EqualError(t, err, bcrypt.ErrMismatchedHashAndPassword.Error())
+
+	//Salt makes every hash unique
+	otherHashedPassword, err := HashPassword(password)
+	require.NoError(t, err)
+	require.NotEqual(t, hashedPassword, otherHashedPassword)
+}
@@ -39,8 +39,13 @@ func RandomMoney() int64 {
 	return RandomInt(0, 1000)
 }
 
-//RandomCurrency generates a random currency code
+//RandomCurrency generates a random currency code among the ones enabled by the currencies migration
 func RandomCurrency() string {
-	currencies := []string{"USD", "EUR", "CAD", "BRL"}
+	currencies := []string{"USD", "EUR"}
 	return currencies[rand.Intn(len(currencies))]
 }
+
+//RandomEmail generates a random email
+func RandomEmail() string {
+	return RandomString(6) + "@email.com"
+}
@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"syscall"
+)
+
+var (
+	//ErrInsecureURL is returned for a receiver URL that isn't https
+	ErrInsecureURL = errors.New("webhook URL must use https")
+	//ErrAddressNotAllowed is returned for a receiver on a private, loopback or link-local address
+	ErrAddressNotAllowed = errors.New("webhook receiver must be on a public address")
+)
+
+//ValidateURL checks that rawURL is an https URL whose host only resolves to public addresses.
+//The check is repeated when dialing, since the host may resolve elsewhere by then.
+func ValidateURL(ctx context.Context, resolver *net.Resolver, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" || u.Hostname() == "" {
+		return ErrInsecureURL
+	}
+
+	addrs, err := resolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return fmt.Errorf("can't resolve %s: %w", u.Hostname(), err)
+	}
+	for _, addr := range addrs {
+		if !publicIP(addr.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrAddressNotAllowed, u.Hostname(), addr.IP)
+		}
+	}
+	return nil
+}
+
+//blockedNets are the non-public ranges the net.IP predicates don't cover
+var blockedNets = mustParseCIDRs(
+	//Carrier-grade NAT, shared between the customers of a provider
+	"100.64.0.0/10",
+	//IETF protocol assignments
+	"192.0.0.0/24",
+	//IPv4-compatible IPv6, the IPv4 address isn't checked otherwise
+	"::/96",
+	//NAT64, a gateway would forward to the embedded IPv4 address
+	"64:ff9b::/96",
+)
+
+//publicIP reports whether ip is a unicast address outside the private, loopback, link-local and shared ranges.
+//IPv4-mapped IPv6 addresses are checked as the IPv4 address they map to.
+func publicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	if ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, blocked := range blockedNets {
+		if blocked.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+//mustParseCIDRs parses the CIDR notations, it panics on an invalid one
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets[i] = ipNet
+	}
+	return nets
+}
+
+//dialControl refuses connections to addresses allow rejects, it runs once the host was resolved
+//so a receiver can't pass ValidateURL and then resolve to an internal address
+func dialControl(allow func(net.IP) bool) func(network, address string, _ syscall.RawConn) error {
+	return func(network, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		if ip := net.ParseIP(host); ip == nil || !allow(ip) {
+			return fmt.Errorf("%w: %s", ErrAddressNotAllowed, host)
+		}
+		return nil
+	}
+}
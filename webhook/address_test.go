@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"net"
+	"testing"
+)
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr error
+	}{
+		{name: "PublicAddress", url: "https://203.0.113.10/simplebank"},
+		{name: "PlainHTTP", url: "http://203.0.113.10/simplebank", wantErr: ErrInsecureURL},
+		{name: "NoHost", url: "https:///simplebank", wantErr: ErrInsecureURL},
+		{name: "Private", url: "https://10.1.2.3/simplebank", wantErr: ErrAddressNotAllowed},
+		{name: "Loopback", url: "https://127.0.0.1/simplebank", wantErr: ErrAddressNotAllowed},
+		{name: "LoopbackIPv6", url: "https://[::1]/simplebank", wantErr: ErrAddressNotAllowed},
+		{name: "LinkLocal", url: "https://169.254.169.254/latest/meta-data", wantErr: ErrAddressNotAllowed},
+		{name: "Unspecified", url: "https://0.0.0.0/simplebank", wantErr: ErrAddressNotAllowed},
+		{name: "CarrierGradeNAT", url: "https://100.64.0.1/simplebank", wantErr: ErrAddressNotAllowed},
+		{name: "CarrierGradeNATUpperBound", url: "https://100.127.255.254/simplebank", wantErr: ErrAddressNotAllowed},
+		{name: "BelowCarrierGradeNAT", url: "https://100.63.255.254/simplebank"},
+		{name: "ProtocolAssignments", url: "https://192.0.0.8/simplebank", wantErr: ErrAddressNotAllowed},
+		{name: "IPv4MappedLoopback", url: "https://[::ffff:127.0.0.1]/simplebank", wantErr: ErrAddressNotAllowed},
+		{name: "IPv4MappedPrivate", url: "https://[::ffff:10.1.2.3]/simplebank", wantErr: ErrAddressNotAllowed},
+		{name: "IPv4MappedCarrierGradeNAT", url: "https://[::ffff:100.64.0.1]/simplebank", wantErr: ErrAddressNotAllowed},
+		{name: "IPv4MappedPublic", url: "https://[::ffff:203.0.113.10]/simplebank"},
+		{name: "IPv4CompatibleLoopback", url: "https://[::127.0.0.1]/simplebank", wantErr: ErrAddressNotAllowed},
+		{name: "NAT64", url: "https://[64:ff9b::a01:203]/simplebank", wantErr: ErrAddressNotAllowed},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateURL(context.Background(), net.DefaultResolver, tt.url)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	db "simplebank/db/sqlc"
+	"simplebank/outbox"
+)
+
+//Publisher is an outbox.Publisher that queues a delivery of every relayed event for each active subscription of
+//the users concerned by it, deliveries are then posted by the webhook dispatcher
+type Publisher struct {
+	store db.Store
+}
+
+//eventOwners holds the fields naming the users concerned by an event
+type eventOwners struct {
+	Owner     string `json:"owner"`
+	FromOwner string `json:"from_owner"`
+	ToOwner   string `json:"to_owner"`
+}
+
+//NewPublisher builds a Publisher
+func NewPublisher(store db.Store) *Publisher {
+	return &Publisher{
+		store: store,
+	}
+}
+
+//Publish queues the deliveries of the event. An event relayed again doesn't queue a second delivery to a subscription.
+func (p *Publisher) Publish(ctx context.Context, event outbox.Event) error {
+	owners, err := Owners(event.Payload)
+	if err != nil {
+		return err
+	}
+	if len(owners) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(Message{
+		ID:        event.ID,
+		Type:      event.Type,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = p.store.EnqueueWebhookDeliveries(ctx, db.EnqueueWebhookDeliveriesParams{
+		EventID:   event.ID,
+		EventType: event.Type,
+		Payload:   payload,
+		Owners:    owners,
+	})
+	return err
+}
+
+//Owners returns the users concerned by an event payload, both sides of a transfer are concerned by it
+func Owners(payload json.RawMessage) ([]string, error) {
+	var fields eventOwners
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, err
+	}
+
+	var owners []string
+	for _, owner := range []string{fields.Owner, fields.FromOwner, fields.ToOwner} {
+		if owner != "" && !contains(owners, owner) {
+			owners = append(owners, owner)
+		}
+	}
+	return owners, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
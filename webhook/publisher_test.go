@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "simplebank/db/sqlc"
+	mockdb "simplebank/db/sqlc/mock"
+	"simplebank/outbox"
+	"testing"
+	"time"
+)
+
+func TestOwners(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    []string
+	}{
+		{name: "When the event concerns one owner", payload: `{"account_id":1,"owner":"perotto"}`, want: []string{"perotto"}},
+		{name: "When the event concerns both sides of a transfer", payload: `{"from_owner":"perotto","to_owner":"emmanuel"}`, want: []string{"perotto", "emmanuel"}},
+		{name: "When the owner pays themselves", payload: `{"from_owner":"perotto","to_owner":"perotto"}`, want: []string{"perotto"}},
+		{name: "When the event concerns no owner", payload: `{"account_id":1}`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			owners, err := Owners(json.RawMessage(tt.payload))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, owners)
+		})
+	}
+}
+
+func TestPublisher_Publish(t *testing.T) {
+	event := outbox.Event{
+		ID:            40,
+		AggregateType: db.OutboxAggregateTransfer,
+		AggregateID:   9,
+		Type:          db.EventTransferCompleted,
+		Payload:       json.RawMessage(`{"transfer_id":9,"from_owner":"perotto","to_owner":"emmanuel"}`),
+		CreatedAt:     time.Date(2022, time.May, 1, 9, 0, 0, 0, time.UTC),
+	}
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().EnqueueWebhookDeliveries(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, params db.EnqueueWebhookDeliveriesParams) (int64, error) {
+			assert.Equal(t, event.ID, params.EventID)
+			assert.Equal(t, event.Type, params.EventType)
+			assert.Equal(t, []string{"perotto", "emmanuel"}, params.Owners)
+
+			var msg Message
+			require.NoError(t, json.Unmarshal(params.Payload, &msg))
+			assert.Equal(t, event.ID, msg.ID)
+			assert.Equal(t, event.Type, msg.Type)
+			assert.True(t, event.CreatedAt.Equal(msg.CreatedAt))
+			assert.JSONEq(t, string(event.Payload), string(msg.Data))
+			return 2, nil
+		})
+
+	require.NoError(t, NewPublisher(store).Publish(context.Background(), event))
+}
@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	//EventHeader carries the type of the delivered event
+	EventHeader = "Simplebank-Event"
+	//DeliveryHeader carries the delivery id, it is the same on every attempt of a delivery
+	DeliveryHeader = "Simplebank-Delivery"
+)
+
+type (
+	//Message is the JSON body posted to subscribers, ID is the outbox event id receivers dedupe on
+	Message struct {
+		ID        int64           `json:"id"`
+		Type      string          `json:"type"`
+		CreatedAt time.Time       `json:"created_at"`
+		Data      json.RawMessage `json:"data"`
+	}
+	//Delivery is a message to post to a subscriber, ID is the same on every attempt
+	Delivery struct {
+		ID        int64
+		URL       string
+		Secret    string
+		EventType string
+		Body      []byte
+	}
+	//Sender posts signed messages to subscriber URLs
+	Sender struct {
+		client *http.Client
+		now    func() time.Time
+	}
+	//Response is what the receiver answered, StatusCode is zero when no response was received
+	Response struct {
+		StatusCode int
+		Duration   time.Duration
+	}
+)
+
+//NewSender builds a Sender giving up on a receiver after timeout.
+//Redirects are not followed, a receiver must answer on the subscribed URL, and only public addresses are dialed.
+func NewSender(timeout time.Duration) *Sender {
+	return newSender(timeout, publicIP)
+}
+
+//newSender builds a Sender dialing only the addresses allow accepts
+func newSender(timeout time.Duration, allow func(net.IP) bool) *Sender {
+	dialer := &net.Dialer{Control: dialControl(allow)}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	//Through a proxy the receiver address would never reach the dialer check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &Sender{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		now: time.Now,
+	}
+}
+
+//Send posts the delivery signed with its secret, it succeeds when the receiver answers with a 2xx status
+func (s *Sender) Send(ctx context.Context, delivery Delivery) (Response, error) {
+	var rsp Response
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Body))
+	if err != nil {
+		return rsp, err
+	}
+
+	start := s.now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "simplebank-webhooks")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, start, delivery.Body))
+
+	httpRsp, err := s.client.Do(req)
+	rsp.Duration = s.now().Sub(start)
+	if err != nil {
+		return rsp, err
+	}
+	defer httpRsp.Body.Close()
+
+	rsp.StatusCode = httpRsp.StatusCode
+	if httpRsp.StatusCode >= 200 && httpRsp.StatusCode < 300 {
+		return rsp, nil
+	}
+
+	//The body is left out, the error is shown to the subscriber and must not echo what an internal service answered
+	return rsp, fmt.Errorf("receiver answered %s", httpRsp.Status)
+}
@@ -0,0 +1,106 @@
+package webhook
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSender_Send(t *testing.T) {
+	body := []byte(`{"id":40,"type":"TransferCompleted","created_at":"2022-05-01T09:00:00Z","data":{"transfer_id":9}}`)
+
+	tests := []struct {
+		name           string
+		handler        http.HandlerFunc
+		wantStatusCode int
+		wantErr        bool
+	}{
+		{
+			name: "When the receiver verifies and acknowledges the delivery",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				received, _ := io.ReadAll(r.Body)
+				if err := Verify("secret", r.Header.Get(SignatureHeader), received, time.Minute, time.Now()); err != nil {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				if r.Header.Get(EventHeader) != "TransferCompleted" || r.Header.Get(DeliveryHeader) != "11" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			},
+			wantStatusCode: http.StatusNoContent,
+		},
+		{
+			name: "When the receiver fails",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "database is down", http.StatusServiceUnavailable)
+			},
+			wantStatusCode: http.StatusServiceUnavailable,
+			wantErr:        true,
+		},
+		{
+			name: "When the receiver redirects",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "/elsewhere", http.StatusFound)
+			},
+			wantStatusCode: http.StatusFound,
+			wantErr:        true,
+		},
+		{
+			name: "When the receiver answers too late",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			receiver := httptest.NewServer(tt.handler)
+			defer receiver.Close()
+
+			rsp, err := newSender(100*time.Millisecond, net.IP.IsLoopback).Send(context.Background(), Delivery{
+				ID:        11,
+				URL:       receiver.URL,
+				Secret:    "secret",
+				EventType: "TransferCompleted",
+				Body:      body,
+			})
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.NotContains(t, err.Error(), "database is down")
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantStatusCode, rsp.StatusCode)
+		})
+	}
+}
+
+func TestSender_SendToLoopback(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	//The receiver address is checked when dialing, whatever the URL host resolved to
+	rsp, err := NewSender(time.Second).Send(context.Background(), Delivery{
+		ID:        11,
+		URL:       receiver.URL,
+		Secret:    "secret",
+		EventType: "TransferCompleted",
+		Body:      []byte(`{}`),
+	})
+	require.ErrorIs(t, err, ErrAddressNotAllowed)
+	assert.Zero(t, rsp.StatusCode)
+}
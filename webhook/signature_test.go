@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	sentAt := time.Unix(1651395600, 0)
+
+	header := Sign("secret", sentAt, []byte(`{"id":1}`))
+	assert.Equal(t, "t=1651395600,v1=", header[:16])
+	assert.Len(t, strings.TrimPrefix(header, "t=1651395600,v1="), 64)
+
+	//The signature changes with the timestamp, so it can't be replayed at another time
+	assert.NotEqual(t, header, Sign("secret", sentAt.Add(time.Second), []byte(`{"id":1}`)))
+}
+
+func TestVerify(t *testing.T) {
+	sentAt := time.Unix(1651395600, 0)
+	body := []byte(`{"id":1}`)
+	header := Sign("secret", sentAt, body)
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  string
+		body    []byte
+		now     time.Time
+		wantErr bool
+	}{
+		{name: "When the signature matches", secret: "secret", header: header, body: body, now: sentAt.Add(time.Minute)},
+		{name: "When one of several signatures matches", secret: "secret", header: header + ",v1=00ff", body: body, now: sentAt},
+		{name: "When the body was changed", secret: "secret", header: header, body: []byte(`{"id":2}`), now: sentAt, wantErr: true},
+		{name: "When the secret is another", secret: "other", header: header, body: body, now: sentAt, wantErr: true},
+		{name: "When the signature is too old", secret: "secret", header: header, body: body, now: sentAt.Add(10 * time.Minute), wantErr: true},
+		{name: "When the header has no timestamp", secret: "secret", header: strings.Split(header, ",")[1], body: body, now: sentAt, wantErr: true},
+		{name: "When the header is malformed", secret: "secret", header: "garbage", body: body, now: sentAt, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := Verify(tt.secret, tt.header, tt.body, 5*time.Minute, tt.now)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidSignature)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	first, err := NewSecret()
+	require.NoError(t, err)
+	second, err := NewSecret()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(first, "whsec_"))
+	assert.Len(t, first, len("whsec_")+2*secretSize)
+	assert.NotEqual(t, first, second)
+}
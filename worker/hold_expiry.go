@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"context"
+	"log"
+	db "simplebank/db/sqlc"
+	"time"
+)
+
+//holdExpiryBatchSize bounds the accounts swept per batch so a backlog doesn't hold connections for long
+const holdExpiryBatchSize = 100
+
+//HoldExpirer releases the holds past their expiry on accounts nobody touched since.
+//Holds are also expired whenever their account is used, so the sweep only catches up on idle accounts.
+type HoldExpirer struct {
+	store db.Store
+}
+
+//NewHoldExpirer builds a HoldExpirer
+func NewHoldExpirer(store db.Store) *HoldExpirer {
+	return &HoldExpirer{
+		store: store,
+	}
+}
+
+//Run releases the expired holds every interval until ctx is done
+func (e *HoldExpirer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				swept, err := e.RunOnce(ctx)
+				if err != nil {
+					log.Print("Can't expire holds: ", err)
+					break
+				}
+				if swept < holdExpiryBatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+//RunOnce releases the expired holds of a batch of accounts and returns how many accounts were swept
+func (e *HoldExpirer) RunOnce(ctx context.Context) (int, error) {
+	return e.store.ExpireHolds(ctx, holdExpiryBatchSize)
+}
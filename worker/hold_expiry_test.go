@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	mockdb "simplebank/db/sqlc/mock"
+	"testing"
+)
+
+func TestHoldExpirer_RunOnce(t *testing.T) {
+	tests := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		runAssertions func(t *testing.T, swept int, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ExpireHolds(gomock.Any(), int32(holdExpiryBatchSize)).
+					Times(1).
+					Return(3, nil)
+			},
+			runAssertions: func(t *testing.T, swept int, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, 3, swept)
+			},
+		},
+		{
+			name: "StoreError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ExpireHolds(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(0, errors.New("connection reset"))
+			},
+			runAssertions: func(t *testing.T, swept int, err error) {
+				require.EqualError(t, err, "connection reset")
+				assert.Zero(t, swept)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tt.buildStubs(store)
+
+			swept, err := NewHoldExpirer(store).RunOnce(context.Background())
+			tt.runAssertions(t, swept, err)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"context"
+	"log"
+	db "simplebank/db/sqlc"
+	"time"
+)
+
+//idempotencyKeySweepBatchSize bounds the expired idempotency keys deleted per statement
+const idempotencyKeySweepBatchSize = 1000
+
+//IdempotencyKeySweeper deletes the idempotency keys past their expiry,
+//a key is otherwise only deleted when a request reuses it
+type IdempotencyKeySweeper struct {
+	store db.Store
+}
+
+//NewIdempotencyKeySweeper builds an IdempotencyKeySweeper
+func NewIdempotencyKeySweeper(store db.Store) *IdempotencyKeySweeper {
+	return &IdempotencyKeySweeper{
+		store: store,
+	}
+}
+
+//Run deletes the expired idempotency keys every interval until ctx is done
+func (s *IdempotencyKeySweeper) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				deleted, err := s.RunOnce(ctx)
+				if err != nil {
+					log.Print("Can't delete expired idempotency keys: ", err)
+					break
+				}
+				if deleted < idempotencyKeySweepBatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+//RunOnce deletes a batch of expired idempotency keys and returns how many were deleted
+func (s *IdempotencyKeySweeper) RunOnce(ctx context.Context) (int64, error) {
+	return s.store.DeleteExpiredIdempotencyKeys(ctx, idempotencyKeySweepBatchSize)
+}
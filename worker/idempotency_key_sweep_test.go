@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	mockdb "simplebank/db/sqlc/mock"
+	"testing"
+)
+
+func TestIdempotencyKeySweeper_RunOnce(t *testing.T) {
+	tests := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		runAssertions func(t *testing.T, deleted int64, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeleteExpiredIdempotencyKeys(gomock.Any(), int32(idempotencyKeySweepBatchSize)).
+					Times(1).
+					Return(int64(42), nil)
+			},
+			runAssertions: func(t *testing.T, deleted int64, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, int64(42), deleted)
+			},
+		},
+		{
+			name: "StoreError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeleteExpiredIdempotencyKeys(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), errors.New("connection reset"))
+			},
+			runAssertions: func(t *testing.T, deleted int64, err error) {
+				require.EqualError(t, err, "connection reset")
+				assert.Zero(t, deleted)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tt.buildStubs(store)
+
+			deleted, err := NewIdempotencyKeySweeper(store).RunOnce(context.Background())
+			tt.runAssertions(t, deleted, err)
+		})
+	}
+}
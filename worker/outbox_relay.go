@@ -0,0 +1,139 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	db "simplebank/db/sqlc"
+	"simplebank/outbox"
+	"simplebank/util"
+	"sort"
+	"time"
+)
+
+const (
+	//outboxClaimSize bounds the events published per claim
+	outboxClaimSize = 100
+	//outboxBaseDelay is the delay before retrying an event that failed once, it doubles with every failure
+	outboxBaseDelay = time.Second
+	//outboxMaxDelay caps the delay between two attempts to publish an event
+	outboxMaxDelay = time.Hour
+)
+
+//OutboxRelay publishes the events written to the outbox.
+//Delivery is at least once: an event is marked published only once the publisher returned, and an event
+//claimed by a relay that stopped is claimed again once its lease expires. The events of an aggregate are
+//published in the order they were written, a failing event holds back the later ones of its aggregate
+//until it is published or marked dead after maxAttempts.
+type OutboxRelay struct {
+	store       db.Store
+	publisher   outbox.Publisher
+	maxAttempts int32
+	now         func() time.Time
+}
+
+//NewOutboxRelay builds an OutboxRelay
+func NewOutboxRelay(store db.Store, publisher outbox.Publisher, config util.Config) *OutboxRelay {
+	return &OutboxRelay{
+		store:       store,
+		publisher:   publisher,
+		maxAttempts: config.OutboxMaxAttempts,
+		now:         time.Now,
+	}
+}
+
+//Run publishes the pending events every interval until ctx is done
+func (r *OutboxRelay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			//Only the oldest pending event of each aggregate can be claimed,
+			//so claims go on while there are events to publish
+			for {
+				claimed, err := r.RunOnce(ctx)
+				if err != nil {
+					log.Print("Can't relay outbox events: ", err)
+					break
+				}
+				if claimed == 0 {
+					break
+				}
+			}
+		}
+	}
+}
+
+//RunOnce claims pending events, publishes them and returns how many were claimed
+func (r *OutboxRelay) RunOnce(ctx context.Context) (int, error) {
+	claimed, err := r.store.ClaimOutboxEvents(ctx, db.ClaimOutboxEventsParams{
+		ClaimedUntil: sql.NullTime{Time: r.now().Add(claimLease), Valid: true},
+		Limit:        outboxClaimSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	sort.Slice(claimed, func(i, j int) bool { return claimed[i].ID < claimed[j].ID })
+
+	for _, event := range claimed {
+		if err := r.publish(ctx, event); err != nil {
+			//The claim expires with the lease, so the event is picked up again
+			log.Printf("Can't record outbox event %d: %v", event.ID, err)
+		}
+	}
+
+	return len(claimed), nil
+}
+
+//publish hands the event to the publisher and records the outcome
+func (r *OutboxRelay) publish(ctx context.Context, event db.OutboxEvent) error {
+	publishErr := r.publisher.Publish(ctx, outbox.Event{
+		ID:            event.ID,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		Type:          event.EventType,
+		Payload:       event.Payload,
+		CreatedAt:     event.CreatedAt,
+	})
+	if publishErr == nil {
+		_, err := r.store.MarkOutboxEventPublished(ctx, event.ID)
+		return err
+	}
+
+	attempt := event.Attempts + 1
+	if attempt >= r.maxAttempts {
+		//A dead event no longer holds back the later events of its aggregate
+		log.Printf("Outbox event %d gave up after %d attempts: %v", event.ID, attempt, publishErr)
+		_, err := r.store.MarkOutboxEventDead(ctx, db.MarkOutboxEventDeadParams{
+			ID:        event.ID,
+			LastError: publishErr.Error(),
+		})
+		return err
+	}
+
+	log.Printf("Can't publish outbox event %d, attempt %d: %v", event.ID, attempt, publishErr)
+	_, err := r.store.RecordOutboxEventFailure(ctx, db.RecordOutboxEventFailureParams{
+		ID:            event.ID,
+		NextAttemptAt: r.now().Add(backoff(outboxBaseDelay, outboxMaxDelay, event.Attempts)),
+		LastError:     publishErr.Error(),
+	})
+	return err
+}
+
+//backoff is the delay before the next attempt of something that already failed attempts times,
+//it doubles from base with every failure up to max
+func backoff(base, max time.Duration, attempts int32) time.Duration {
+	delay := base
+	for i := int32(0); i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
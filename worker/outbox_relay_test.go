@@ -0,0 +1,173 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "simplebank/db/sqlc"
+	mockdb "simplebank/db/sqlc/mock"
+	"simplebank/outbox"
+	"testing"
+	"time"
+)
+
+//failingPublisher refuses every event
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(context.Context, outbox.Event) error {
+	return errors.New("broker unavailable")
+}
+
+func TestOutboxRelay_RunOnce(t *testing.T) {
+	now := time.Date(2022, 5, 1, 9, 0, 0, 0, time.UTC)
+	created := db.OutboxEvent{
+		ID:            4,
+		AggregateType: db.OutboxAggregateAccount,
+		AggregateID:   1,
+		EventType:     db.EventAccountCreated,
+		Payload:       json.RawMessage(`{"account_id":1}`),
+		CreatedAt:     now,
+	}
+	completed := db.OutboxEvent{
+		ID:            7,
+		AggregateType: db.OutboxAggregateTransfer,
+		AggregateID:   9,
+		EventType:     db.EventTransferCompleted,
+		Payload:       json.RawMessage(`{"transfer_id":9}`),
+		Attempts:      2,
+		CreatedAt:     now,
+	}
+
+	tests := []struct {
+		name          string
+		publisher     func() outbox.Publisher
+		buildStubs    func(store *mockdb.MockStore)
+		runAssertions func(t *testing.T, publisher outbox.Publisher, claimed int, err error)
+	}{
+		{
+			name:      "PublishesInWriteOrder",
+			publisher: func() outbox.Publisher { return outbox.NewMemoryPublisher() },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ClaimOutboxEvents(gomock.Any(), db.ClaimOutboxEventsParams{
+					ClaimedUntil: sql.NullTime{Time: now.Add(claimLease), Valid: true},
+					Limit:        outboxClaimSize,
+				}).
+					Times(1).
+					Return([]db.OutboxEvent{completed, created}, nil)
+				store.EXPECT().MarkOutboxEventPublished(gomock.Any(), created.ID).Times(1)
+				store.EXPECT().MarkOutboxEventPublished(gomock.Any(), completed.ID).Times(1)
+				store.EXPECT().RecordOutboxEventFailure(gomock.Any(), gomock.Any()).Times(0)
+			},
+			runAssertions: func(t *testing.T, publisher outbox.Publisher, claimed int, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, 2, claimed)
+
+				events := publisher.(*outbox.MemoryPublisher).Events()
+				require.Len(t, events, 2)
+				assert.Equal(t, outbox.Event{
+					ID:            created.ID,
+					AggregateType: created.AggregateType,
+					AggregateID:   created.AggregateID,
+					Type:          created.EventType,
+					Payload:       created.Payload,
+					CreatedAt:     created.CreatedAt,
+				}, events[0])
+				assert.Equal(t, completed.ID, events[1].ID)
+			},
+		},
+		{
+			name:      "PublishErrorBacksOff",
+			publisher: func() outbox.Publisher { return failingPublisher{} },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ClaimOutboxEvents(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.OutboxEvent{completed}, nil)
+				store.EXPECT().MarkOutboxEventPublished(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().MarkOutboxEventDead(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().RecordOutboxEventFailure(gomock.Any(), db.RecordOutboxEventFailureParams{
+					ID:            completed.ID,
+					NextAttemptAt: now.Add(4 * time.Second),
+					LastError:     "broker unavailable",
+				}).
+					Times(1)
+			},
+			runAssertions: func(t *testing.T, publisher outbox.Publisher, claimed int, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, 1, claimed)
+			},
+		},
+		{
+			name:      "LastAttemptMarksDead",
+			publisher: func() outbox.Publisher { return failingPublisher{} },
+			buildStubs: func(store *mockdb.MockStore) {
+				exhausted := completed
+				exhausted.Attempts = 4
+
+				store.EXPECT().ClaimOutboxEvents(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.OutboxEvent{exhausted}, nil)
+				store.EXPECT().RecordOutboxEventFailure(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().MarkOutboxEventDead(gomock.Any(), db.MarkOutboxEventDeadParams{
+					ID:        completed.ID,
+					LastError: "broker unavailable",
+				}).
+					Times(1)
+			},
+			runAssertions: func(t *testing.T, publisher outbox.Publisher, claimed int, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, 1, claimed)
+			},
+		},
+		{
+			name:      "ClaimError",
+			publisher: func() outbox.Publisher { return outbox.NewMemoryPublisher() },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ClaimOutboxEvents(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			runAssertions: func(t *testing.T, publisher outbox.Publisher, claimed int, err error) {
+				assert.True(t, errors.Is(err, sql.ErrConnDone))
+				assert.Empty(t, publisher.(*outbox.MemoryPublisher).Events())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tt.buildStubs(store)
+
+			publisher := tt.publisher()
+			relay := &OutboxRelay{store: store, publisher: publisher, maxAttempts: 5, now: func() time.Time { return now }}
+
+			claimed, err := relay.RunOnce(context.Background())
+			tt.runAssertions(t, publisher, claimed, err)
+		})
+	}
+}
+
+func Test_backoff(t *testing.T) {
+	tests := []struct {
+		attempts int32
+		want     time.Duration
+	}{
+		{attempts: 0, want: time.Second},
+		{attempts: 1, want: 2 * time.Second},
+		{attempts: 5, want: 32 * time.Second},
+		{attempts: 12, want: time.Hour},
+		{attempts: 1000, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, backoff(outboxBaseDelay, outboxMaxDelay, tt.attempts), "attempts %d", tt.attempts)
+	}
+}
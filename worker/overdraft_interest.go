@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	db "simplebank/db/sqlc"
+	"simplebank/util"
+	"time"
+)
+
+//overdraftBatchSize bounds the account ids listed per query while charging interest
+const overdraftBatchSize = 100
+
+//OverdraftInterestCharger charges every night the interest owed by accounts that ended the previous day below zero
+type OverdraftInterestCharger struct {
+	store   db.Store
+	rateBps int64
+	now     func() time.Time
+}
+
+//NewOverdraftInterestCharger builds an OverdraftInterestCharger
+func NewOverdraftInterestCharger(store db.Store, config util.Config) *OverdraftInterestCharger {
+	return &OverdraftInterestCharger{
+		store:   store,
+		rateBps: config.OverdraftInterestRateBps,
+		now:     time.Now,
+	}
+}
+
+//Run charges the interest of the day that just ended at every UTC midnight until ctx is done.
+//Days missed while the process was down are not charged.
+func (c *OverdraftInterestCharger) Run(ctx context.Context) {
+	for {
+		now := c.now().UTC()
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+
+		timer := time.NewTimer(midnight.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		charged, err := c.RunOnce(ctx, midnight.AddDate(0, 0, -1))
+		if err != nil {
+			log.Print("Can't charge overdraft interest: ", err)
+		}
+		log.Printf("Charged overdraft interest to %d accounts", charged)
+	}
+}
+
+//RunOnce charges the overdraft interest of day and returns how many accounts were charged.
+//Accounts already charged for the day are skipped, so it is safe to run it again.
+func (c *OverdraftInterestCharger) RunOnce(ctx context.Context, day time.Time) (int, error) {
+	charged := 0
+	var afterID int64
+
+	for {
+		ids, err := c.store.ListOverdraftAccountIDs(ctx, db.ListOverdraftAccountIDsParams{
+			AfterID: afterID,
+			Limit:   overdraftBatchSize,
+		})
+		if err != nil {
+			return charged, err
+		}
+
+		for _, id := range ids {
+			result, err := c.store.ChargeOverdraftInterest(ctx, db.ChargeOverdraftInterestParams{
+				AccountID: id,
+				Day:       day,
+				RateBps:   c.rateBps,
+			})
+			if err != nil {
+				if !errors.Is(err, db.ErrOverdraftInterestCharged) {
+					log.Printf("Can't charge overdraft interest to account %d: %v", id, err)
+				}
+				continue
+			}
+
+			if result.Charge.Amount > 0 {
+				charged++
+			}
+		}
+
+		if len(ids) < overdraftBatchSize {
+			return charged, nil
+		}
+		afterID = ids[len(ids)-1]
+	}
+}
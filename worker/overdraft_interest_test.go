@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "simplebank/db/sqlc"
+	mockdb "simplebank/db/sqlc/mock"
+	"testing"
+	"time"
+)
+
+func TestOverdraftInterestCharger_RunOnce(t *testing.T) {
+	day := time.Date(2022, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	firstPage := make([]int64, overdraftBatchSize)
+	for i := range firstPage {
+		firstPage[i] = int64(i + 1)
+	}
+
+	store.EXPECT().ListOverdraftAccountIDs(gomock.Any(), db.ListOverdraftAccountIDsParams{AfterID: 0, Limit: overdraftBatchSize}).
+		Times(1).
+		Return(firstPage, nil)
+	store.EXPECT().ListOverdraftAccountIDs(gomock.Any(), db.ListOverdraftAccountIDsParams{AfterID: overdraftBatchSize, Limit: overdraftBatchSize}).
+		Times(1).
+		Return([]int64{500}, nil)
+
+	//Only account 1 owes interest, account 2 was already charged and account 3 fails
+	store.EXPECT().ChargeOverdraftInterest(gomock.Any(), db.ChargeOverdraftInterestParams{AccountID: 1, Day: day, RateBps: 1500}).
+		Times(1).
+		Return(db.ChargeOverdraftInterestResult{Charge: db.OverdraftInterestCharge{AccountID: 1, Amount: 5}}, nil)
+	store.EXPECT().ChargeOverdraftInterest(gomock.Any(), db.ChargeOverdraftInterestParams{AccountID: 2, Day: day, RateBps: 1500}).
+		Times(1).
+		Return(db.ChargeOverdraftInterestResult{}, db.ErrOverdraftInterestCharged)
+	store.EXPECT().ChargeOverdraftInterest(gomock.Any(), db.ChargeOverdraftInterestParams{AccountID: 3, Day: day, RateBps: 1500}).
+		Times(1).
+		Return(db.ChargeOverdraftInterestResult{}, errors.New("connection reset"))
+	store.EXPECT().ChargeOverdraftInterest(gomock.Any(), gomock.Any()).
+		Times(overdraftBatchSize-2).
+		Return(db.ChargeOverdraftInterestResult{}, nil)
+
+	charger := &OverdraftInterestCharger{store: store, rateBps: 1500, now: time.Now}
+
+	charged, err := charger.RunOnce(context.Background(), day)
+	require.NoError(t, err)
+	assert.Equal(t, 1, charged)
+}
@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"log"
+	db "simplebank/db/sqlc"
+	"time"
+)
+
+//Reconciler periodically checks that the ledger balances and logs the drift it finds
+type Reconciler struct {
+	store db.Store
+}
+
+//NewReconciler builds a Reconciler
+func NewReconciler(store db.Store) *Reconciler {
+	return &Reconciler{
+		store: store,
+	}
+}
+
+//Run reconciles the ledger every interval until ctx is done
+func (r *Reconciler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.RunOnce(ctx); err != nil {
+				log.Print("Can't reconcile the ledger: ", err)
+			}
+		}
+	}
+}
+
+//RunOnce reconciles the ledger, stores the report and logs every issue found
+func (r *Reconciler) RunOnce(ctx context.Context) (db.ReconcileResult, error) {
+	result, err := r.store.Reconcile(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	for _, issue := range result.Issues {
+		switch issue.Kind {
+		case db.ReconciliationIssueAccountDrift:
+			log.Printf("Reconciliation run %d: account %d has balance %d but its entries sum to %d",
+				result.Run.ID, issue.AccountID.Int64, issue.Actual, issue.Expected)
+		default:
+			log.Printf("Reconciliation run %d: transfer %d has %d entries, %s",
+				result.Run.ID, issue.TransferID.Int64, issue.Actual, issue.Detail)
+		}
+	}
+	log.Printf("Reconciliation run %d checked %d accounts and %d transfers, found %d issues",
+		result.Run.ID, result.Run.AccountsChecked, result.Run.TransfersChecked, result.Run.IssuesCount)
+
+	return result, nil
+}
@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "simplebank/db/sqlc"
+	mockdb "simplebank/db/sqlc/mock"
+	"testing"
+)
+
+func TestReconciler_RunOnce(t *testing.T) {
+	report := db.ReconcileResult{
+		Run: db.ReconciliationRun{ID: 1, AccountsChecked: 10, TransfersChecked: 20, IssuesCount: 2},
+		Issues: []db.ReconciliationIssue{
+			{
+				ID:        1,
+				RunID:     1,
+				Kind:      db.ReconciliationIssueAccountDrift,
+				AccountID: sql.NullInt64{Int64: 3, Valid: true},
+				Expected:  100,
+				Actual:    150,
+			},
+			{
+				ID:         2,
+				RunID:      1,
+				Kind:       db.ReconciliationIssueTransferEntries,
+				TransferID: sql.NullInt64{Int64: 7, Valid: true},
+				Expected:   2,
+				Actual:     1,
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		runAssertions func(t *testing.T, result db.ReconcileResult, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Reconcile(gomock.Any()).
+					Times(1).
+					Return(report, nil)
+			},
+			runAssertions: func(t *testing.T, result db.ReconcileResult, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, report, result)
+			},
+		},
+		{
+			name: "StoreError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Reconcile(gomock.Any()).
+					Times(1).
+					Return(db.ReconcileResult{}, errors.New("connection reset"))
+			},
+			runAssertions: func(t *testing.T, result db.ReconcileResult, err error) {
+				require.Error(t, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tt.buildStubs(store)
+
+			result, err := NewReconciler(store).RunOnce(context.Background())
+			tt.runAssertions(t, result, err)
+		})
+	}
+}
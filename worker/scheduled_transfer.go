@@ -0,0 +1,185 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	db "simplebank/db/sqlc"
+	"simplebank/schedule"
+	"simplebank/util"
+	"time"
+)
+
+const (
+	//claimLease is how long a claimed scheduled transfer or transfer batch is hidden from other workers
+	claimLease = 5 * time.Minute
+	//claimBatchSize bounds the scheduled transfers executed per claim
+	claimBatchSize = 50
+)
+
+type (
+	//Notifier tells the owner that a scheduled transfer gave up on an occurrence
+	Notifier interface {
+		ScheduledTransferFailed(ctx context.Context, st db.ScheduledTransfer, err error) error
+	}
+	//LogNotifier writes failed scheduled transfers to the standard logger
+	LogNotifier struct{}
+
+	//ScheduledTransferExecutor claims due scheduled transfers and executes them as transfers.
+	//Failed occurrences are retried after RetryDelay until MaxAttempts, then the owner is notified and
+	//the transfer moves on to its next occurrence.
+	ScheduledTransferExecutor struct {
+		store             db.Store
+		notifier          Notifier
+		maxAttempts       int32
+		retryDelay        time.Duration
+		idempotencyKeyTTL time.Duration
+		now               func() time.Time
+	}
+)
+
+//ScheduledTransferFailed logs the failed scheduled transfer
+func (LogNotifier) ScheduledTransferFailed(_ context.Context, st db.ScheduledTransfer, err error) error {
+	log.Printf("Scheduled transfer %d of %s failed for %s: %v", st.ID, st.Owner, st.ScheduledFor.Format(time.RFC3339), err)
+	return nil
+}
+
+//NewScheduledTransferExecutor builds a ScheduledTransferExecutor
+func NewScheduledTransferExecutor(store db.Store, notifier Notifier, config util.Config) *ScheduledTransferExecutor {
+	return &ScheduledTransferExecutor{
+		store:             store,
+		notifier:          notifier,
+		maxAttempts:       config.ScheduledTransferMaxAttempts,
+		retryDelay:        config.ScheduledTransferRetryDelay,
+		idempotencyKeyTTL: config.IdempotencyKeyTTL,
+		now:               time.Now,
+	}
+}
+
+//Run executes the due scheduled transfers every interval until ctx is done
+func (e *ScheduledTransferExecutor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				executed, err := e.RunOnce(ctx)
+				if err != nil {
+					log.Print("Can't execute scheduled transfers: ", err)
+					break
+				}
+				if executed < claimBatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+//RunOnce claims a batch of due scheduled transfers, executes them and returns how many were claimed
+func (e *ScheduledTransferExecutor) RunOnce(ctx context.Context) (int, error) {
+	claimed, err := e.store.ClaimDueScheduledTransfers(ctx, db.ClaimDueScheduledTransfersParams{
+		ClaimedUntil: sql.NullTime{Time: e.now().Add(claimLease), Valid: true},
+		Limit:        claimBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, st := range claimed {
+		if err := e.execute(ctx, st); err != nil {
+			//The claim expires with the lease, so the occurrence is picked up again
+			log.Printf("Can't record scheduled transfer %d: %v", st.ID, err)
+		}
+	}
+
+	return len(claimed), nil
+}
+
+//execute runs the current occurrence of st and records its outcome.
+//The transfer is keyed by occurrence so a worker crashing before recording the outcome never pays twice.
+func (e *ScheduledTransferExecutor) execute(ctx context.Context, st db.ScheduledTransfer) error {
+	key := fmt.Sprintf("scheduled-transfer-%d-%d", st.ID, st.ScheduledFor.Unix())
+	result, transferErr := e.store.IdempotentTransferTx(ctx, db.IdempotentTransferTxParams{
+		TransferTxParams: db.TransferTxParams{
+			FromAccountID: st.FromAccountID,
+			ToAccountID:   st.ToAccountID,
+			Amount:        st.Amount,
+		},
+		Username:    st.Owner,
+		Key:         key,
+		RequestHash: fmt.Sprintf("%d:%d:%d", st.FromAccountID, st.ToAccountID, st.Amount),
+		TTL:         e.idempotencyKeyTTL,
+	})
+
+	attempt := st.Attempts + 1
+	params := db.RecordScheduledTransferRunParams{
+		Run: db.CreateScheduledTransferRunParams{
+			ScheduledTransferID: st.ID,
+			ScheduledFor:        st.ScheduledFor,
+			Attempt:             attempt,
+		},
+	}
+
+	switch {
+	case transferErr == nil:
+		params.Run.TransferID = sql.NullInt64{Int64: result.Transfer.ID, Valid: true}
+		e.advance(st, &params, db.ScheduledTransferStatusCompleted)
+	case attempt < e.maxAttempts:
+		params.Run.Error = transferErr.Error()
+		params.ScheduledFor = st.ScheduledFor
+		params.NextRunAt = e.now().Add(e.retryDelay)
+		params.Status = db.ScheduledTransferStatusActive
+		params.Attempts = attempt
+		params.LastError = transferErr.Error()
+	default:
+		params.Run.Error = transferErr.Error()
+		e.advance(st, &params, db.ScheduledTransferStatusFailed)
+		params.LastError = transferErr.Error()
+
+		if err := e.notifier.ScheduledTransferFailed(ctx, st, transferErr); err != nil {
+			log.Printf("Can't notify failed scheduled transfer %d: %v", st.ID, err)
+		}
+	}
+
+	_, err := e.store.RecordScheduledTransferRun(ctx, params)
+	return err
+}
+
+//advance moves st to its next occurrence after now, or to finalStatus when there is none
+func (e *ScheduledTransferExecutor) advance(st db.ScheduledTransfer, params *db.RecordScheduledTransferRunParams, finalStatus string) {
+	params.ScheduledFor = st.ScheduledFor
+	params.NextRunAt = st.NextRunAt
+	params.Status = finalStatus
+
+	if st.Recurrence == "" {
+		return
+	}
+
+	rule, err := schedule.ParseRule(st.Recurrence)
+	if err != nil {
+		//Recurrences are validated on creation, a broken one can only stop the schedule
+		params.Status = db.ScheduledTransferStatusFailed
+		params.LastError = err.Error()
+		return
+	}
+
+	//Occurrences missed while paused are skipped instead of being paid in a burst
+	next := rule.Next(st.ScheduledFor)
+	for !next.After(e.now()) {
+		next = rule.Next(next)
+	}
+
+	if st.EndAt.Valid && next.After(st.EndAt.Time) {
+		return
+	}
+
+	params.ScheduledFor = next
+	params.NextRunAt = next
+	params.Status = db.ScheduledTransferStatusActive
+}
@@ -0,0 +1,215 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "simplebank/db/sqlc"
+	mockdb "simplebank/db/sqlc/mock"
+	"testing"
+	"time"
+)
+
+//fakeNotifier records the scheduled transfers it was told about
+type fakeNotifier struct {
+	failed []int64
+}
+
+func (n *fakeNotifier) ScheduledTransferFailed(_ context.Context, st db.ScheduledTransfer, _ error) error {
+	n.failed = append(n.failed, st.ID)
+	return nil
+}
+
+func TestScheduledTransferExecutor_RunOnce(t *testing.T) {
+	now := time.Date(2022, time.February, 1, 9, 0, 0, 0, time.UTC)
+	monthly := db.ScheduledTransfer{
+		ID:            5,
+		Owner:         "Perotto",
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        60,
+		Recurrence:    "FREQ=MONTHLY;BYMONTHDAY=31",
+		ScheduledFor:  time.Date(2022, time.January, 31, 9, 0, 0, 0, time.UTC),
+		NextRunAt:     time.Date(2022, time.January, 31, 9, 0, 0, 0, time.UTC),
+		Status:        db.ScheduledTransferStatusActive,
+	}
+	errTransfer := db.ErrInsufficientFunds
+
+	tests := []struct {
+		name         string
+		st           db.ScheduledTransfer
+		buildStubs   func(store *mockdb.MockStore, st db.ScheduledTransfer)
+		wantNotified []int64
+	}{
+		{
+			name: "When it succeeds it moves to the next occurrence",
+			st:   monthly,
+			buildStubs: func(store *mockdb.MockStore, st db.ScheduledTransfer) {
+				store.EXPECT().IdempotentTransferTx(gomock.Any(), db.IdempotentTransferTxParams{
+					TransferTxParams: db.TransferTxParams{FromAccountID: 1, ToAccountID: 2, Amount: 60},
+					Username:         st.Owner,
+					Key:              "scheduled-transfer-5-1643619600",
+					RequestHash:      "1:2:60",
+					TTL:              time.Hour,
+				}).
+					Times(1).
+					Return(db.IdempotentTransferTxResult{TransferTxResult: db.TransferTxResult{Transfer: db.Transfer{ID: 9}}}, nil)
+				store.EXPECT().RecordScheduledTransferRun(gomock.Any(), db.RecordScheduledTransferRunParams{
+					Run: db.CreateScheduledTransferRunParams{
+						ScheduledTransferID: st.ID,
+						ScheduledFor:        st.ScheduledFor,
+						Attempt:             1,
+						TransferID:          sql.NullInt64{Int64: 9, Valid: true},
+					},
+					ScheduledFor: time.Date(2022, time.February, 28, 9, 0, 0, 0, time.UTC),
+					NextRunAt:    time.Date(2022, time.February, 28, 9, 0, 0, 0, time.UTC),
+					Status:       db.ScheduledTransferStatusActive,
+				}).
+					Times(1)
+			},
+		},
+		{
+			name: "When a one-off transfer succeeds it completes",
+			st: func() db.ScheduledTransfer {
+				st := monthly
+				st.Recurrence = ""
+				return st
+			}(),
+			buildStubs: func(store *mockdb.MockStore, st db.ScheduledTransfer) {
+				store.EXPECT().IdempotentTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotentTransferTxResult{TransferTxResult: db.TransferTxResult{Transfer: db.Transfer{ID: 9}}}, nil)
+				store.EXPECT().RecordScheduledTransferRun(gomock.Any(), db.RecordScheduledTransferRunParams{
+					Run: db.CreateScheduledTransferRunParams{
+						ScheduledTransferID: st.ID,
+						ScheduledFor:        st.ScheduledFor,
+						Attempt:             1,
+						TransferID:          sql.NullInt64{Int64: 9, Valid: true},
+					},
+					ScheduledFor: st.ScheduledFor,
+					NextRunAt:    st.NextRunAt,
+					Status:       db.ScheduledTransferStatusCompleted,
+				}).
+					Times(1)
+			},
+		},
+		{
+			name: "When it fails before the last attempt it retries the occurrence",
+			st:   monthly,
+			buildStubs: func(store *mockdb.MockStore, st db.ScheduledTransfer) {
+				store.EXPECT().IdempotentTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotentTransferTxResult{}, errTransfer)
+				store.EXPECT().RecordScheduledTransferRun(gomock.Any(), db.RecordScheduledTransferRunParams{
+					Run: db.CreateScheduledTransferRunParams{
+						ScheduledTransferID: st.ID,
+						ScheduledFor:        st.ScheduledFor,
+						Attempt:             1,
+						Error:               errTransfer.Error(),
+					},
+					ScheduledFor: st.ScheduledFor,
+					NextRunAt:    now.Add(time.Hour),
+					Status:       db.ScheduledTransferStatusActive,
+					Attempts:     1,
+					LastError:    errTransfer.Error(),
+				}).
+					Times(1)
+			},
+		},
+		{
+			name: "When the last attempt fails it notifies and moves to the next occurrence",
+			st: func() db.ScheduledTransfer {
+				st := monthly
+				st.Attempts = 2
+				return st
+			}(),
+			buildStubs: func(store *mockdb.MockStore, st db.ScheduledTransfer) {
+				store.EXPECT().IdempotentTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotentTransferTxResult{}, errTransfer)
+				store.EXPECT().RecordScheduledTransferRun(gomock.Any(), db.RecordScheduledTransferRunParams{
+					Run: db.CreateScheduledTransferRunParams{
+						ScheduledTransferID: st.ID,
+						ScheduledFor:        st.ScheduledFor,
+						Attempt:             3,
+						Error:               errTransfer.Error(),
+					},
+					ScheduledFor: time.Date(2022, time.February, 28, 9, 0, 0, 0, time.UTC),
+					NextRunAt:    time.Date(2022, time.February, 28, 9, 0, 0, 0, time.UTC),
+					Status:       db.ScheduledTransferStatusActive,
+					LastError:    errTransfer.Error(),
+				}).
+					Times(1)
+			},
+			wantNotified: []int64{monthly.ID},
+		},
+		{
+			name: "When the last attempt of the last occurrence fails it fails",
+			st: func() db.ScheduledTransfer {
+				st := monthly
+				st.Attempts = 2
+				st.EndAt = sql.NullTime{Time: time.Date(2022, time.February, 15, 0, 0, 0, 0, time.UTC), Valid: true}
+				return st
+			}(),
+			buildStubs: func(store *mockdb.MockStore, st db.ScheduledTransfer) {
+				store.EXPECT().IdempotentTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotentTransferTxResult{}, errTransfer)
+				store.EXPECT().RecordScheduledTransferRun(gomock.Any(), db.RecordScheduledTransferRunParams{
+					Run: db.CreateScheduledTransferRunParams{
+						ScheduledTransferID: st.ID,
+						ScheduledFor:        st.ScheduledFor,
+						Attempt:             3,
+						Error:               errTransfer.Error(),
+					},
+					ScheduledFor: st.ScheduledFor,
+					NextRunAt:    st.NextRunAt,
+					Status:       db.ScheduledTransferStatusFailed,
+					LastError:    errTransfer.Error(),
+				}).
+					Times(1)
+			},
+			wantNotified: []int64{monthly.ID},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().ClaimDueScheduledTransfers(gomock.Any(), db.ClaimDueScheduledTransfersParams{
+				ClaimedUntil: sql.NullTime{Time: now.Add(claimLease), Valid: true},
+				Limit:        claimBatchSize,
+			}).
+				Times(1).
+				Return([]db.ScheduledTransfer{tt.st}, nil)
+			tt.buildStubs(store, tt.st)
+
+			notifier := &fakeNotifier{}
+			executor := &ScheduledTransferExecutor{
+				store:             store,
+				notifier:          notifier,
+				maxAttempts:       3,
+				retryDelay:        time.Hour,
+				idempotencyKeyTTL: time.Hour,
+				now:               func() time.Time { return now },
+			}
+
+			executed, err := executor.RunOnce(context.Background())
+			require.NoError(t, err)
+
+			assert.Equal(t, 1, executed)
+			assert.Equal(t, tt.wantNotified, notifier.failed)
+		})
+	}
+}
+
+func TestScheduledTransferExecutor_RunOnceClaimError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ClaimDueScheduledTransfers(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+	store.EXPECT().IdempotentTransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+	executor := &ScheduledTransferExecutor{store: store, notifier: &fakeNotifier{}, now: time.Now}
+
+	_, err := executor.RunOnce(context.Background())
+	assert.True(t, errors.Is(err, sql.ErrConnDone))
+}
@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	db "simplebank/db/sqlc"
+	"time"
+)
+
+//batchClaimSize bounds the transfer batches processed per claim, batches hold up to hundreds of transfers
+const batchClaimSize = 10
+
+//TransferBatchProcessor claims submitted transfer batches and books their transfers.
+//A batch left unfinished by a failing worker is claimed again once its lease expires.
+type TransferBatchProcessor struct {
+	store db.Store
+	now   func() time.Time
+}
+
+//NewTransferBatchProcessor builds a TransferBatchProcessor
+func NewTransferBatchProcessor(store db.Store) *TransferBatchProcessor {
+	return &TransferBatchProcessor{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+//Run processes the submitted transfer batches every interval until ctx is done
+func (p *TransferBatchProcessor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				processed, err := p.RunOnce(ctx)
+				if err != nil {
+					log.Print("Can't process transfer batches: ", err)
+					break
+				}
+				if processed < batchClaimSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+//RunOnce claims submitted transfer batches, processes them and returns how many were claimed
+func (p *TransferBatchProcessor) RunOnce(ctx context.Context) (int, error) {
+	claimed, err := p.store.ClaimTransferBatches(ctx, db.ClaimTransferBatchesParams{
+		ClaimedUntil: sql.NullTime{Time: p.now().Add(claimLease), Valid: true},
+		Limit:        batchClaimSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, batch := range claimed {
+		result, err := p.store.ProcessTransferBatch(ctx, batch.ID)
+		if err != nil {
+			//The claim expires with the lease, so the batch is picked up again
+			log.Printf("Can't process transfer batch %d: %v", batch.ID, err)
+			continue
+		}
+
+		log.Printf("Transfer batch %d %s: %d of %d transfers succeeded",
+			result.Batch.ID, result.Batch.Status, result.Batch.SucceededCount, result.Batch.ItemsCount)
+	}
+
+	return len(claimed), nil
+}
@@ -0,0 +1,103 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "simplebank/db/sqlc"
+	mockdb "simplebank/db/sqlc/mock"
+	"testing"
+	"time"
+)
+
+func TestTransferBatchProcessor_RunOnce(t *testing.T) {
+	now := time.Date(2022, 5, 1, 9, 0, 0, 0, time.UTC)
+	batches := []db.TransferBatch{
+		{ID: 1, Mode: db.TransferBatchModeAllOrNothing, Status: db.TransferBatchStatusProcessing, ItemsCount: 2},
+		{ID: 2, Mode: db.TransferBatchModeBestEffort, Status: db.TransferBatchStatusProcessing, ItemsCount: 3},
+	}
+
+	tests := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		wantProcessed int
+		wantErr       error
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ClaimTransferBatches(gomock.Any(), db.ClaimTransferBatchesParams{
+					ClaimedUntil: sql.NullTime{Time: now.Add(claimLease), Valid: true},
+					Limit:        batchClaimSize,
+				}).
+					Times(1).
+					Return(batches, nil)
+				store.EXPECT().ProcessTransferBatch(gomock.Any(), int64(1)).
+					Times(1).
+					Return(db.TransferBatchResult{Batch: db.TransferBatch{ID: 1, Status: db.TransferBatchStatusCompleted}}, nil)
+				store.EXPECT().ProcessTransferBatch(gomock.Any(), int64(2)).
+					Times(1).
+					Return(db.TransferBatchResult{Batch: db.TransferBatch{ID: 2, Status: db.TransferBatchStatusPartiallyCompleted}}, nil)
+			},
+			wantProcessed: 2,
+		},
+		{
+			name: "ProcessErrorDoesNotStopOthers",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ClaimTransferBatches(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(batches, nil)
+				store.EXPECT().ProcessTransferBatch(gomock.Any(), int64(1)).
+					Times(1).
+					Return(db.TransferBatchResult{}, sql.ErrConnDone)
+				store.EXPECT().ProcessTransferBatch(gomock.Any(), int64(2)).
+					Times(1).
+					Return(db.TransferBatchResult{Batch: db.TransferBatch{ID: 2, Status: db.TransferBatchStatusCompleted}}, nil)
+			},
+			wantProcessed: 2,
+		},
+		{
+			name: "NothingToClaim",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ClaimTransferBatches(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.TransferBatch{}, nil)
+				store.EXPECT().ProcessTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "ClaimError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ClaimTransferBatches(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+				store.EXPECT().ProcessTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tt.buildStubs(store)
+
+			processor := &TransferBatchProcessor{store: store, now: func() time.Time { return now }}
+
+			processed, err := processor.RunOnce(context.Background())
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantProcessed, processed)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	db "simplebank/db/sqlc"
+	"simplebank/util"
+	"simplebank/webhook"
+	"time"
+)
+
+const (
+	//webhookClaimSize bounds the deliveries posted per claim
+	webhookClaimSize = 20
+	//webhookBaseDelay is the delay before retrying a delivery that failed once, it doubles with every failure
+	webhookBaseDelay = 30 * time.Second
+	//webhookMaxDelay caps the delay between two attempts of a delivery
+	webhookMaxDelay = 6 * time.Hour
+)
+
+type (
+	//WebhookSender posts a signed delivery to its subscriber, webhook.Sender is the one used in production
+	WebhookSender interface {
+		Send(ctx context.Context, delivery webhook.Delivery) (webhook.Response, error)
+	}
+
+	//WebhookDispatcher posts the queued webhook deliveries to their subscribers.
+	//A failed delivery is retried with an exponential backoff until maxAttempts, and a subscription whose
+	//attempts failed disableAfter times in a row is disabled. Every attempt is kept in the delivery log.
+	WebhookDispatcher struct {
+		store        db.Store
+		sender       WebhookSender
+		maxAttempts  int32
+		disableAfter int32
+		now          func() time.Time
+	}
+)
+
+//NewWebhookDispatcher builds a WebhookDispatcher
+func NewWebhookDispatcher(store db.Store, sender WebhookSender, config util.Config) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		store:        store,
+		sender:       sender,
+		maxAttempts:  config.WebhookMaxAttempts,
+		disableAfter: config.WebhookDisableAfterFailures,
+		now:          time.Now,
+	}
+}
+
+//Run posts the due deliveries every interval until ctx is done
+func (d *WebhookDispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				claimed, err := d.RunOnce(ctx)
+				if err != nil {
+					log.Print("Can't dispatch webhook deliveries: ", err)
+					break
+				}
+				if claimed < webhookClaimSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+//RunOnce claims due deliveries, posts them and returns how many were claimed
+func (d *WebhookDispatcher) RunOnce(ctx context.Context) (int, error) {
+	claimed, err := d.store.ClaimWebhookDeliveries(ctx, db.ClaimWebhookDeliveriesParams{
+		ClaimedUntil: sql.NullTime{Time: d.now().Add(claimLease), Valid: true},
+		Limit:        webhookClaimSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range claimed {
+		if err := d.dispatch(ctx, delivery); err != nil {
+			//The claim expires with the lease, so the delivery is picked up again
+			log.Printf("Can't record webhook delivery %d: %v", delivery.ID, err)
+		}
+	}
+
+	return len(claimed), nil
+}
+
+//dispatch posts the delivery to its subscriber and records the attempt
+func (d *WebhookDispatcher) dispatch(ctx context.Context, delivery db.WebhookDelivery) error {
+	subscription, err := d.store.GetWebhookSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	rsp, sendErr := d.sender.Send(ctx, webhook.Delivery{
+		ID:        delivery.ID,
+		URL:       subscription.Url,
+		Secret:    subscription.Secret,
+		EventType: delivery.EventType,
+		Body:      delivery.Payload,
+	})
+
+	attempt := delivery.Attempts + 1
+	params := db.RecordWebhookAttemptParams{
+		Attempt: db.CreateWebhookDeliveryAttemptParams{
+			DeliveryID:     delivery.ID,
+			Attempt:        attempt,
+			ResponseStatus: sql.NullInt32{Int32: int32(rsp.StatusCode), Valid: rsp.StatusCode != 0},
+			DurationMs:     rsp.Duration.Milliseconds(),
+		},
+		NextAttemptAt: delivery.NextAttemptAt,
+		DisableAfter:  d.disableAfter,
+	}
+
+	switch {
+	case sendErr == nil:
+		params.Status = db.WebhookDeliverySucceeded
+	case attempt >= d.maxAttempts:
+		log.Printf("Webhook delivery %d to subscription %d gave up after %d attempts: %v", delivery.ID, subscription.ID, attempt, sendErr)
+		params.Status = db.WebhookDeliveryFailed
+		params.Attempt.Error = sendErr.Error()
+	default:
+		params.Status = db.WebhookDeliveryPending
+		params.Attempt.Error = sendErr.Error()
+		params.NextAttemptAt = d.now().Add(backoff(webhookBaseDelay, webhookMaxDelay, delivery.Attempts))
+	}
+
+	result, err := d.store.RecordWebhookAttempt(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	if subscription.Status == db.WebhookSubscriptionStatusActive && result.Subscription.Status == db.WebhookSubscriptionStatusDisabled {
+		log.Printf("Webhook subscription %d of %s disabled after %d consecutive failures", subscription.ID, subscription.Owner, result.Subscription.ConsecutiveFailures)
+	}
+	return nil
+}
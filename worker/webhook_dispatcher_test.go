@@ -0,0 +1,186 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	db "simplebank/db/sqlc"
+	mockdb "simplebank/db/sqlc/mock"
+	"simplebank/webhook"
+	"testing"
+	"time"
+)
+
+//fakeSender records the deliveries it was given and answers them all the same way
+type fakeSender struct {
+	rsp        webhook.Response
+	err        error
+	deliveries []webhook.Delivery
+}
+
+func (s *fakeSender) Send(_ context.Context, delivery webhook.Delivery) (webhook.Response, error) {
+	s.deliveries = append(s.deliveries, delivery)
+	return s.rsp, s.err
+}
+
+func TestWebhookDispatcher_RunOnce(t *testing.T) {
+	now := time.Date(2022, 5, 1, 9, 0, 0, 0, time.UTC)
+	delivery := db.WebhookDelivery{
+		ID:             11,
+		SubscriptionID: 3,
+		EventID:        40,
+		EventType:      db.EventTransferCompleted,
+		Payload:        json.RawMessage(`{"id":40,"type":"TransferCompleted","data":{"transfer_id":9}}`),
+		Status:         db.WebhookDeliveryPending,
+		Attempts:       2,
+		NextAttemptAt:  now,
+	}
+	acknowledge := func() *fakeSender {
+		return &fakeSender{rsp: webhook.Response{StatusCode: http.StatusOK}}
+	}
+	fail := func() *fakeSender {
+		return &fakeSender{
+			rsp: webhook.Response{StatusCode: http.StatusServiceUnavailable},
+			err: errors.New("receiver answered 503 Service Unavailable"),
+		}
+	}
+
+	tests := []struct {
+		name       string
+		sender     func() *fakeSender
+		delivery   db.WebhookDelivery
+		buildStubs func(t *testing.T, store *mockdb.MockStore, subscription db.WebhookSubscription)
+		wantErr    error
+	}{
+		{
+			name:     "DeliverySucceeds",
+			sender:   acknowledge,
+			delivery: delivery,
+			buildStubs: func(t *testing.T, store *mockdb.MockStore, subscription db.WebhookSubscription) {
+				store.EXPECT().RecordWebhookAttempt(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, params db.RecordWebhookAttemptParams) (db.RecordWebhookAttemptResult, error) {
+						assert.Equal(t, db.WebhookDeliverySucceeded, params.Status)
+						assert.Equal(t, delivery.ID, params.Attempt.DeliveryID)
+						assert.Equal(t, int32(3), params.Attempt.Attempt)
+						assert.Equal(t, sql.NullInt32{Int32: http.StatusOK, Valid: true}, params.Attempt.ResponseStatus)
+						assert.Empty(t, params.Attempt.Error)
+						assert.Equal(t, int32(20), params.DisableAfter)
+						return db.RecordWebhookAttemptResult{Subscription: subscription}, nil
+					})
+			},
+		},
+		{
+			name:     "FailedDeliveryBacksOff",
+			sender:   fail,
+			delivery: delivery,
+			buildStubs: func(t *testing.T, store *mockdb.MockStore, subscription db.WebhookSubscription) {
+				store.EXPECT().RecordWebhookAttempt(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, params db.RecordWebhookAttemptParams) (db.RecordWebhookAttemptResult, error) {
+						assert.Equal(t, db.WebhookDeliveryPending, params.Status)
+						assert.Equal(t, sql.NullInt32{Int32: http.StatusServiceUnavailable, Valid: true}, params.Attempt.ResponseStatus)
+						assert.Equal(t, "receiver answered 503 Service Unavailable", params.Attempt.Error)
+						assert.Equal(t, now.Add(2*time.Minute), params.NextAttemptAt)
+						return db.RecordWebhookAttemptResult{Subscription: subscription}, nil
+					})
+			},
+		},
+		{
+			name:   "LastAttemptFailsTheDelivery",
+			sender: fail,
+			delivery: func() db.WebhookDelivery {
+				last := delivery
+				last.Attempts = 7
+				return last
+			}(),
+			buildStubs: func(t *testing.T, store *mockdb.MockStore, subscription db.WebhookSubscription) {
+				store.EXPECT().RecordWebhookAttempt(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, params db.RecordWebhookAttemptParams) (db.RecordWebhookAttemptResult, error) {
+						assert.Equal(t, db.WebhookDeliveryFailed, params.Status)
+						assert.Equal(t, int32(8), params.Attempt.Attempt)
+
+						subscription.Status = db.WebhookSubscriptionStatusDisabled
+						subscription.ConsecutiveFailures = 20
+						return db.RecordWebhookAttemptResult{Subscription: subscription}, nil
+					})
+			},
+		},
+		{
+			name:     "RecordErrorIsLogged",
+			sender:   acknowledge,
+			delivery: delivery,
+			buildStubs: func(t *testing.T, store *mockdb.MockStore, subscription db.WebhookSubscription) {
+				store.EXPECT().RecordWebhookAttempt(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RecordWebhookAttemptResult{}, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			subscription := db.WebhookSubscription{
+				ID:         tt.delivery.SubscriptionID,
+				Owner:      "perotto",
+				Url:        "https://hooks.example.com/simplebank",
+				EventTypes: []string{db.EventTransferCompleted},
+				Secret:     "secret",
+				Status:     db.WebhookSubscriptionStatusActive,
+			}
+
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().ClaimWebhookDeliveries(gomock.Any(), db.ClaimWebhookDeliveriesParams{
+				ClaimedUntil: sql.NullTime{Time: now.Add(claimLease), Valid: true},
+				Limit:        webhookClaimSize,
+			}).
+				Times(1).
+				Return([]db.WebhookDelivery{tt.delivery}, nil)
+			store.EXPECT().GetWebhookSubscription(gomock.Any(), subscription.ID).Times(1).Return(subscription, nil)
+			tt.buildStubs(t, store, subscription)
+
+			sender := tt.sender()
+			dispatcher := &WebhookDispatcher{
+				store:        store,
+				sender:       sender,
+				maxAttempts:  8,
+				disableAfter: 20,
+				now:          func() time.Time { return now },
+			}
+
+			claimed, err := dispatcher.RunOnce(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 1, claimed)
+			assert.Equal(t, []webhook.Delivery{{
+				ID:        tt.delivery.ID,
+				URL:       subscription.Url,
+				Secret:    subscription.Secret,
+				EventType: tt.delivery.EventType,
+				Body:      tt.delivery.Payload,
+			}}, sender.deliveries)
+		})
+	}
+}
+
+func TestWebhookDispatcher_RunOnce_ClaimError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ClaimWebhookDeliveries(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+	store.EXPECT().RecordWebhookAttempt(gomock.Any(), gomock.Any()).Times(0)
+
+	dispatcher := &WebhookDispatcher{store: store, sender: &fakeSender{}, now: time.Now}
+
+	claimed, err := dispatcher.RunOnce(context.Background())
+	assert.True(t, errors.Is(err, sql.ErrConnDone))
+	assert.Zero(t, claimed)
+}